@@ -0,0 +1,76 @@
+package api
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/JokerYuan-lang/MyNoteBook/internal/service"
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/errcode"
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/response"
+	"github.com/gin-gonic/gin"
+)
+
+// 允许在浏览器中直接显示的附件类型（不含 SVG 等可执行脚本的格式）
+var inlineMimeTypes = map[string]bool{
+	"image/png":       true,
+	"image/jpeg":      true,
+	"image/gif":       true,
+	"image/webp":      true,
+	"image/bmp":       true,
+	"application/pdf": true,
+}
+
+// AttachmentAPI 附件接口
+type AttachmentAPI struct {
+	attachmentService *service.AttachmentService
+}
+
+// NewAttachmentAPI 创建 AttachmentAPI 实例
+func NewAttachmentAPI(attachmentService *service.AttachmentService) *AttachmentAPI {
+	return &AttachmentAPI{attachmentService: attachmentService}
+}
+
+// GetAttachmentList 笔记附件列表接口
+func (a *AttachmentAPI) GetAttachmentList(c *gin.Context) {
+	noteID, err := strconv.ParseUint(c.Query("note_id"), 10, 32)
+	if err != nil {
+		response.Error(c, errcode.InvalidParam, "笔记ID格式错误")
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	attachments, err := a.attachmentService.GetAttachmentList(userID.(uint), uint(noteID))
+	if err != nil {
+		response.Error(c, errcode.ServerError, err.Error())
+		return
+	}
+
+	response.Success(c, attachments)
+}
+
+// DownloadAttachment 下载附件接口（hash 为笔记中 attachment://<hash> 的部分）
+func (a *AttachmentAPI) DownloadAttachment(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	attachment, f, err := a.attachmentService.OpenAttachment(userID.(uint), c.Query("hash"))
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	defer f.Close()
+
+	// MIME 类型来自上传或导入，不可信：只有安全的图片和 PDF 内联显示，其余一律作为附件下载，
+	// 避免 HTML、SVG 等在站点域名下执行脚本
+	mimeType := strings.ToLower(strings.TrimSpace(strings.Split(attachment.MimeType, ";")[0]))
+	disposition := "inline"
+	if !inlineMimeTypes[mimeType] {
+		disposition = "attachment"
+		if mimeType == "" {
+			mimeType = "application/octet-stream"
+		}
+	}
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Header("Content-Disposition", fmt.Sprintf(`%s; filename*=UTF-8''%s`, disposition, url.PathEscape(attachment.FileName)))
+	c.DataFromReader(200, attachment.Size, mimeType, f, nil)
+}
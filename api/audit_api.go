@@ -0,0 +1,169 @@
+package api
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/JokerYuan-lang/MyNoteBook/internal/model"
+	"github.com/JokerYuan-lang/MyNoteBook/internal/service"
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/errcode"
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/response"
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/validator"
+	"github.com/gin-gonic/gin"
+)
+
+// 审计日志查询请求参数（时间格式：2006-01-02）
+
+type AuditListRequest struct {
+	Page       int       `form:"page" binding:"required,min=1"`             // 页码（至少1）
+	PageSize   int       `form:"page_size" binding:"required,min=1,max=50"` // 每页数量（1-50）
+	Action     string    `form:"action"`                                    // 动作（可选）
+	TargetType string    `form:"target_type"`                               // 对象类型（可选）
+	StartDate  time.Time `form:"start_date" time_format:"2006-01-02"`       // 起始日期（可选，含当天）
+	EndDate    time.Time `form:"end_date" time_format:"2006-01-02"`         // 截止日期（可选，含当天）
+}
+
+// 管理员审计日志查询请求参数（额外支持按用户、IP 筛选）
+
+type AdminAuditListRequest struct {
+	AuditListRequest
+	ActorID uint   `form:"actor_id"` // 操作者ID（可选）
+	IP      string `form:"ip"`       // 客户端IP（可选）
+}
+
+// 审计日志导出请求参数（不分页）
+
+type AuditExportRequest struct {
+	ActorID    uint      `form:"actor_id"`
+	Action     string    `form:"action"`
+	TargetType string    `form:"target_type"`
+	IP         string    `form:"ip"`
+	StartDate  time.Time `form:"start_date" time_format:"2006-01-02"`
+	EndDate    time.Time `form:"end_date" time_format:"2006-01-02"`
+}
+
+// AuditAPI 审计日志接口
+type AuditAPI struct {
+	auditService *service.AuditService
+}
+
+// NewAuditAPI 创建 AuditAPI 实例
+func NewAuditAPI(auditService *service.AuditService) *AuditAPI {
+	return &AuditAPI{auditService: auditService}
+}
+
+// GetMyAuditList 查询当前用户自己的操作记录接口
+func (a *AuditAPI) GetMyAuditList(c *gin.Context) {
+	var req AuditListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.Error(c, errcode.InvalidParam, validator.GetErrorMsg(err))
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	filter := service.AuditFilter{
+		ActorID:    userID.(uint),
+		Action:     req.Action,
+		TargetType: req.TargetType,
+		StartTime:  req.StartDate,
+		EndTime:    endOfDay(req.EndDate),
+	}
+	a.writeAuditList(c, filter, req.Page, req.PageSize)
+}
+
+// GetAllAuditList 管理员查询全局审计日志接口
+func (a *AuditAPI) GetAllAuditList(c *gin.Context) {
+	var req AdminAuditListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.Error(c, errcode.InvalidParam, validator.GetErrorMsg(err))
+		return
+	}
+
+	filter := service.AuditFilter{
+		ActorID:    req.ActorID,
+		Action:     req.Action,
+		TargetType: req.TargetType,
+		IP:         req.IP,
+		StartTime:  req.StartDate,
+		EndTime:    endOfDay(req.EndDate),
+	}
+	a.writeAuditList(c, filter, req.Page, req.PageSize)
+}
+
+// ExportAuditLogs 管理员导出审计日志（CSV）接口
+func (a *AuditAPI) ExportAuditLogs(c *gin.Context) {
+	var req AuditExportRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.Error(c, errcode.InvalidParam, validator.GetErrorMsg(err))
+		return
+	}
+
+	filter := service.AuditFilter{
+		ActorID:    req.ActorID,
+		Action:     req.Action,
+		TargetType: req.TargetType,
+		IP:         req.IP,
+		StartTime:  req.StartDate,
+		EndTime:    endOfDay(req.EndDate),
+	}
+	fileName := fmt.Sprintf("audit_%s.csv", time.Now().Format("20060102150405"))
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, fileName))
+	if err := a.auditService.ExportCSV(filter, c.Writer); err != nil {
+		// 响应头可能已写出，只能中断连接
+		_ = c.Error(err)
+		c.Abort()
+	}
+}
+
+// writeAuditList 查询并返回分页审计日志
+func (a *AuditAPI) writeAuditList(c *gin.Context, filter service.AuditFilter, page, pageSize int) {
+	logs, total, err := a.auditService.GetAuditList(filter, page, pageSize)
+	if err != nil {
+		response.Error(c, errcode.ServerError, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"list":      logs,
+		"total":     total,
+		"page":      page,
+		"page_size": pageSize,
+	})
+}
+
+// newAuditLog 根据请求上下文构造审计日志（操作者取自 AuthCheck 写入的用户信息）
+func newAuditLog(c *gin.Context, action, targetType string, targetID uint, detail string) *model.AuditLog {
+	log := &model.AuditLog{
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		IP:         c.ClientIP(),
+		UserAgent:  truncate(c.Request.UserAgent(), 255),
+		Detail:     truncate(detail, 255),
+	}
+	if userID, ok := c.Get("user_id"); ok {
+		log.ActorID = userID.(uint)
+	}
+	if username, ok := c.Get("username"); ok {
+		log.ActorName = username.(string)
+	}
+	return log
+}
+
+// endOfDay 截止日期按整天计算（返回次日零点）
+func endOfDay(date time.Time) time.Time {
+	if date.IsZero() {
+		return date
+	}
+	return date.AddDate(0, 0, 1)
+}
+
+// truncate 按字符截断字符串（避免超出字段长度）
+func truncate(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max])
+}
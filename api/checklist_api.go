@@ -0,0 +1,112 @@
+package api
+
+import (
+	"strconv"
+
+	"github.com/JokerYuan-lang/MyNoteBook/internal/service"
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/errcode"
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/response"
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/validator"
+	"github.com/gin-gonic/gin"
+)
+
+// 添加清单项请求参数
+
+type AddChecklistItemRequest struct {
+	NoteID uint   `json:"note_id" binding:"required,min=1"` // 笔记ID
+	Text   string `json:"text" binding:"required,max=255"`  // 清单内容
+}
+
+// 切换清单项请求参数
+
+type ToggleChecklistItemRequest struct {
+	ItemID uint  `json:"item_id" binding:"required,min=1"` // 清单项ID
+	Done   *bool `json:"done"`                             // 目标状态（可选，不传则取反）
+}
+
+// 清单排序请求参数
+
+type ReorderChecklistRequest struct {
+	NoteID  uint   `json:"note_id" binding:"required,min=1"`  // 笔记ID
+	ItemIDs []uint `json:"item_ids" binding:"required,min=1"` // 排序后的清单项ID（需包含全部清单项）
+}
+
+// ChecklistAPI 笔记清单接口
+type ChecklistAPI struct {
+	checklistService *service.ChecklistService
+}
+
+// NewChecklistAPI 创建 ChecklistAPI 实例
+func NewChecklistAPI(checklistService *service.ChecklistService) *ChecklistAPI {
+	return &ChecklistAPI{checklistService: checklistService}
+}
+
+// AddItem 添加清单项接口
+func (a *ChecklistAPI) AddItem(c *gin.Context) {
+	var req AddChecklistItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, errcode.InvalidParam, validator.GetErrorMsg(err))
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	item, err := a.checklistService.AddItem(userID.(uint), req.NoteID, req.Text)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	response.Success(c, item)
+}
+
+// ToggleItem 切换清单项完成状态接口
+func (a *ChecklistAPI) ToggleItem(c *gin.Context) {
+	var req ToggleChecklistItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, errcode.InvalidParam, validator.GetErrorMsg(err))
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	item, err := a.checklistService.ToggleItem(userID.(uint), req.ItemID, req.Done)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	response.Success(c, item)
+}
+
+// ReorderItems 清单排序接口
+func (a *ChecklistAPI) ReorderItems(c *gin.Context) {
+	var req ReorderChecklistRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, errcode.InvalidParam, validator.GetErrorMsg(err))
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	if err := a.checklistService.ReorderItems(userID.(uint), req.NoteID, req.ItemIDs); err != nil {
+		writeError(c, err)
+		return
+	}
+
+	response.SuccessWithoutData(c)
+}
+
+// DeleteItem 删除清单项接口
+func (a *ChecklistAPI) DeleteItem(c *gin.Context) {
+	itemID, err := strconv.ParseUint(c.Query("item_id"), 10, 32)
+	if err != nil {
+		response.Error(c, errcode.InvalidParam, "清单项ID格式错误")
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	if err := a.checklistService.DeleteItem(userID.(uint), uint(itemID)); err != nil {
+		writeError(c, err)
+		return
+	}
+
+	response.SuccessWithoutData(c)
+}
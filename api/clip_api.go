@@ -0,0 +1,54 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/JokerYuan-lang/MyNoteBook/internal/model"
+	"github.com/JokerYuan-lang/MyNoteBook/internal/service"
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/errcode"
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/response"
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/validator"
+	"github.com/gin-gonic/gin"
+)
+
+const maxClipSize = 10 << 20 // 剪藏请求体上限 10MB
+
+// 网页剪藏请求参数
+
+type ClipRequest struct {
+	URL      string   `json:"url" binding:"required,url,max=2048"` // 来源地址
+	HTML     string   `json:"html" binding:"required"`             // 网页 HTML
+	Category string   `json:"category" binding:"max=255"`          // 笔记本路径（可选，默认"网页剪藏"）
+	TagNames []string `json:"tag_names"`                           // 额外标签（可选，来源域名自动作为标签）
+}
+
+// ClipAPI 网页剪藏接口
+type ClipAPI struct {
+	clipService  *service.ClipService
+	auditService *service.AuditService
+}
+
+// NewClipAPI 创建 ClipAPI 实例
+func NewClipAPI(clipService *service.ClipService, auditService *service.AuditService) *ClipAPI {
+	return &ClipAPI{clipService: clipService, auditService: auditService}
+}
+
+// ClipPage 剪藏网页为笔记接口
+func (a *ClipAPI) ClipPage(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxClipSize)
+	var req ClipRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, errcode.InvalidParam, validator.GetErrorMsg(err))
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	noteID, err := a.clipService.ClipPage(userID.(uint), req.URL, req.HTML, req.Category, req.TagNames)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	a.auditService.Record(newAuditLog(c, model.AuditActionNoteCreate, model.AuditTargetNote, noteID, req.URL))
+
+	response.Success(c, gin.H{"note_id": noteID})
+}
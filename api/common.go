@@ -0,0 +1,26 @@
+package api
+
+import (
+	"errors"
+
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/errcode"
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/response"
+	"github.com/gin-gonic/gin"
+)
+
+// writeError 根据业务错误返回对应错误码（errcode.Error 使用自带错误码；不存在 404、服务器错误 500，其余视为参数错误）
+func writeError(c *gin.Context, err error) {
+	var codeErr *errcode.Error
+	if errors.As(err, &codeErr) {
+		response.Error(c, codeErr.Code, codeErr.Error())
+		return
+	}
+	switch err.Error() {
+	case errcode.GetMsg(errcode.NotFound):
+		response.ErrorWithDefaultMsg(c, errcode.NotFound)
+	case errcode.GetMsg(errcode.ServerError):
+		response.Error(c, errcode.ServerError, err.Error())
+	default:
+		response.Error(c, errcode.InvalidParam, err.Error())
+	}
+}
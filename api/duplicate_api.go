@@ -0,0 +1,111 @@
+package api
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/JokerYuan-lang/MyNoteBook/internal/model"
+	"github.com/JokerYuan-lang/MyNoteBook/internal/service"
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/errcode"
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/response"
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/validator"
+	"github.com/gin-gonic/gin"
+)
+
+// 重复笔记查询请求参数
+
+type DuplicateRequest struct {
+	MaxDistance int `form:"max_distance" binding:"omitempty,min=1,max=12"` // 指纹最大汉明距离（可选，默认6，越大越宽松）
+}
+
+// 合并笔记请求参数
+
+type MergeNotesRequest struct {
+	TargetID  uint   `json:"target_id" binding:"required,min=1"`                    // 合并到的笔记ID
+	SourceIDs []uint `json:"source_ids" binding:"required,min=1,max=50,dive,min=1"` // 被合并的笔记ID（合并后放入合并回收站）
+}
+
+// DuplicateAPI 重复笔记接口
+type DuplicateAPI struct {
+	duplicateService *service.DuplicateService
+	auditService     *service.AuditService
+}
+
+// NewDuplicateAPI 创建 DuplicateAPI 实例
+func NewDuplicateAPI(duplicateService *service.DuplicateService, auditService *service.AuditService) *DuplicateAPI {
+	return &DuplicateAPI{duplicateService: duplicateService, auditService: auditService}
+}
+
+// GetDuplicates 重复笔记接口（按内容指纹分组，附相似度）
+func (a *DuplicateAPI) GetDuplicates(c *gin.Context) {
+	var req DuplicateRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.Error(c, errcode.InvalidParam, validator.GetErrorMsg(err))
+		return
+	}
+	if req.MaxDistance == 0 {
+		req.MaxDistance = 6
+	}
+
+	userID, _ := c.Get("user_id")
+	clusters, err := a.duplicateService.GetDuplicateClusters(userID.(uint), req.MaxDistance)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	response.Success(c, clusters)
+}
+
+// MergeNotes 合并笔记接口（内容、标签、附件合并到目标笔记，其余笔记删除并放入合并回收站）
+func (a *DuplicateAPI) MergeNotes(c *gin.Context) {
+	var req MergeNotesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, errcode.InvalidParam, validator.GetErrorMsg(err))
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	if err := a.duplicateService.MergeNotes(userID.(uint), req.TargetID, req.SourceIDs); err != nil {
+		writeError(c, err)
+		return
+	}
+	ids := make([]string, 0, len(req.SourceIDs))
+	for _, id := range req.SourceIDs {
+		ids = append(ids, fmt.Sprint(id))
+	}
+	a.auditService.Record(newAuditLog(c, model.AuditActionNoteMerge, model.AuditTargetNote, req.TargetID, "合并笔记 "+strings.Join(ids, ",")))
+
+	response.SuccessWithoutData(c)
+}
+
+// GetMergedNotes 合并回收站接口（被合并的笔记，可恢复）
+func (a *DuplicateAPI) GetMergedNotes(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	items, err := a.duplicateService.GetMergedNotes(userID.(uint))
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	response.Success(c, items)
+}
+
+// RestoreNote 从合并回收站恢复笔记接口
+func (a *DuplicateAPI) RestoreNote(c *gin.Context) {
+	noteID, err := strconv.ParseUint(c.Query("note_id"), 10, 32)
+	if err != nil {
+		response.Error(c, errcode.InvalidParam, "笔记ID格式错误")
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	if err := a.duplicateService.RestoreMergedNote(userID.(uint), uint(noteID)); err != nil {
+		writeError(c, err)
+		return
+	}
+	a.auditService.Record(newAuditLog(c, model.AuditActionNoteRestore, model.AuditTargetNote, uint(noteID), "从合并回收站恢复"))
+
+	response.SuccessWithoutData(c)
+}
@@ -0,0 +1,52 @@
+package api
+
+import (
+	"github.com/JokerYuan-lang/MyNoteBook/internal/service"
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/errcode"
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/response"
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/validator"
+	"github.com/gin-gonic/gin"
+)
+
+// 语义搜索请求参数
+
+type SemanticSearchRequest struct {
+	Q     string   `form:"q" binding:"required,max=200"`           // 搜索内容（自然语言）
+	Limit int      `form:"limit" binding:"omitempty,min=1,max=50"` // 返回数量（可选，默认20）
+	Bias  *float64 `form:"bias" binding:"omitempty,min=0,max=1"`   // 语义相似度权重（可选，默认0.7；1 只按语义，0 只按关键词）
+}
+
+// EmbeddingAPI 语义搜索接口
+type EmbeddingAPI struct {
+	embeddingService *service.EmbeddingService
+}
+
+// NewEmbeddingAPI 创建 EmbeddingAPI 实例
+func NewEmbeddingAPI(embeddingService *service.EmbeddingService) *EmbeddingAPI {
+	return &EmbeddingAPI{embeddingService: embeddingService}
+}
+
+// SemanticSearch 语义搜索接口（语义相似度与关键词得分综合排序）
+func (a *EmbeddingAPI) SemanticSearch(c *gin.Context) {
+	var req SemanticSearchRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.Error(c, errcode.InvalidParam, validator.GetErrorMsg(err))
+		return
+	}
+	if req.Limit == 0 {
+		req.Limit = 20
+	}
+	bias := service.DefaultSemanticBias
+	if req.Bias != nil {
+		bias = *req.Bias
+	}
+
+	userID, _ := c.Get("user_id")
+	hits, err := a.embeddingService.SemanticSearch(userID.(uint), req.Q, req.Limit, bias)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	response.Success(c, hits)
+}
@@ -0,0 +1,114 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/JokerYuan-lang/MyNoteBook/internal/service"
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/errcode"
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/response"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// ExportAPI 笔记导出接口
+type ExportAPI struct {
+	exportService *service.ExportService
+}
+
+// NewExportAPI 创建 ExportAPI 实例
+func NewExportAPI(exportService *service.ExportService) *ExportAPI {
+	return &ExportAPI{exportService: exportService}
+}
+
+// ExportPDF 导出 PDF 接口（note_id 导出单条笔记，否则按 category 导出整个笔记本，含下级笔记本）
+func (a *ExportAPI) ExportPDF(c *gin.Context) {
+	var noteID uint64
+	category := c.Query("category")
+	if idStr := c.Query("note_id"); idStr != "" {
+		var err error
+		if noteID, err = strconv.ParseUint(idStr, 10, 32); err != nil {
+			response.Error(c, errcode.InvalidParam, "笔记ID格式错误")
+			return
+		}
+	} else if category == "" {
+		response.Error(c, errcode.InvalidParam, "请指定笔记或分类")
+		return
+	}
+
+	// 先生成到内存，出错时还能返回 JSON 错误
+	userID, _ := c.Get("user_id")
+	var buf bytes.Buffer
+	if err := a.exportService.ExportPDF(userID.(uint), uint(noteID), category, &buf); err != nil {
+		writeError(c, err)
+		return
+	}
+
+	fileName := fmt.Sprintf("notes_%s.pdf", time.Now().Format("20060102150405"))
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, fileName))
+	c.Data(http.StatusOK, "application/pdf", buf.Bytes())
+}
+
+// ExportEPUB 导出分类为 EPUB 电子书接口（category 必填；note_ids 可选，逗号分隔的手动顺序，默认按标题排序）
+func (a *ExportAPI) ExportEPUB(c *gin.Context) {
+	a.exportCategory(c, "epub", "application/epub+zip", a.exportService.ExportEPUB)
+}
+
+// ExportSite 导出分类为静态网站压缩包接口（参数同 ExportEPUB）
+func (a *ExportAPI) ExportSite(c *gin.Context) {
+	a.exportCategory(c, "zip", "application/zip", a.exportService.ExportSite)
+}
+
+// exportCategory 解析分类导出参数，生成文件后下载
+func (a *ExportAPI) exportCategory(c *gin.Context, ext, contentType string,
+	export func(userID uint, category string, noteIDs []uint, w io.Writer) error) {
+	category := c.Query("category")
+	if category == "" {
+		response.Error(c, errcode.InvalidParam, "请指定分类")
+		return
+	}
+	var noteIDs []uint
+	for _, idStr := range strings.Split(c.Query("note_ids"), ",") {
+		if idStr = strings.TrimSpace(idStr); idStr == "" {
+			continue
+		}
+		id, err := strconv.ParseUint(idStr, 10, 32)
+		if err != nil {
+			response.Error(c, errcode.InvalidParam, "笔记ID格式错误")
+			return
+		}
+		noteIDs = append(noteIDs, uint(id))
+	}
+
+	userID, _ := c.Get("user_id")
+	var buf bytes.Buffer
+	if err := export(userID.(uint), category, noteIDs, &buf); err != nil {
+		writeError(c, err)
+		return
+	}
+
+	fileName := fmt.Sprintf("%s_%s.%s", strings.ReplaceAll(category, "/", "_"), time.Now().Format("20060102150405"), ext)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename*=UTF-8''%s`, url.PathEscape(fileName)))
+	c.Data(http.StatusOK, contentType, buf.Bytes())
+}
+
+// ExportMarkdown 导出全部笔记为 Markdown 压缩包接口（流式输出 ZIP）
+func (a *ExportAPI) ExportMarkdown(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	fileName := fmt.Sprintf("notes_%s.zip", time.Now().Format("20060102150405"))
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, fileName))
+	if err := a.exportService.ExportMarkdownZip(userID.(uint), c.Writer); err != nil {
+		// 响应已开始输出，只能中断连接
+		zap.S().Errorf("导出 Markdown 失败: %v", err)
+		_ = c.Error(err)
+		c.Abort()
+	}
+}
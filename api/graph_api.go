@@ -0,0 +1,49 @@
+package api
+
+import (
+	"github.com/JokerYuan-lang/MyNoteBook/internal/service"
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/errcode"
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/response"
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/validator"
+	"github.com/gin-gonic/gin"
+)
+
+// 知识图谱请求参数
+
+type GraphRequest struct {
+	Category string `form:"category" binding:"max=255"`            // 笔记本路径（可选，含下级笔记本）
+	NoteID   uint   `form:"note_id"`                               // 中心笔记ID（可选，指定时只返回邻域）
+	Depth    int    `form:"depth" binding:"omitempty,min=1,max=3"` // 邻域深度（可选，默认1，最多3）
+}
+
+// GraphAPI 知识图谱接口
+type GraphAPI struct {
+	graphService *service.GraphService
+}
+
+// NewGraphAPI 创建 GraphAPI 实例
+func NewGraphAPI(graphService *service.GraphService) *GraphAPI {
+	return &GraphAPI{graphService: graphService}
+}
+
+// GetGraph 知识图谱接口（笔记、标签节点和链接、标签归属边）
+func (a *GraphAPI) GetGraph(c *gin.Context) {
+	var req GraphRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.Error(c, errcode.InvalidParam, validator.GetErrorMsg(err))
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	graph, err := a.graphService.GetGraph(userID.(uint), service.GraphFilter{
+		Category: req.Category,
+		NoteID:   req.NoteID,
+		Depth:    req.Depth,
+	})
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	response.Success(c, graph)
+}
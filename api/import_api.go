@@ -0,0 +1,209 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"os"
+	"strconv"
+
+	"github.com/JokerYuan-lang/MyNoteBook/internal/model"
+	"github.com/JokerYuan-lang/MyNoteBook/internal/service"
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/errcode"
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/response"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const maxImportSize = 100 << 20 // 单次导入上传总大小上限 100MB
+
+// ImportAPI 笔记导入接口
+type ImportAPI struct {
+	importService *service.ImportService
+	auditService  *service.AuditService
+}
+
+// NewImportAPI 创建 ImportAPI 实例
+func NewImportAPI(importService *service.ImportService, auditService *service.AuditService) *ImportAPI {
+	return &ImportAPI{importService: importService, auditService: auditService}
+}
+
+// ImportMarkdown 导入 Markdown 接口（表单字段 files，可多个 .md 文件或 .zip 压缩包）
+func (a *ImportAPI) ImportMarkdown(c *gin.Context) {
+	files, err := readUploadFiles(c, "files")
+	if err != nil {
+		response.Error(c, errcode.InvalidParam, err.Error())
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	job, err := a.importService.ImportMarkdown(userID.(uint), files)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	a.recordImport(c, job, len(files))
+	response.Success(c, job)
+}
+
+// ImportENEX 导入 Evernote 接口（表单字段 files，可多个 .enex 文件，文件名作为分类）
+func (a *ImportAPI) ImportENEX(c *gin.Context) {
+	files, err := readUploadFiles(c, "files")
+	if err != nil {
+		response.Error(c, errcode.InvalidParam, err.Error())
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	job, err := a.importService.ImportENEX(userID.(uint), files)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	a.recordImport(c, job, len(files))
+	response.Success(c, job)
+}
+
+// ImportJoplin 导入 Joplin 接口（表单字段 files，.jex 文件或 RAW 导出目录打包的 .zip）
+func (a *ImportAPI) ImportJoplin(c *gin.Context) {
+	files, err := readUploadFiles(c, "files")
+	if err != nil {
+		response.Error(c, errcode.InvalidParam, err.Error())
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	job, err := a.importService.ImportJoplin(userID.(uint), files)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	a.recordImport(c, job, len(files))
+	response.Success(c, job)
+}
+
+// ImportNotion 导入 Notion 接口（表单字段 files，Notion 导出的 Markdown & CSV 压缩包）
+func (a *ImportAPI) ImportNotion(c *gin.Context) {
+	files, err := readUploadFiles(c, "files")
+	if err != nil {
+		response.Error(c, errcode.InvalidParam, err.Error())
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	job, err := a.importService.ImportNotion(userID.(uint), files)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	a.recordImport(c, job, len(files))
+	response.Success(c, job)
+}
+
+// GetJob 查询导入任务进度接口（含逐文件错误）
+func (a *ImportAPI) GetJob(c *gin.Context) {
+	jobID, err := strconv.ParseUint(c.Query("job_id"), 10, 32)
+	if err != nil {
+		response.Error(c, errcode.InvalidParam, "任务ID格式错误")
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	job, err := a.importService.GetJob(userID.(uint), uint(jobID))
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{
+		"job":    job,
+		"errors": service.ParseJobErrors(job),
+	})
+}
+
+// GetJobList 最近导入任务列表接口
+func (a *ImportAPI) GetJobList(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	jobs, err := a.importService.GetJobList(userID.(uint))
+	if err != nil {
+		response.Error(c, errcode.ServerError, err.Error())
+		return
+	}
+
+	response.Success(c, jobs)
+}
+
+// recordImport 记录导入任务的审计日志（笔记在后台逐条创建，按任务记一条）
+func (a *ImportAPI) recordImport(c *gin.Context, job *model.ImportJob, fileCount int) {
+	detail := fmt.Sprintf("导入任务 #%d（%s，%d 个文件）", job.ID, job.Source, fileCount)
+	a.auditService.Record(newAuditLog(c, model.AuditActionNoteImport, model.AuditTargetImportJob, job.ID, detail))
+}
+
+// readUploadFiles 把表单中上传的文件逐个流式写入临时文件（不整体读入内存，限制总大小）
+// 临时文件由导入任务在结束后删除；这里出错时立即删除已写入的文件
+func readUploadFiles(c *gin.Context, field string) ([]service.UploadFile, error) {
+	reader, err := c.Request.MultipartReader()
+	if err != nil {
+		return nil, fmt.Errorf("请上传文件")
+	}
+
+	var (
+		files []service.UploadFile
+		total int64
+	)
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			service.RemoveUploadFiles(files)
+			return nil, fmt.Errorf("读取上传文件失败")
+		}
+		if part.FormName() != field || part.FileName() == "" {
+			part.Close()
+			continue
+		}
+
+		file, size, err := saveUploadPart(part, maxImportSize-total)
+		part.Close()
+		if file.Path != "" {
+			files = append(files, file)
+		}
+		if err != nil {
+			service.RemoveUploadFiles(files)
+			return nil, err
+		}
+		total += size
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("请上传文件")
+	}
+	return files, nil
+}
+
+// saveUploadPart 把单个上传文件写入临时文件（超过 limit 字节时返回错误）
+func saveUploadPart(part *multipart.Part, limit int64) (service.UploadFile, int64, error) {
+	file := service.UploadFile{Name: part.FileName()}
+	f, err := os.CreateTemp("", "mynotebook-import-*")
+	if err != nil {
+		zap.S().Errorf("创建导入临时文件失败: %v", err)
+		return file, 0, errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+	defer f.Close()
+	file.Path = f.Name()
+
+	size, err := io.Copy(f, io.LimitReader(part, limit+1))
+	if err != nil {
+		return file, size, fmt.Errorf("读取文件 %s 失败", file.Name)
+	}
+	if size > limit {
+		return file, size, fmt.Errorf("上传文件总大小不能超过 %dMB", maxImportSize>>20)
+	}
+	return file, size, nil
+}
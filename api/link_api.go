@@ -0,0 +1,29 @@
+package api
+
+import (
+	"github.com/JokerYuan-lang/MyNoteBook/internal/service"
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/response"
+	"github.com/gin-gonic/gin"
+)
+
+// LinkAPI 笔记链接接口
+type LinkAPI struct {
+	linkService *service.LinkService
+}
+
+// NewLinkAPI 创建 LinkAPI 实例
+func NewLinkAPI(linkService *service.LinkService) *LinkAPI {
+	return &LinkAPI{linkService: linkService}
+}
+
+// GetBrokenLinks 断链报告接口（目标笔记不存在或已删除的链接）
+func (a *LinkAPI) GetBrokenLinks(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	links, err := a.linkService.GetBrokenLinks(userID.(uint))
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{"list": links, "total": len(links)})
+}
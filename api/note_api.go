@@ -3,6 +3,7 @@ package api
 import (
 	"strconv"
 
+	"github.com/JokerYuan-lang/MyNoteBook/internal/model"
 	"github.com/JokerYuan-lang/MyNoteBook/internal/service"
 	"github.com/JokerYuan-lang/MyNoteBook/pkg/errcode"
 	"github.com/JokerYuan-lang/MyNoteBook/pkg/response"
@@ -16,8 +17,10 @@ import (
 type CreateNoteRequest struct {
 	Title    string   `json:"title" binding:"required,max=100"` // 标题最多100位
 	Content  string   `json:"content" binding:"required"`       // 内容必填
-	Category string   `json:"category" binding:"max=50"`        // 分类最多50位
+	Category string   `json:"category" binding:"max=255"`       // 笔记本路径（如「工作/项目A」，不存在时自动创建）
 	TagNames []string `json:"tag_names" binding:"required"`     // 标签必填（至少一个）
+	// 内容格式（可选，默认 markdown；html 会按白名单过滤）
+	ContentFormat string `json:"content_format" binding:"omitempty,oneof=plain markdown html"`
 }
 
 // 更新笔记请求参数
@@ -26,26 +29,58 @@ type UpdateNoteRequest struct {
 	NoteID   uint     `form:"note_id" binding:"required,min=1"` // 笔记ID
 	Title    string   `form:"title" binding:"required,max=100"` // 标题
 	Content  string   `form:"content" binding:"required"`       // 内容
-	Category string   `form:"category" binding:"max=50"`        // 分类
+	Category string   `form:"category" binding:"max=255"`       // 笔记本路径（不存在时自动创建）
 	TagNames []string `form:"tag_names" binding:"required"`     // 标签
+	// 内容格式（可选，不传保持原格式）
+	ContentFormat string `form:"content_format" binding:"omitempty,oneof=plain markdown html"`
 }
 
 // 笔记列表请求参数（分页+筛选）
 
 type NoteListRequest struct {
-	Page     int    `form:"page" binding:"required,min=1"`             // 页码（至少1）
-	PageSize int    `form:"page_size" binding:"required,min=1,max=50"` // 每页数量（1-50）
-	Category string `form:"category,omitempty"`                        // 分类（可选）
+	Page         int    `form:"page" binding:"required,min=1"`             // 页码（至少1）
+	PageSize     int    `form:"page_size" binding:"required,min=1,max=50"` // 每页数量（1-50）
+	Category     string `form:"category,omitempty"`                        // 笔记本路径（可选，含下级笔记本）
+	Tag          string `form:"tag" binding:"max=100"`                     // 标签（可选，含下级标签）
+	HasOpenItems bool   `form:"has_open_items"`                            // 只看有未完成清单项的笔记（可选）
+	Pinned       bool   `form:"pinned"`                                    // 只看置顶笔记（可选）
+	Favorite     bool   `form:"favorite"`                                  // 只看收藏笔记（可选）
+	// 归档筛选（可选，默认 exclude 不显示已归档笔记；include 包含，only 只看已归档）
+	Archived string `form:"archived" binding:"omitempty,oneof=exclude include only"`
+	Keyword  string `form:"keyword" binding:"max=100"` // 关键词（可选，匹配标题和正文）
+	Facets   bool   `form:"facets"`                    // 是否同时返回分类/标签/月份分面统计（可选）
+}
+
+// 置顶笔记请求参数
+
+type PinNoteRequest struct {
+	NoteID uint  `json:"note_id" binding:"required,min=1"` // 笔记ID
+	Pinned *bool `json:"pinned"`                           // 目标状态（可选，不传则取反）
+}
+
+// 收藏笔记请求参数
+
+type FavoriteNoteRequest struct {
+	NoteID   uint  `json:"note_id" binding:"required,min=1"` // 笔记ID
+	Favorite *bool `json:"favorite"`                         // 目标状态（可选，不传则取反）
+}
+
+// 归档笔记请求参数
+
+type ArchiveNoteRequest struct {
+	NoteID   uint  `json:"note_id" binding:"required,min=1"` // 笔记ID
+	Archived *bool `json:"archived"`                         // 目标状态（可选，不传则取反；归档时取消置顶）
 }
 
 // NoteAPI 笔记接口
 type NoteAPI struct {
-	noteService *service.NoteService
+	noteService  *service.NoteService
+	auditService *service.AuditService
 }
 
 // NewNoteAPI 创建 NoteAPI 实例
-func NewNoteAPI(noteService *service.NoteService) *NoteAPI {
-	return &NoteAPI{noteService: noteService}
+func NewNoteAPI(noteService *service.NoteService, auditService *service.AuditService) *NoteAPI {
+	return &NoteAPI{noteService: noteService, auditService: auditService}
 }
 
 // CreateNote 创建笔记接口
@@ -60,11 +95,12 @@ func (a *NoteAPI) CreateNote(c *gin.Context) {
 	userID, _ := c.Get("user_id")
 
 	// 调用业务逻辑
-	err := a.noteService.CreateNote(userID.(uint), req.Title, req.Content, req.Category, req.TagNames)
+	noteID, err := a.noteService.CreateNote(userID.(uint), req.Title, req.Content, req.ContentFormat, req.Category, req.TagNames)
 	if err != nil {
 		response.Error(c, errcode.ServerError, err.Error())
 		return
 	}
+	a.auditService.Record(newAuditLog(c, model.AuditActionNoteCreate, model.AuditTargetNote, noteID, req.Title))
 
 	response.SuccessWithoutData(c)
 }
@@ -83,19 +119,40 @@ func (a *NoteAPI) GetNoteList(c *gin.Context) {
 	}
 	zap.S().Info("page", req.Page)
 	userID, _ := c.Get("user_id")
-	notes, total, err := a.noteService.GetNoteList(userID.(uint), req.Page, req.PageSize, req.Category)
+	if req.Archived == "" {
+		req.Archived = service.ArchivedExclude // 默认不显示已归档笔记
+	}
+	filter := service.NoteListFilter{
+		Category:     req.Category,
+		Tag:          req.Tag,
+		HasOpenItems: req.HasOpenItems,
+		Pinned:       req.Pinned,
+		Favorite:     req.Favorite,
+		Archived:     req.Archived,
+		Keyword:      req.Keyword,
+	}
+	notes, total, err := a.noteService.GetNoteList(userID.(uint), req.Page, req.PageSize, filter)
 	if err != nil {
 		response.Error(c, errcode.ServerError, err.Error())
 		return
 	}
 
 	// 返回列表+分页信息
-	response.Success(c, gin.H{
+	data := gin.H{
 		"list":      notes,
 		"total":     total,
 		"page":      req.Page,
 		"page_size": req.PageSize,
-	})
+	}
+	if req.Facets {
+		facets, err := a.noteService.GetNoteFacets(userID.(uint), filter)
+		if err != nil {
+			response.Error(c, errcode.ServerError, err.Error())
+			return
+		}
+		data["facets"] = facets
+	}
+	response.Success(c, data)
 }
 
 // GetNoteByID 查询单条笔记接口
@@ -122,6 +179,24 @@ func (a *NoteAPI) GetNoteByID(c *gin.Context) {
 	response.Success(c, note)
 }
 
+// RenderNote 笔记渲染为 HTML 接口
+func (a *NoteAPI) RenderNote(c *gin.Context) {
+	noteID, err := strconv.ParseUint(c.Query("note_id"), 10, 32)
+	if err != nil {
+		response.Error(c, errcode.InvalidParam, "笔记ID格式错误")
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	html, err := a.noteService.RenderNote(userID.(uint), uint(noteID))
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{"html": html})
+}
+
 // UpdateNote 更新笔记接口
 func (a *NoteAPI) UpdateNote(c *gin.Context) {
 	var req UpdateNoteRequest
@@ -131,7 +206,7 @@ func (a *NoteAPI) UpdateNote(c *gin.Context) {
 	}
 
 	userID, _ := c.Get("user_id")
-	err := a.noteService.UpdateNote(userID.(uint), req.NoteID, req.Title, req.Content, req.Category, req.TagNames)
+	err := a.noteService.UpdateNote(userID.(uint), req.NoteID, req.Title, req.Content, req.ContentFormat, req.Category, req.TagNames)
 	if err != nil {
 		if err.Error() == errcode.GetMsg(errcode.NotFound) {
 			response.ErrorWithDefaultMsg(c, errcode.NotFound)
@@ -140,10 +215,65 @@ func (a *NoteAPI) UpdateNote(c *gin.Context) {
 		}
 		return
 	}
+	a.auditService.Record(newAuditLog(c, model.AuditActionNoteUpdate, model.AuditTargetNote, req.NoteID, req.Title))
 
 	response.SuccessWithoutData(c)
 }
 
+// PinNote 置顶/取消置顶笔记接口
+func (a *NoteAPI) PinNote(c *gin.Context) {
+	var req PinNoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, errcode.InvalidParam, validator.GetErrorMsg(err))
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	pinned, err := a.noteService.PinNote(userID.(uint), req.NoteID, req.Pinned)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{"note_id": req.NoteID, "pinned": pinned})
+}
+
+// FavoriteNote 收藏/取消收藏笔记接口
+func (a *NoteAPI) FavoriteNote(c *gin.Context) {
+	var req FavoriteNoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, errcode.InvalidParam, validator.GetErrorMsg(err))
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	favorite, err := a.noteService.FavoriteNote(userID.(uint), req.NoteID, req.Favorite)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{"note_id": req.NoteID, "favorite": favorite})
+}
+
+// ArchiveNote 归档/取消归档笔记接口
+func (a *NoteAPI) ArchiveNote(c *gin.Context) {
+	var req ArchiveNoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, errcode.InvalidParam, validator.GetErrorMsg(err))
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	archived, err := a.noteService.ArchiveNote(userID.(uint), req.NoteID, req.Archived)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{"note_id": req.NoteID, "archived": archived})
+}
+
 // DeleteNote 删除笔记接口
 func (a *NoteAPI) DeleteNote(c *gin.Context) {
 	noteIDStr := c.Query("note_id")
@@ -163,6 +293,7 @@ func (a *NoteAPI) DeleteNote(c *gin.Context) {
 		}
 		return
 	}
+	a.auditService.Record(newAuditLog(c, model.AuditActionNoteDelete, model.AuditTargetNote, uint(noteID), ""))
 
 	response.SuccessWithoutData(c)
 }
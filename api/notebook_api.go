@@ -0,0 +1,124 @@
+package api
+
+import (
+	"strconv"
+
+	"github.com/JokerYuan-lang/MyNoteBook/internal/service"
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/errcode"
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/response"
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/validator"
+	"github.com/gin-gonic/gin"
+)
+
+// 创建笔记本请求参数
+
+type CreateNotebookRequest struct {
+	ParentID uint   `json:"parent_id"`                      // 上级笔记本ID（可选，不传创建顶级笔记本）
+	Name     string `json:"name" binding:"required,max=50"` // 名称（不能包含 /）
+}
+
+// 重命名笔记本请求参数
+
+type RenameNotebookRequest struct {
+	NotebookID uint   `json:"notebook_id" binding:"required,min=1"` // 笔记本ID
+	Name       string `json:"name" binding:"required,max=50"`       // 新名称
+}
+
+// 移动笔记本请求参数
+
+type MoveNotebookRequest struct {
+	NotebookID uint `json:"notebook_id" binding:"required,min=1"` // 笔记本ID
+	ParentID   uint `json:"parent_id"`                            // 新的上级笔记本ID（0 表示移到顶级）
+}
+
+// NotebookAPI 笔记本接口
+type NotebookAPI struct {
+	notebookService *service.NotebookService
+}
+
+// NewNotebookAPI 创建 NotebookAPI 实例
+func NewNotebookAPI(notebookService *service.NotebookService) *NotebookAPI {
+	return &NotebookAPI{notebookService: notebookService}
+}
+
+// GetNotebookTree 笔记本树接口（含每个笔记本的笔记数）
+func (a *NotebookAPI) GetNotebookTree(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	tree, err := a.notebookService.GetNotebookTree(userID.(uint))
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	response.Success(c, tree)
+}
+
+// CreateNotebook 创建笔记本接口
+func (a *NotebookAPI) CreateNotebook(c *gin.Context) {
+	var req CreateNotebookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, errcode.InvalidParam, validator.GetErrorMsg(err))
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	notebook, err := a.notebookService.CreateNotebook(userID.(uint), req.ParentID, req.Name)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	response.Success(c, notebook)
+}
+
+// RenameNotebook 重命名笔记本接口
+func (a *NotebookAPI) RenameNotebook(c *gin.Context) {
+	var req RenameNotebookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, errcode.InvalidParam, validator.GetErrorMsg(err))
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	if err := a.notebookService.RenameNotebook(userID.(uint), req.NotebookID, req.Name); err != nil {
+		writeError(c, err)
+		return
+	}
+
+	response.SuccessWithoutData(c)
+}
+
+// MoveNotebook 移动笔记本接口
+func (a *NotebookAPI) MoveNotebook(c *gin.Context) {
+	var req MoveNotebookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, errcode.InvalidParam, validator.GetErrorMsg(err))
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	if err := a.notebookService.MoveNotebook(userID.(uint), req.NotebookID, req.ParentID); err != nil {
+		writeError(c, err)
+		return
+	}
+
+	response.SuccessWithoutData(c)
+}
+
+// DeleteNotebook 删除笔记本接口（cascade=true 连同下级笔记本和笔记一起删除，否则移到上级笔记本）
+func (a *NotebookAPI) DeleteNotebook(c *gin.Context) {
+	notebookID, err := strconv.ParseUint(c.Query("notebook_id"), 10, 32)
+	if err != nil {
+		response.Error(c, errcode.InvalidParam, "笔记本ID格式错误")
+		return
+	}
+	cascade := c.Query("cascade") == "true"
+
+	userID, _ := c.Get("user_id")
+	if err := a.notebookService.DeleteNotebook(userID.(uint), uint(notebookID), cascade); err != nil {
+		writeError(c, err)
+		return
+	}
+
+	response.SuccessWithoutData(c)
+}
@@ -0,0 +1,96 @@
+package api
+
+import (
+	"github.com/JokerYuan-lang/MyNoteBook/internal/service"
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/errcode"
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/response"
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/validator"
+	"github.com/gin-gonic/gin"
+)
+
+// 通知列表请求参数
+
+type NotificationListRequest struct {
+	Page       int  `form:"page" binding:"required,min=1"`             // 页码（至少1）
+	PageSize   int  `form:"page_size" binding:"required,min=1,max=50"` // 每页数量（1-50）
+	UnreadOnly bool `form:"unread_only"`                               // 只看未读（可选）
+}
+
+// 标记已读请求参数
+
+type MarkReadRequest struct {
+	IDs []uint `json:"ids" binding:"required,min=1"` // 通知ID列表
+}
+
+// NotificationAPI 通知接口
+type NotificationAPI struct {
+	notificationService *service.NotificationService
+}
+
+// NewNotificationAPI 创建 NotificationAPI 实例
+func NewNotificationAPI(notificationService *service.NotificationService) *NotificationAPI {
+	return &NotificationAPI{notificationService: notificationService}
+}
+
+// GetNotificationList 分页查询通知列表接口
+func (a *NotificationAPI) GetNotificationList(c *gin.Context) {
+	var req NotificationListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.Error(c, errcode.InvalidParam, validator.GetErrorMsg(err))
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	notifications, total, err := a.notificationService.GetNotificationList(userID.(uint), req.Page, req.PageSize, req.UnreadOnly)
+	if err != nil {
+		response.Error(c, errcode.ServerError, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"list":      notifications,
+		"total":     total,
+		"page":      req.Page,
+		"page_size": req.PageSize,
+	})
+}
+
+// GetUnreadCount 未读通知数量接口
+func (a *NotificationAPI) GetUnreadCount(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	count, err := a.notificationService.GetUnreadCount(userID.(uint))
+	if err != nil {
+		response.Error(c, errcode.ServerError, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{"count": count})
+}
+
+// MarkRead 标记指定通知已读接口
+func (a *NotificationAPI) MarkRead(c *gin.Context) {
+	var req MarkReadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, errcode.InvalidParam, validator.GetErrorMsg(err))
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	if err := a.notificationService.MarkRead(userID.(uint), req.IDs); err != nil {
+		response.Error(c, errcode.ServerError, err.Error())
+		return
+	}
+
+	response.SuccessWithoutData(c)
+}
+
+// MarkAllRead 全部标记已读接口
+func (a *NotificationAPI) MarkAllRead(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	if err := a.notificationService.MarkRead(userID.(uint), nil); err != nil {
+		response.Error(c, errcode.ServerError, err.Error())
+		return
+	}
+
+	response.SuccessWithoutData(c)
+}
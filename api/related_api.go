@@ -0,0 +1,47 @@
+package api
+
+import (
+	"github.com/JokerYuan-lang/MyNoteBook/internal/service"
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/errcode"
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/response"
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/validator"
+	"github.com/gin-gonic/gin"
+)
+
+// 相关笔记请求参数
+
+type RelatedNotesRequest struct {
+	NoteID uint `form:"note_id" binding:"required,min=1"`       // 笔记ID
+	Limit  int  `form:"limit" binding:"omitempty,min=1,max=20"` // 返回数量（可选，默认5）
+}
+
+// RelatedAPI 相关笔记接口
+type RelatedAPI struct {
+	relatedService *service.RelatedService
+}
+
+// NewRelatedAPI 创建 RelatedAPI 实例
+func NewRelatedAPI(relatedService *service.RelatedService) *RelatedAPI {
+	return &RelatedAPI{relatedService: relatedService}
+}
+
+// GetRelatedNotes 相关笔记推荐接口（按内容相似度和共同标签排序）
+func (a *RelatedAPI) GetRelatedNotes(c *gin.Context) {
+	var req RelatedNotesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.Error(c, errcode.InvalidParam, validator.GetErrorMsg(err))
+		return
+	}
+	if req.Limit == 0 {
+		req.Limit = 5
+	}
+
+	userID, _ := c.Get("user_id")
+	notes, err := a.relatedService.GetRelatedNotes(userID.(uint), req.NoteID, req.Limit)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	response.Success(c, notes)
+}
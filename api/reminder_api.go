@@ -0,0 +1,134 @@
+package api
+
+import (
+	"time"
+
+	"github.com/JokerYuan-lang/MyNoteBook/internal/model"
+	"github.com/JokerYuan-lang/MyNoteBook/internal/service"
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/errcode"
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/response"
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/validator"
+	"github.com/gin-gonic/gin"
+)
+
+// 设置提醒/截止时间请求参数（RFC3339 格式，传 null 表示清除）
+
+type ScheduleRequest struct {
+	NoteID   uint       `json:"note_id" binding:"required,min=1"` // 笔记ID
+	RemindAt *time.Time `json:"remind_at"`                        // 提醒时间
+	DueAt    *time.Time `json:"due_at"`                           // 截止时间
+}
+
+// 稍后提醒请求参数
+
+type SnoozeRequest struct {
+	NoteID  uint `json:"note_id" binding:"required,min=1"`           // 笔记ID
+	Minutes int  `json:"minutes" binding:"required,min=1,max=10080"` // 推迟分钟数（最多7天）
+}
+
+// 完成笔记请求参数
+
+type CompleteRequest struct {
+	NoteID    uint  `json:"note_id" binding:"required,min=1"` // 笔记ID
+	Completed *bool `json:"completed" binding:"required"`     // 是否完成
+}
+
+// ReminderAPI 提醒与日历接口
+type ReminderAPI struct {
+	reminderService *service.ReminderService
+	auditService    *service.AuditService
+}
+
+// NewReminderAPI 创建 ReminderAPI 实例
+func NewReminderAPI(reminderService *service.ReminderService, auditService *service.AuditService) *ReminderAPI {
+	return &ReminderAPI{reminderService: reminderService, auditService: auditService}
+}
+
+// SetSchedule 设置提醒/截止时间接口
+func (a *ReminderAPI) SetSchedule(c *gin.Context) {
+	var req ScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, errcode.InvalidParam, validator.GetErrorMsg(err))
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	err := a.reminderService.SetSchedule(userID.(uint), req.NoteID, req.RemindAt, req.DueAt)
+	writeNoteResult(c, err)
+}
+
+// Snooze 稍后提醒接口
+func (a *ReminderAPI) Snooze(c *gin.Context) {
+	var req SnoozeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, errcode.InvalidParam, validator.GetErrorMsg(err))
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	err := a.reminderService.Snooze(userID.(uint), req.NoteID, req.Minutes)
+	writeNoteResult(c, err)
+}
+
+// Complete 标记完成接口
+func (a *ReminderAPI) Complete(c *gin.Context) {
+	var req CompleteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, errcode.InvalidParam, validator.GetErrorMsg(err))
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	err := a.reminderService.Complete(userID.(uint), req.NoteID, *req.Completed)
+	writeNoteResult(c, err)
+}
+
+// GetCalendarURL 获取日历订阅地址接口（reset=true 时重新生成）
+func (a *ReminderAPI) GetCalendarURL(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	reset := c.Query("reset") == "true"
+	token, issued, err := a.reminderService.GetCalendarToken(userID.(uint), reset)
+	if err != nil {
+		response.Error(c, errcode.ServerError, err.Error())
+		return
+	}
+
+	// 日历令牌可直接读取笔记，签发和重置都要留痕
+	if issued {
+		detail := "生成日历订阅令牌"
+		if reset {
+			detail = "重置日历订阅令牌"
+		}
+		a.auditService.Record(newAuditLog(c, model.AuditActionTokenIssued, model.AuditTargetUser, userID.(uint), detail))
+	}
+
+	response.Success(c, gin.H{
+		"token": token,
+		"path":  "/api/v1/public/calendar.ics?token=" + token,
+	})
+}
+
+// GetCalendar 日历订阅接口（公开访问，凭令牌识别用户，返回 .ics）
+func (a *ReminderAPI) GetCalendar(c *gin.Context) {
+	content, err := a.reminderService.BuildCalendar(c.Query("token"))
+	if err != nil {
+		if err.Error() == errcode.GetMsg(errcode.NotFound) {
+			response.ErrorWithDefaultMsg(c, errcode.NotFound)
+		} else {
+			response.Error(c, errcode.ServerError, err.Error())
+		}
+		return
+	}
+
+	c.Data(200, "text/calendar; charset=utf-8", []byte(content))
+}
+
+// writeNoteResult 按笔记操作结果返回响应（笔记不存在返回 404）
+func writeNoteResult(c *gin.Context, err error) {
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	response.SuccessWithoutData(c)
+}
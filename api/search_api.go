@@ -0,0 +1,183 @@
+package api
+
+import (
+	"strconv"
+
+	"github.com/JokerYuan-lang/MyNoteBook/internal/service"
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/errcode"
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/response"
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/validator"
+	"github.com/gin-gonic/gin"
+)
+
+// 搜索请求参数
+
+type SearchRequest struct {
+	Query    string `form:"q" binding:"max=500"`                       // 搜索语句
+	Page     int    `form:"page" binding:"required,min=1"`             // 页码
+	PageSize int    `form:"page_size" binding:"required,min=1,max=50"` // 每页数量
+	Facets   bool   `form:"facets"`                                    // 是否同时返回分面统计
+}
+
+// 保存搜索请求参数
+
+type SavedSearchRequest struct {
+	Name  string `json:"name" binding:"required,max=50"`   // 名称
+	Query string `json:"query" binding:"required,max=500"` // 搜索语句
+}
+
+// 修改保存的搜索请求参数
+
+type UpdateSavedSearchRequest struct {
+	SearchID uint   `json:"search_id" binding:"required,min=1"` // 保存的搜索ID
+	Name     string `json:"name" binding:"required,max=50"`     // 名称
+	Query    string `json:"query" binding:"required,max=500"`   // 搜索语句
+}
+
+// 执行保存的搜索请求参数
+
+type RunSavedSearchRequest struct {
+	SearchID uint `form:"search_id" binding:"required,min=1"`        // 保存的搜索ID
+	Page     int  `form:"page" binding:"required,min=1"`             // 页码
+	PageSize int  `form:"page_size" binding:"required,min=1,max=50"` // 每页数量
+	Facets   bool `form:"facets"`                                    // 是否同时返回分面统计
+}
+
+// SearchAPI 搜索接口
+type SearchAPI struct {
+	searchService *service.SearchService
+}
+
+// NewSearchAPI 创建 SearchAPI 实例
+func NewSearchAPI(searchService *service.SearchService) *SearchAPI {
+	return &SearchAPI{searchService: searchService}
+}
+
+// Search 按搜索语句查询笔记接口
+func (a *SearchAPI) Search(c *gin.Context) {
+	var req SearchRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.Error(c, errcode.InvalidParam, validator.GetErrorMsg(err))
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	notes, total, err := a.searchService.Search(userID.(uint), req.Query, req.Page, req.PageSize)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	data := gin.H{
+		"list":      notes,
+		"total":     total,
+		"page":      req.Page,
+		"page_size": req.PageSize,
+	}
+	if req.Facets {
+		facets, err := a.searchService.SearchFacets(userID.(uint), req.Query)
+		if err != nil {
+			writeError(c, err)
+			return
+		}
+		data["facets"] = facets
+	}
+	response.Success(c, data)
+}
+
+// CreateSavedSearch 保存搜索接口
+func (a *SearchAPI) CreateSavedSearch(c *gin.Context) {
+	var req SavedSearchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, errcode.InvalidParam, validator.GetErrorMsg(err))
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	search, err := a.searchService.CreateSavedSearch(userID.(uint), req.Name, req.Query)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	response.Success(c, search)
+}
+
+// GetSavedSearchList 保存的搜索列表接口
+func (a *SearchAPI) GetSavedSearchList(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	searches, err := a.searchService.GetSavedSearchList(userID.(uint))
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	response.Success(c, searches)
+}
+
+// UpdateSavedSearch 修改保存的搜索接口
+func (a *SearchAPI) UpdateSavedSearch(c *gin.Context) {
+	var req UpdateSavedSearchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, errcode.InvalidParam, validator.GetErrorMsg(err))
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	if err := a.searchService.UpdateSavedSearch(userID.(uint), req.SearchID, req.Name, req.Query); err != nil {
+		writeError(c, err)
+		return
+	}
+
+	response.SuccessWithoutData(c)
+}
+
+// DeleteSavedSearch 删除保存的搜索接口
+func (a *SearchAPI) DeleteSavedSearch(c *gin.Context) {
+	searchID, err := strconv.ParseUint(c.Query("search_id"), 10, 32)
+	if err != nil {
+		response.Error(c, errcode.InvalidParam, "搜索ID格式错误")
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	if err := a.searchService.DeleteSavedSearch(userID.(uint), uint(searchID)); err != nil {
+		writeError(c, err)
+		return
+	}
+
+	response.SuccessWithoutData(c)
+}
+
+// RunSavedSearch 执行保存的搜索接口
+func (a *SearchAPI) RunSavedSearch(c *gin.Context) {
+	var req RunSavedSearchRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.Error(c, errcode.InvalidParam, validator.GetErrorMsg(err))
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	search, notes, total, err := a.searchService.RunSavedSearch(userID.(uint), req.SearchID, req.Page, req.PageSize)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	data := gin.H{
+		"search":    search,
+		"list":      notes,
+		"total":     total,
+		"page":      req.Page,
+		"page_size": req.PageSize,
+	}
+	if req.Facets {
+		facets, err := a.searchService.SearchFacets(userID.(uint), search.Query)
+		if err != nil {
+			writeError(c, err)
+			return
+		}
+		data["facets"] = facets
+	}
+	response.Success(c, data)
+}
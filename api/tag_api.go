@@ -0,0 +1,169 @@
+package api
+
+import (
+	"strconv"
+
+	"github.com/JokerYuan-lang/MyNoteBook/internal/service"
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/errcode"
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/response"
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/validator"
+	"github.com/gin-gonic/gin"
+)
+
+// 标签推荐请求参数
+
+type SuggestTagsRequest struct {
+	Title         string `json:"title" binding:"max=100"`                                      // 草稿标题
+	Content       string `json:"content"`                                                      // 草稿内容
+	ContentFormat string `json:"content_format" binding:"omitempty,oneof=plain markdown html"` // 内容格式（可选）
+	Limit         int    `json:"limit" binding:"omitempty,min=1,max=20"`                       // 返回数量（可选，默认5）
+}
+
+// 自动打标签规则请求参数
+
+type TagRuleRequest struct {
+	TagName   string `json:"tag_name" binding:"required,max=100"`               // 命中后添加的标签
+	MatchType string `json:"match_type" binding:"required,oneof=keyword regex"` // 匹配方式（keyword：包含关键词；regex：正则表达式）
+	Pattern   string `json:"pattern" binding:"required,max=255"`                // 关键词或正则表达式
+}
+
+// 修改自动打标签规则请求参数
+
+type UpdateTagRuleRequest struct {
+	RuleID    uint   `json:"rule_id" binding:"required,min=1"`                  // 规则ID
+	TagName   string `json:"tag_name" binding:"required,max=100"`               // 命中后添加的标签
+	MatchType string `json:"match_type" binding:"required,oneof=keyword regex"` // 匹配方式
+	Pattern   string `json:"pattern" binding:"required,max=255"`                // 关键词或正则表达式
+	Enabled   *bool  `json:"enabled"`                                           // 是否启用（不传时保持不变）
+}
+
+// 重命名标签请求参数
+
+type RenameTagRequest struct {
+	Name    string `json:"name" binding:"required,max=100"`     // 原标签名（多级标签为完整路径）
+	NewName string `json:"new_name" binding:"required,max=100"` // 新标签名（下级标签一并改名，已存在时合并）
+}
+
+// TagAPI 标签树、标签推荐和自动打标签规则接口
+type TagAPI struct {
+	tagService *service.TagService
+}
+
+// NewTagAPI 创建 TagAPI 实例
+func NewTagAPI(tagService *service.TagService) *TagAPI {
+	return &TagAPI{tagService: tagService}
+}
+
+// GetTagTree 标签树接口（多级标签按 / 分层，含笔记数）
+func (a *TagAPI) GetTagTree(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	tree, err := a.tagService.GetTagTree(userID.(uint))
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	response.Success(c, tree)
+}
+
+// RenameTag 重命名标签接口（下级标签一并改名）
+func (a *TagAPI) RenameTag(c *gin.Context) {
+	var req RenameTagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, errcode.InvalidParam, validator.GetErrorMsg(err))
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	if err := a.tagService.RenameTag(userID.(uint), req.Name, req.NewName); err != nil {
+		writeError(c, err)
+		return
+	}
+
+	response.SuccessWithoutData(c)
+}
+
+// SuggestTags 标签推荐接口（根据草稿内容推荐已有标签）
+func (a *TagAPI) SuggestTags(c *gin.Context) {
+	var req SuggestTagsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, errcode.InvalidParam, validator.GetErrorMsg(err))
+		return
+	}
+	if req.Limit == 0 {
+		req.Limit = 5
+	}
+
+	userID, _ := c.Get("user_id")
+	suggestions, err := a.tagService.SuggestTags(userID.(uint), req.Title, req.Content, req.ContentFormat, req.Limit)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	response.Success(c, suggestions)
+}
+
+// CreateTagRule 创建自动打标签规则接口
+func (a *TagAPI) CreateTagRule(c *gin.Context) {
+	var req TagRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, errcode.InvalidParam, validator.GetErrorMsg(err))
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	rule, err := a.tagService.CreateTagRule(userID.(uint), req.TagName, req.MatchType, req.Pattern)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	response.Success(c, rule)
+}
+
+// GetTagRuleList 自动打标签规则列表接口
+func (a *TagAPI) GetTagRuleList(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	rules, err := a.tagService.GetTagRuleList(userID.(uint))
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	response.Success(c, rules)
+}
+
+// UpdateTagRule 修改自动打标签规则接口
+func (a *TagAPI) UpdateTagRule(c *gin.Context) {
+	var req UpdateTagRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, errcode.InvalidParam, validator.GetErrorMsg(err))
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	if err := a.tagService.UpdateTagRule(userID.(uint), req.RuleID, req.TagName, req.MatchType, req.Pattern, req.Enabled); err != nil {
+		writeError(c, err)
+		return
+	}
+
+	response.SuccessWithoutData(c)
+}
+
+// DeleteTagRule 删除自动打标签规则接口
+func (a *TagAPI) DeleteTagRule(c *gin.Context) {
+	ruleID, err := strconv.ParseUint(c.Query("rule_id"), 10, 32)
+	if err != nil {
+		response.Error(c, errcode.InvalidParam, "规则ID格式错误")
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	if err := a.tagService.DeleteTagRule(userID.(uint), uint(ruleID)); err != nil {
+		writeError(c, err)
+		return
+	}
+
+	response.SuccessWithoutData(c)
+}
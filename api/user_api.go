@@ -1,6 +1,7 @@
 package api
 
 import (
+	"github.com/JokerYuan-lang/MyNoteBook/internal/model"
 	"github.com/JokerYuan-lang/MyNoteBook/internal/service"
 	"github.com/JokerYuan-lang/MyNoteBook/pkg/errcode"
 	"github.com/JokerYuan-lang/MyNoteBook/pkg/response"
@@ -25,12 +26,13 @@ type LoginRequest struct {
 
 // UserAPI 用户接口
 type UserAPI struct {
-	userService *service.UserService
+	userService  *service.UserService
+	auditService *service.AuditService
 }
 
 // NewUserAPI 创建 UserAPI 实例
-func NewUserAPI(userService *service.UserService) *UserAPI {
-	return &UserAPI{userService: userService}
+func NewUserAPI(userService *service.UserService, auditService *service.AuditService) *UserAPI {
+	return &UserAPI{userService: userService, auditService: auditService}
 }
 
 // Register 用户注册接口
@@ -43,12 +45,17 @@ func (a *UserAPI) Register(c *gin.Context) {
 	}
 
 	// 调用业务逻辑
-	err := a.userService.Register(req.Username, req.Password, req.Email)
+	userID, err := a.userService.Register(req.Username, req.Password, req.Email)
 	if err != nil {
 		response.Error(c, errcode.DuplicateData, err.Error())
 		return
 	}
 
+	// 记录审计日志
+	log := newAuditLog(c, model.AuditActionRegister, model.AuditTargetUser, userID, "")
+	log.ActorID, log.ActorName = userID, req.Username
+	a.auditService.Record(log)
+
 	// 返回成功
 	response.SuccessWithoutData(c)
 }
@@ -62,12 +69,23 @@ func (a *UserAPI) Login(c *gin.Context) {
 	}
 
 	// 调用业务逻辑生成 Token
-	token, err := a.userService.Login(req.Username, req.Password)
+	token, userID, err := a.userService.Login(req.Username, req.Password)
 	if err != nil {
+		// 登录失败也要留痕：未通过认证，操作者ID记为0，被尝试登录的账号记为对象（用户不存在时为0）
+		log := newAuditLog(c, model.AuditActionLoginFailed, model.AuditTargetUser, userID, err.Error())
+		log.ActorID, log.ActorName = 0, req.Username
+		a.auditService.Record(log)
 		response.Error(c, errcode.PasswordError, err.Error())
 		return
 	}
 
+	// 记录登录和 Token 签发
+	for _, action := range []string{model.AuditActionLogin, model.AuditActionTokenIssued} {
+		log := newAuditLog(c, action, model.AuditTargetUser, userID, "")
+		log.ActorID, log.ActorName = userID, req.Username
+		a.auditService.Record(log)
+	}
+
 	// 返回 Token
 	response.Success(c, gin.H{"token": token})
 }
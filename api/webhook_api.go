@@ -0,0 +1,167 @@
+package api
+
+import (
+	"strconv"
+
+	"github.com/JokerYuan-lang/MyNoteBook/internal/service"
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/errcode"
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/response"
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/validator"
+	"github.com/gin-gonic/gin"
+)
+
+// 创建 Webhook 请求参数
+
+type CreateWebhookRequest struct {
+	URL       string   `json:"url" binding:"required,url,max=500"` // 推送地址
+	Events    []string `json:"events" binding:"required,min=1"`    // 订阅事件（note.created/note.updated/note.deleted）
+	TagFilter []string `json:"tag_filter"`                         // 标签过滤（可选，为空不过滤）
+}
+
+// 启用/停用 Webhook 请求参数
+
+type SetWebhookEnabledRequest struct {
+	WebhookID uint  `json:"webhook_id" binding:"required,min=1"` // Webhook ID
+	Enabled   *bool `json:"enabled" binding:"required"`          // 是否启用
+}
+
+// 投递记录请求参数
+
+type DeliveryListRequest struct {
+	Page      int  `form:"page" binding:"required,min=1"`             // 页码（至少1）
+	PageSize  int  `form:"page_size" binding:"required,min=1,max=50"` // 每页数量（1-50）
+	WebhookID uint `form:"webhook_id"`                                // Webhook ID（可选）
+}
+
+// WebhookAPI Webhook 接口
+type WebhookAPI struct {
+	webhookService *service.WebhookService
+}
+
+// NewWebhookAPI 创建 WebhookAPI 实例
+func NewWebhookAPI(webhookService *service.WebhookService) *WebhookAPI {
+	return &WebhookAPI{webhookService: webhookService}
+}
+
+// CreateWebhook 创建 Webhook 接口（签名密钥只在这里返回一次）
+func (a *WebhookAPI) CreateWebhook(c *gin.Context) {
+	var req CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, errcode.InvalidParam, validator.GetErrorMsg(err))
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	hook, err := a.webhookService.CreateWebhook(userID.(uint), req.URL, req.Events, req.TagFilter)
+	if err != nil {
+		if err.Error() == errcode.GetMsg(errcode.ServerError) {
+			response.Error(c, errcode.ServerError, err.Error())
+		} else {
+			response.Error(c, errcode.InvalidParam, err.Error())
+		}
+		return
+	}
+
+	response.Success(c, gin.H{"webhook": hook, "secret": hook.Secret})
+}
+
+// GetWebhookList Webhook 列表接口
+func (a *WebhookAPI) GetWebhookList(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	hooks, err := a.webhookService.GetWebhookList(userID.(uint))
+	if err != nil {
+		response.Error(c, errcode.ServerError, err.Error())
+		return
+	}
+
+	response.Success(c, hooks)
+}
+
+// SetWebhookEnabled 启用/停用 Webhook 接口
+func (a *WebhookAPI) SetWebhookEnabled(c *gin.Context) {
+	var req SetWebhookEnabledRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, errcode.InvalidParam, validator.GetErrorMsg(err))
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	err := a.webhookService.SetWebhookEnabled(userID.(uint), req.WebhookID, *req.Enabled)
+	if err != nil {
+		if err.Error() == errcode.GetMsg(errcode.NotFound) {
+			response.ErrorWithDefaultMsg(c, errcode.NotFound)
+		} else {
+			response.Error(c, errcode.ServerError, err.Error())
+		}
+		return
+	}
+
+	response.SuccessWithoutData(c)
+}
+
+// DeleteWebhook 删除 Webhook 接口
+func (a *WebhookAPI) DeleteWebhook(c *gin.Context) {
+	webhookID, err := strconv.ParseUint(c.Query("webhook_id"), 10, 32)
+	if err != nil {
+		response.Error(c, errcode.InvalidParam, "Webhook ID格式错误")
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	err = a.webhookService.DeleteWebhook(userID.(uint), uint(webhookID))
+	if err != nil {
+		if err.Error() == errcode.GetMsg(errcode.NotFound) {
+			response.ErrorWithDefaultMsg(c, errcode.NotFound)
+		} else {
+			response.Error(c, errcode.ServerError, err.Error())
+		}
+		return
+	}
+
+	response.SuccessWithoutData(c)
+}
+
+// PingWebhook 发送测试推送接口
+func (a *WebhookAPI) PingWebhook(c *gin.Context) {
+	webhookID, err := strconv.ParseUint(c.Query("webhook_id"), 10, 32)
+	if err != nil {
+		response.Error(c, errcode.InvalidParam, "Webhook ID格式错误")
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	err = a.webhookService.PingWebhook(userID.(uint), uint(webhookID))
+	if err != nil {
+		if err.Error() == errcode.GetMsg(errcode.NotFound) {
+			response.ErrorWithDefaultMsg(c, errcode.NotFound)
+		} else {
+			response.Error(c, errcode.ServerError, err.Error())
+		}
+		return
+	}
+
+	response.SuccessWithoutData(c)
+}
+
+// GetDeliveryList 投递记录接口
+func (a *WebhookAPI) GetDeliveryList(c *gin.Context) {
+	var req DeliveryListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.Error(c, errcode.InvalidParam, validator.GetErrorMsg(err))
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	deliveries, total, err := a.webhookService.GetDeliveryList(userID.(uint), req.WebhookID, req.Page, req.PageSize)
+	if err != nil {
+		response.Error(c, errcode.ServerError, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"list":      deliveries,
+		"total":     total,
+		"page":      req.Page,
+		"page_size": req.PageSize,
+	})
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/JokerYuan-lang/MyNoteBook/internal/config"
 	"github.com/JokerYuan-lang/MyNoteBook/pkg/db"
 	"github.com/JokerYuan-lang/MyNoteBook/pkg/redis"
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/storage"
 	"github.com/JokerYuan-lang/MyNoteBook/router"
 	"github.com/spf13/viper"
 	"go.uber.org/zap"
@@ -44,10 +45,17 @@ func main() {
 		os.Exit(1)
 	}
 
-	// 5. 初始化路由
-	r := router.InitRouter(mysqlDB, redisClient, globalConf.Jwt, globalConf.Debug)
+	// 5. 初始化附件存储
+	fileStorage, err := storage.NewLocalStorage(globalConf.Storage.Dir)
+	if err != nil {
+		zap.S().Fatalf("附件存储初始化失败: %v", err)
+		os.Exit(1)
+	}
+
+	// 6. 初始化路由
+	r := router.InitRouter(mysqlDB, redisClient, fileStorage, globalConf)
 
-	// 6. 启动服务
+	// 7. 启动服务
 	zap.S().Infof("服务启动成功，监听端口: %d", globalConf.Port)
 	if err := r.Run(fmt.Sprintf(":%d", globalConf.Port)); err != nil {
 		zap.S().Fatalf("服务启动失败: %v", err)
@@ -20,10 +20,52 @@ type JwtConfig struct {
 	Expire int    `mapstructure:"expire"` //过期时间
 }
 
+type SmtpConfig struct {
+	Enable   bool   `mapstructure:"enable"`    // 是否开启邮件通知
+	Host     string `mapstructure:"host"`      // SMTP 服务器地址
+	Port     int    `mapstructure:"port"`      // SMTP 端口（465 走 TLS）
+	UserName string `mapstructure:"user_name"` // 登录账号
+	Password string `mapstructure:"password"`  // 密码/授权码
+	From     string `mapstructure:"from"`      // 发件人
+}
+
+type StorageConfig struct {
+	Dir string `mapstructure:"dir"` // 附件存储目录
+}
+
+type RenderConfig struct {
+	PdfFont string `mapstructure:"pdf_font"` // PDF 导出使用的 TrueType 字体文件（输出中文必须配置）
+}
+
+type SearchConfig struct {
+	Tokenizer string `mapstructure:"tokenizer"` // 分词器（mixed：中英文混合，默认；whitespace：按空白切分）
+	DictPath  string `mapstructure:"dict_path"` // 自定义中文词典（每行「词语 词频」），追加到内置词典
+}
+
+type EmbeddingConfig struct {
+	Provider     string `mapstructure:"provider"`      // 向量提供方（local：本地特征哈希，默认；http：OpenAI 兼容的 embeddings 接口）
+	URL          string `mapstructure:"url"`           // http：接口地址
+	APIKey       string `mapstructure:"api_key"`       // http：接口密钥
+	Model        string `mapstructure:"model"`         // http：模型名
+	Dimension    int    `mapstructure:"dimension"`     // local：向量维度（默认 256）
+	Timeout      int    `mapstructure:"timeout"`       // http：请求超时（秒，默认 30）
+	CacheVectors int    `mapstructure:"cache_vectors"` // 内存中最多缓存的笔记向量数（默认 100000，超出时淘汰最久未使用的用户）
+}
+
+type WebhookConfig struct {
+	AllowPrivateHosts []string `mapstructure:"allow_private_hosts"` // 允许推送的内网主机（主机名、IP 或 CIDR 网段；默认只能推送到公网地址）
+}
+
 type Config struct {
-	Port  int         `mapstructure:"port"`
-	Debug bool        `mapstructure:"debug"` // 是否调试模式
-	Mysql MysqlConfig `mapstructure:"mysql"`
-	Redis RedisConfig `mapstructure:"redis"`
-	Jwt   JwtConfig   `mapstructure:"jwt"`
+	Port      int             `mapstructure:"port"`
+	Debug     bool            `mapstructure:"debug"` // 是否调试模式
+	Mysql     MysqlConfig     `mapstructure:"mysql"`
+	Redis     RedisConfig     `mapstructure:"redis"`
+	Jwt       JwtConfig       `mapstructure:"jwt"`
+	Smtp      SmtpConfig      `mapstructure:"smtp"`
+	Storage   StorageConfig   `mapstructure:"storage"`
+	Render    RenderConfig    `mapstructure:"render"`
+	Search    SearchConfig    `mapstructure:"search"`
+	Embedding EmbeddingConfig `mapstructure:"embedding"`
+	Webhook   WebhookConfig   `mapstructure:"webhook"`
 }
@@ -0,0 +1,274 @@
+package exporter
+
+import (
+	"archive/zip"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"path"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/JokerYuan-lang/MyNoteBook/internal/model"
+)
+
+// Book 电子书信息
+type Book struct {
+	Title    string
+	Author   string
+	Language string // 如 zh-CN
+}
+
+// epubChapter 电子书中的一章（一条笔记）
+type epubChapter struct {
+	ID      string
+	File    string
+	Title   string
+	Content string
+}
+
+// epubAsset 电子书中的附件文件
+type epubAsset struct {
+	ID        string
+	File      string
+	MediaType string
+}
+
+// WriteEPUB 把笔记按给定顺序导出为 EPUB 3 电子书（每条笔记一章，附件一并打包）
+func WriteEPUB(w io.Writer, book Book, notes []Note, load AssetLoader) error {
+	// 1. 渲染各章节，改写笔记间链接和附件链接
+	inBook := make(map[string]string, len(notes)) // 笔记ID -> 章节文件
+	for _, note := range notes {
+		inBook[strconv.FormatUint(uint64(note.ID), 10)] = fmt.Sprintf("note-%d.xhtml", note.ID)
+	}
+	assets := newAssetSet(load, "files")
+	rewrite := func(url string) string {
+		if id := strings.TrimPrefix(url, model.NoteLinkScheme); id != url {
+			return inBook[id]
+		}
+		return assets.file(strings.TrimPrefix(url, model.AttachmentScheme))
+	}
+
+	chapters := make([]epubChapter, 0, len(notes))
+	for _, note := range notes {
+		content, _, err := renderNote(note, rewrite)
+		if err != nil {
+			return err
+		}
+		chapters = append(chapters, epubChapter{
+			ID:      fmt.Sprintf("note-%d", note.ID),
+			File:    fmt.Sprintf("note-%d.xhtml", note.ID),
+			Title:   note.Title,
+			Content: content,
+		})
+	}
+
+	// 2. 写入压缩包：mimetype 必须是第一个文件且不压缩
+	zw := zip.NewWriter(w)
+	fw, err := zw.CreateHeader(&zip.FileHeader{Name: "mimetype", Method: zip.Store})
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(fw, "application/epub+zip"); err != nil {
+		return err
+	}
+	if err := writeZipFile(zw, "META-INF/container.xml", []byte(epubContainer)); err != nil {
+		return err
+	}
+	if err := writeZipFile(zw, "OEBPS/style.css", []byte(epubStyle)); err != nil {
+		return err
+	}
+
+	for _, chapter := range chapters {
+		if err := writeTemplate(zw, "OEBPS/"+chapter.File, epubChapterTemplate, map[string]interface{}{
+			"Book":    book,
+			"Chapter": chapter,
+		}); err != nil {
+			return err
+		}
+	}
+
+	var files []epubAsset
+	for i, asset := range assets.list {
+		file := assets.files[asset.Hash]
+		if err := writeZipFile(zw, "OEBPS/"+file, asset.Data); err != nil {
+			return err
+		}
+		files = append(files, epubAsset{
+			ID:        fmt.Sprintf("file-%d", i+1),
+			File:      file,
+			MediaType: mediaType(asset),
+		})
+	}
+
+	// 3. 目录和包描述文件
+	data := map[string]interface{}{
+		"Book":       book,
+		"Identifier": bookIdentifier(book, notes),
+		"Modified":   time.Now().UTC().Format("2006-01-02T15:04:05Z"),
+		"Chapters":   chapters,
+		"Assets":     files,
+	}
+	for name, tmpl := range map[string]*template.Template{
+		"OEBPS/nav.xhtml":   epubNavTemplate,
+		"OEBPS/toc.ncx":     epubNCXTemplate,
+		"OEBPS/content.opf": epubOPFTemplate,
+	} {
+		if err := writeTemplate(zw, name, tmpl, data); err != nil {
+			return err
+		}
+	}
+
+	return zw.Close()
+}
+
+// bookIdentifier 由书名和笔记ID生成稳定的书籍标识（同一组笔记重复导出时标识不变）
+func bookIdentifier(book Book, notes []Note) string {
+	h := md5.New()
+	io.WriteString(h, book.Title)
+	for _, note := range notes {
+		fmt.Fprintf(h, ",%d", note.ID)
+	}
+	return "urn:mynotebook:" + hex.EncodeToString(h.Sum(nil))
+}
+
+// mediaType 附件的 MIME 类型（未记录时按扩展名推断）
+func mediaType(asset *Asset) string {
+	if asset.MimeType != "" {
+		return asset.MimeType
+	}
+	if t := mime.TypeByExtension(strings.ToLower(path.Ext(asset.FileName))); t != "" {
+		return strings.Split(t, ";")[0]
+	}
+	return "application/octet-stream"
+}
+
+// writeZipFile 向压缩包写入一个文件
+func writeZipFile(zw *zip.Writer, name string, data []byte) error {
+	fw, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: time.Now()})
+	if err != nil {
+		return err
+	}
+	_, err = fw.Write(data)
+	return err
+}
+
+// writeTemplate 渲染模板并写入压缩包
+func writeTemplate(zw *zip.Writer, name string, tmpl *template.Template, data interface{}) error {
+	fw, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: time.Now()})
+	if err != nil {
+		return err
+	}
+	return tmpl.Execute(fw, data)
+}
+
+// xmlTemplate 解析 XML 模板（x 函数转义文本，inc 用于从 1 开始编号）
+func xmlTemplate(name, text string) *template.Template {
+	return template.Must(template.New(name).Funcs(template.FuncMap{
+		"x":   html.EscapeString,
+		"inc": func(i int) int { return i + 1 },
+	}).Parse(text))
+}
+
+const epubContainer = `<?xml version="1.0" encoding="UTF-8"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+  <rootfiles>
+    <rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/>
+  </rootfiles>
+</container>
+`
+
+const epubStyle = `body { font-family: sans-serif; line-height: 1.6; }
+h1 { font-size: 1.6em; border-bottom: 1px solid #ddd; padding-bottom: .3em; }
+pre { padding: .6em; overflow-x: auto; white-space: pre-wrap; font-size: .85em; }
+code { font-family: monospace; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: .3em .6em; }
+blockquote { margin-left: 0; padding-left: 1em; border-left: 3px solid #ddd; color: #555; }
+img { max-width: 100%; }
+`
+
+var epubChapterTemplate = xmlTemplate("chapter", `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops" xml:lang="{{x .Book.Language}}" lang="{{x .Book.Language}}">
+<head>
+  <meta charset="UTF-8"/>
+  <title>{{x .Chapter.Title}}</title>
+  <link rel="stylesheet" type="text/css" href="style.css"/>
+</head>
+<body>
+  <h1>{{x .Chapter.Title}}</h1>
+  {{.Chapter.Content}}
+</body>
+</html>
+`)
+
+var epubNavTemplate = xmlTemplate("nav", `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops" xml:lang="{{x .Book.Language}}" lang="{{x .Book.Language}}">
+<head>
+  <meta charset="UTF-8"/>
+  <title>{{x .Book.Title}}</title>
+</head>
+<body>
+  <nav epub:type="toc" id="toc">
+    <h1>目录</h1>
+    <ol>
+{{- range .Chapters}}
+      <li><a href="{{.File}}">{{x .Title}}</a></li>
+{{- end}}
+    </ol>
+  </nav>
+</body>
+</html>
+`)
+
+var epubNCXTemplate = xmlTemplate("ncx", `<?xml version="1.0" encoding="UTF-8"?>
+<ncx xmlns="http://www.daisy.org/z3986/2005/ncx/" version="2005-1">
+  <head>
+    <meta name="dtb:uid" content="{{x .Identifier}}"/>
+  </head>
+  <docTitle><text>{{x .Book.Title}}</text></docTitle>
+  <navMap>
+{{- range $i, $c := .Chapters}}
+    <navPoint id="nav-{{$c.ID}}" playOrder="{{inc $i}}">
+      <navLabel><text>{{x $c.Title}}</text></navLabel>
+      <content src="{{$c.File}}"/>
+    </navPoint>
+{{- end}}
+  </navMap>
+</ncx>
+`)
+
+var epubOPFTemplate = xmlTemplate("opf", `<?xml version="1.0" encoding="UTF-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="book-id" xml:lang="{{x .Book.Language}}">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:identifier id="book-id">{{x .Identifier}}</dc:identifier>
+    <dc:title>{{x .Book.Title}}</dc:title>
+    <dc:creator>{{x .Book.Author}}</dc:creator>
+    <dc:language>{{x .Book.Language}}</dc:language>
+    <meta property="dcterms:modified">{{.Modified}}</meta>
+  </metadata>
+  <manifest>
+    <item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>
+    <item id="ncx" href="toc.ncx" media-type="application/x-dtbncx+xml"/>
+    <item id="style" href="style.css" media-type="text/css"/>
+{{- range .Chapters}}
+    <item id="{{.ID}}" href="{{.File}}" media-type="application/xhtml+xml"/>
+{{- end}}
+{{- range .Assets}}
+    <item id="{{.ID}}" href="{{x .File}}" media-type="{{x .MediaType}}"/>
+{{- end}}
+  </manifest>
+  <spine toc="ncx">
+{{- range .Chapters}}
+    <itemref idref="{{.ID}}"/>
+{{- end}}
+  </spine>
+</package>
+`)
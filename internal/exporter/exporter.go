@@ -0,0 +1,121 @@
+// Package exporter 把一组笔记导出为电子书或静态网站
+package exporter
+
+import (
+	"bytes"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/JokerYuan-lang/MyNoteBook/internal/model"
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/render"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+const searchTextLimit = 5000 // 搜索索引中每条笔记保留的正文字数
+
+// Note 待导出的笔记
+type Note struct {
+	ID            uint
+	Title         string
+	Category      string
+	Tags          []string
+	ContentFormat string // plain/markdown/html
+	Content       string
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// Asset 笔记引用的附件
+type Asset struct {
+	Hash     string
+	FileName string
+	MimeType string
+	Data     []byte
+}
+
+// AssetLoader 按哈希读取附件（ok 为 false 表示附件不存在或无权访问）
+type AssetLoader func(hash string) (asset *Asset, ok bool)
+
+// assetSet 导出过程中用到的附件（按需加载，每个只加载一次）
+type assetSet struct {
+	load  AssetLoader
+	dir   string            // 附件在导出包中的目录
+	files map[string]string // 哈希 -> 导出包中的文件路径（空表示加载失败）
+	list  []*Asset
+}
+
+func newAssetSet(load AssetLoader, dir string) *assetSet {
+	return &assetSet{load: load, dir: dir, files: make(map[string]string)}
+}
+
+// file 返回附件在导出包中的文件路径（附件不存在时返回空）
+func (s *assetSet) file(hash string) string {
+	if name, ok := s.files[hash]; ok {
+		return name
+	}
+	name := ""
+	if asset, ok := s.load(hash); ok {
+		name = path.Join(s.dir, hash+strings.ToLower(path.Ext(asset.FileName)))
+		s.list = append(s.list, asset)
+	}
+	s.files[hash] = name
+	return name
+}
+
+// renderNote 按内容格式把笔记渲染为 HTML，并改写其中的站内链接
+// rewrite 返回新的链接地址，返回空表示去掉链接；同时返回正文纯文本（用于搜索索引）
+func renderNote(note Note, rewrite func(url string) string) (string, string, error) {
+	fragment, err := render.Content(note.ContentFormat, note.Content)
+	if err != nil {
+		return "", "", err
+	}
+	nodes, err := html.ParseFragment(strings.NewReader(fragment), &html.Node{
+		Type:     html.ElementNode,
+		Data:     "div",
+		DataAtom: atom.Div,
+	})
+	if err != nil {
+		return "", "", err
+	}
+
+	var buf bytes.Buffer
+	for _, n := range nodes {
+		rewriteLinks(n, rewrite)
+		// 渲染结果中的空元素自闭合（<br/>），同时满足 EPUB 的 XHTML 要求
+		if err := html.Render(&buf, n); err != nil {
+			return "", "", err
+		}
+	}
+	return buf.String(), truncate(render.PlainText(fragment), searchTextLimit), nil
+}
+
+// rewriteLinks 改写 note:// 和 attachment:// 链接
+func rewriteLinks(n *html.Node, rewrite func(url string) string) {
+	if n.Type == html.ElementNode {
+		attrs := n.Attr[:0]
+		for _, attr := range n.Attr {
+			if (attr.Key == "href" || attr.Key == "src") &&
+				(strings.HasPrefix(attr.Val, model.NoteLinkScheme) || strings.HasPrefix(attr.Val, model.AttachmentScheme)) {
+				attr.Val = rewrite(attr.Val)
+				if attr.Val == "" {
+					continue
+				}
+			}
+			attrs = append(attrs, attr)
+		}
+		n.Attr = attrs
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		rewriteLinks(c, rewrite)
+	}
+}
+
+// truncate 按字符数截断
+func truncate(s string, limit int) string {
+	if runes := []rune(s); len(runes) > limit {
+		return string(runes[:limit])
+	}
+	return s
+}
@@ -0,0 +1,291 @@
+package exporter
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/JokerYuan-lang/MyNoteBook/internal/model"
+)
+
+// Site 静态网站信息
+type Site struct {
+	Title string
+}
+
+// sitePage 网站中的一篇笔记页面
+type sitePage struct {
+	Note    Note
+	File    string // 相对网站根目录的路径
+	Content template.HTML
+	Text    string
+	Tags    []siteTag
+	Prev    *sitePage
+	Next    *sitePage
+}
+
+// siteTag 标签索引页
+type siteTag struct {
+	Name  string
+	File  string
+	Pages []*sitePage
+}
+
+// searchEntry 搜索索引中的一条记录
+type searchEntry struct {
+	Title string   `json:"title"`
+	URL   string   `json:"url"`
+	Tags  []string `json:"tags"`
+	Text  string   `json:"text"`
+}
+
+// WriteSite 把笔记按给定顺序导出为可离线浏览的静态网站（ZIP）
+// 包含首页导航、每条笔记一页、标签索引页和搜索索引 search-index.json
+func WriteSite(w io.Writer, site Site, notes []Note, load AssetLoader) error {
+	// 1. 渲染笔记页面（页面都在 notes/ 下，链接使用相对路径）
+	inSite := make(map[string]string, len(notes)) // 笔记ID -> 页面文件名
+	for _, note := range notes {
+		inSite[strconv.FormatUint(uint64(note.ID), 10)] = fmt.Sprintf("%d.html", note.ID)
+	}
+	assets := newAssetSet(load, "files")
+	rewrite := func(url string) string {
+		if id := strings.TrimPrefix(url, model.NoteLinkScheme); id != url {
+			return inSite[id]
+		}
+		if file := assets.file(strings.TrimPrefix(url, model.AttachmentScheme)); file != "" {
+			return "../" + file
+		}
+		return ""
+	}
+
+	pages := make([]*sitePage, 0, len(notes))
+	for _, note := range notes {
+		content, text, err := renderNote(note, rewrite)
+		if err != nil {
+			return err
+		}
+		page := &sitePage{
+			Note:    note,
+			File:    "notes/" + inSite[strconv.FormatUint(uint64(note.ID), 10)],
+			Content: template.HTML(content), // 已经过白名单过滤
+			Text:    text,
+		}
+		if len(pages) > 0 {
+			page.Prev = pages[len(pages)-1]
+			page.Prev.Next = page
+		}
+		pages = append(pages, page)
+	}
+
+	// 2. 标签索引（按名称排序）
+	tagPages := make(map[string]*siteTag)
+	for _, page := range pages {
+		for _, name := range page.Note.Tags {
+			if tagPages[name] == nil {
+				tagPages[name] = &siteTag{Name: name}
+			}
+			tagPages[name].Pages = append(tagPages[name].Pages, page)
+		}
+	}
+	tags := make([]*siteTag, 0, len(tagPages))
+	for _, tag := range tagPages {
+		tags = append(tags, tag)
+	}
+	sort.Slice(tags, func(i, j int) bool { return tags[i].Name < tags[j].Name })
+	for i, tag := range tags {
+		tag.File = fmt.Sprintf("tags/%d.html", i+1)
+	}
+	for _, page := range pages {
+		for _, name := range page.Note.Tags {
+			page.Tags = append(page.Tags, *tagPages[name])
+		}
+	}
+
+	// 3. 写入页面
+	zw := zip.NewWriter(w)
+	data := func(root string, extra map[string]interface{}) map[string]interface{} {
+		extra["Site"] = site
+		extra["Root"] = root
+		extra["Pages"] = pages
+		extra["Tags"] = tags
+		return extra
+	}
+	if err := writeHTML(zw, "index.html", siteIndexTemplate, data("", map[string]interface{}{})); err != nil {
+		return err
+	}
+	for _, page := range pages {
+		if err := writeHTML(zw, page.File, siteNoteTemplate, data("../", map[string]interface{}{"Page": page})); err != nil {
+			return err
+		}
+	}
+	if err := writeHTML(zw, "tags/index.html", siteTagsTemplate, data("../", map[string]interface{}{})); err != nil {
+		return err
+	}
+	for _, tag := range tags {
+		if err := writeHTML(zw, tag.File, siteTagTemplate, data("../", map[string]interface{}{"Tag": tag})); err != nil {
+			return err
+		}
+	}
+
+	// 4. 搜索索引、样式脚本和附件
+	entries := make([]searchEntry, 0, len(pages))
+	for _, page := range pages {
+		entries = append(entries, searchEntry{Title: page.Note.Title, URL: page.File, Tags: page.Note.Tags, Text: page.Text})
+	}
+	index, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+	for name, content := range map[string][]byte{
+		"search-index.json": index,
+		"assets/style.css":  []byte(siteStyle),
+		"assets/search.js":  []byte(siteSearchScript),
+	} {
+		if err := writeZipFile(zw, name, content); err != nil {
+			return err
+		}
+	}
+	for _, asset := range assets.list {
+		if err := writeZipFile(zw, assets.files[asset.Hash], asset.Data); err != nil {
+			return err
+		}
+	}
+
+	return zw.Close()
+}
+
+// writeHTML 渲染页面模板并写入压缩包
+func writeHTML(zw *zip.Writer, name string, tmpl *template.Template, data interface{}) error {
+	fw, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	return tmpl.ExecuteTemplate(fw, "page", data)
+}
+
+// siteTemplate 解析页面模板（与公共布局组合）
+func siteTemplate(body string) *template.Template {
+	return template.Must(template.Must(template.New("layout").Parse(siteLayout)).New("body").Parse(body))
+}
+
+const siteLayout = `{{define "page"}}<!DOCTYPE html>
+<html lang="zh-CN">
+<head>
+  <meta charset="UTF-8">
+  <meta name="viewport" content="width=device-width, initial-scale=1">
+  <title>{{if .Page}}{{.Page.Note.Title}} - {{end}}{{.Site.Title}}</title>
+  <link rel="stylesheet" href="{{.Root}}assets/style.css">
+</head>
+<body data-root="{{.Root}}">
+  <nav class="sidebar">
+    <h2><a href="{{.Root}}index.html">{{.Site.Title}}</a></h2>
+    <input id="search" type="search" placeholder="搜索…" autocomplete="off">
+    <ul id="search-results"></ul>
+    <ol class="toc">
+      {{- $root := .Root}}{{$current := .Page}}
+      {{- range .Pages}}
+      <li{{if eq . $current}} class="current"{{end}}><a href="{{$root}}{{.File}}">{{.Note.Title}}</a></li>
+      {{- end}}
+    </ol>
+    <p><a href="{{.Root}}tags/index.html">全部标签</a></p>
+  </nav>
+  <main>{{template "body" .}}</main>
+  <script src="{{.Root}}assets/search.js"></script>
+</body>
+</html>
+{{end}}`
+
+var siteIndexTemplate = siteTemplate(`
+<h1>{{.Site.Title}}</h1>
+<p>共 {{len .Pages}} 篇笔记</p>
+<ol>
+  {{- range .Pages}}
+  <li><a href="{{.File}}">{{.Note.Title}}</a> <small>{{.Note.UpdatedAt.Format "2006-01-02"}}</small></li>
+  {{- end}}
+</ol>`)
+
+var siteNoteTemplate = siteTemplate(`
+{{- $root := .Root}}
+<article>
+  <h1>{{.Page.Note.Title}}</h1>
+  <p class="meta">更新于 {{.Page.Note.UpdatedAt.Format "2006-01-02 15:04"}}
+    {{- range .Page.Tags}} <a class="tag" href="{{$root}}{{.File}}">#{{.Name}}</a>{{end}}</p>
+  {{.Page.Content}}
+</article>
+<p class="pager">
+  {{- with .Page.Prev}}<a href="{{$root}}{{.File}}">← {{.Note.Title}}</a>{{end}}
+  {{- with .Page.Next}}<a class="next" href="{{$root}}{{.File}}">{{.Note.Title}} →</a>{{end}}
+</p>`)
+
+var siteTagsTemplate = siteTemplate(`
+{{- $root := .Root}}
+<h1>全部标签</h1>
+<ul class="tags">
+  {{- range .Tags}}
+  <li><a href="{{$root}}{{.File}}">#{{.Name}}</a> ({{len .Pages}})</li>
+  {{- end}}
+</ul>`)
+
+var siteTagTemplate = siteTemplate(`
+{{- $root := .Root}}
+<h1>#{{.Tag.Name}}</h1>
+<ol>
+  {{- range .Tag.Pages}}
+  <li><a href="{{$root}}{{.File}}">{{.Note.Title}}</a></li>
+  {{- end}}
+</ol>`)
+
+const siteStyle = `body { margin: 0; display: flex; font-family: -apple-system, "PingFang SC", "Microsoft YaHei", sans-serif; line-height: 1.7; color: #222; }
+.sidebar { width: 260px; min-height: 100vh; padding: 1em; box-sizing: border-box; background: #f6f7f9; border-right: 1px solid #e5e5e5; }
+.sidebar h2 a { color: inherit; text-decoration: none; }
+.sidebar input { width: 100%; padding: .4em; box-sizing: border-box; }
+.sidebar .current a { font-weight: bold; }
+main { flex: 1; max-width: 860px; padding: 1em 2em; }
+a { color: #1e5ac8; }
+.meta { color: #888; font-size: .9em; }
+.tag { margin-left: .5em; }
+.pager { display: flex; justify-content: space-between; margin-top: 3em; }
+pre { padding: .8em; overflow-x: auto; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: .3em .6em; }
+blockquote { margin-left: 0; padding-left: 1em; border-left: 3px solid #ddd; color: #555; }
+img { max-width: 100%; }
+`
+
+// siteSearchScript 读取 search-index.json 在标题、标签和正文中搜索（需通过 HTTP 访问站点）
+const siteSearchScript = `(function () {
+  var root = document.body.getAttribute('data-root') || '';
+  var input = document.getElementById('search');
+  var results = document.getElementById('search-results');
+  var index = null;
+  function show(query) {
+    results.innerHTML = '';
+    query = query.trim().toLowerCase();
+    if (!query || !index) return;
+    index.filter(function (e) {
+      return e.title.toLowerCase().indexOf(query) >= 0 ||
+        e.text.toLowerCase().indexOf(query) >= 0 ||
+        (e.tags || []).some(function (t) { return t.toLowerCase() === query; });
+    }).slice(0, 20).forEach(function (e) {
+      var li = document.createElement('li');
+      var a = document.createElement('a');
+      a.href = root + e.url;
+      a.textContent = e.title;
+      li.appendChild(a);
+      results.appendChild(li);
+    });
+  }
+  input.addEventListener('input', function () {
+    if (index) return show(input.value);
+    fetch(root + 'search-index.json').then(function (r) { return r.json(); }).then(function (data) {
+      index = data;
+      show(input.value);
+    });
+  });
+})();
+`
@@ -0,0 +1,199 @@
+package importer
+
+import (
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"regexp"
+	"strings"
+	"time"
+
+	md "github.com/JohannesKaufmann/html-to-markdown"
+	"github.com/JohannesKaufmann/html-to-markdown/plugin"
+	"github.com/JokerYuan-lang/MyNoteBook/internal/model"
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/storage"
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html/charset"
+)
+
+const enexTimeLayout = "20060102T150405Z" // ENEX 中的时间格式（UTC）
+
+// 匹配 ENML 中自闭合的 en-* 标签，如 <en-todo checked="true"/>
+var selfClosingRegexp = regexp.MustCompile(`<(en-[a-z]+)([^>]*?)\s*/>`)
+
+// enexNote ENEX 中的 <note> 元素
+type enexNote struct {
+	Title     string         `xml:"title"`
+	Content   string         `xml:"content"`
+	Created   string         `xml:"created"`
+	Updated   string         `xml:"updated"`
+	Tags      []string       `xml:"tag"`
+	Resources []enexResource `xml:"resource"`
+}
+
+// enexResource ENEX 中的 <resource> 元素（内嵌的图片、文件）
+type enexResource struct {
+	Data struct {
+		Encoding string `xml:"encoding,attr"`
+		Value    string `xml:",chardata"`
+	} `xml:"data"`
+	Mime     string `xml:"mime"`
+	FileName string `xml:"resource-attributes>file-name"`
+}
+
+// ParseENEX 流式解析 Evernote 导出的 .enex 文件，每解析出一条笔记调用一次 fn
+// 笔记逐条解码，内存占用只与单条笔记大小有关；category 为导入后的分类（一般取笔记本名）
+func ParseENEX(r io.Reader, category string, fn func(note *Note, err error)) error {
+	decoder := xml.NewDecoder(r)
+	decoder.CharsetReader = charset.NewReaderLabel
+	decoder.Strict = false // 兼容 DOCTYPE 声明和不规范的实体
+
+	index := 0
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("ENEX 格式错误: %v", err)
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "note" {
+			continue
+		}
+
+		index++
+		var en enexNote
+		if err := decoder.DecodeElement(&en, &start); err != nil {
+			return fmt.Errorf("第 %d 条笔记格式错误: %v", index, err)
+		}
+		note, err := convertENEXNote(&en, category)
+		if err != nil {
+			fn(&Note{Title: en.Title}, err)
+			continue
+		}
+		fn(note, nil)
+	}
+}
+
+// convertENEXNote 把 ENEX 笔记转换为待导入笔记
+func convertENEXNote(en *enexNote, category string) (*Note, error) {
+	note := &Note{
+		Title:    en.Title,
+		Category: category,
+		Tags:     en.Tags,
+	}
+	note.CreatedAt, _ = time.Parse(enexTimeLayout, strings.TrimSpace(en.Created))
+	note.UpdatedAt, _ = time.Parse(enexTimeLayout, strings.TrimSpace(en.Updated))
+
+	// 1. 解码附件，记录 Evernote 的 MD5 哈希到附件的映射（正文中 en-media 通过 MD5 引用）
+	resources := make(map[string]Attachment)
+	for i, res := range en.Resources {
+		if !strings.EqualFold(strings.TrimSpace(res.Data.Encoding), "base64") {
+			continue
+		}
+		data, err := base64.StdEncoding.DecodeString(stripSpaces(res.Data.Value))
+		if err != nil {
+			return nil, fmt.Errorf("附件解码失败: %v", err)
+		}
+		fileName := strings.TrimSpace(res.FileName)
+		if fileName == "" {
+			fileName = fmt.Sprintf("resource-%d%s", i+1, extensionByMime(res.Mime))
+		}
+		att := Attachment{
+			FileName: fileName,
+			MimeType: res.Mime,
+			Data:     data,
+		}
+		note.Attachments = append(note.Attachments, att)
+		resources[md5Hex(data)] = att
+	}
+
+	// 2. ENML 转 Markdown
+	content, err := enmlToMarkdown(en.Content, resources)
+	if err != nil {
+		return nil, err
+	}
+	note.Content = content
+	note.Normalize("")
+	return note, nil
+}
+
+// enmlToMarkdown 把 ENML（Evernote 的 XHTML 方言）转换为 Markdown
+func enmlToMarkdown(enml string, resources map[string]Attachment) (string, error) {
+	converter := md.NewConverter("", true, nil)
+	converter.Use(plugin.GitHubFlavored())
+	converter.AddRules(
+		// <en-media hash="..."/>：替换为附件引用
+		md.Rule{
+			Filter: []string{"en-media"},
+			Replacement: func(_ string, sel *goquery.Selection, _ *md.Options) *string {
+				att, ok := resources[strings.ToLower(sel.AttrOr("hash", ""))]
+				if !ok {
+					return md.String("")
+				}
+				link := model.AttachmentScheme + storage.Hash(att.Data)
+				if strings.HasPrefix(att.MimeType, "image/") {
+					return md.String(fmt.Sprintf("![%s](%s)", att.FileName, link))
+				}
+				return md.String(fmt.Sprintf("[%s](%s)", att.FileName, link))
+			},
+		},
+		// <en-todo checked="true"/>：替换为任务列表标记
+		md.Rule{
+			Filter: []string{"en-todo"},
+			Replacement: func(_ string, sel *goquery.Selection, _ *md.Options) *string {
+				mark := "[ ] "
+				if sel.AttrOr("checked", "") == "true" {
+					mark = "[x] "
+				}
+				// 不在列表项中时补上列表符号，成为 GFM 任务列表
+				if !sel.Parent().Is("li") {
+					mark = "- " + mark
+				}
+				return md.String(mark)
+			},
+		},
+		// <en-crypt>：加密内容无法转换
+		md.Rule{
+			Filter: []string{"en-crypt"},
+			Replacement: func(_ string, _ *goquery.Selection, _ *md.Options) *string {
+				return md.String("[加密内容]")
+			},
+		},
+	)
+
+	// 去掉 XML 声明和 DOCTYPE，只保留 <en-note> 内部
+	if i := strings.Index(enml, "<en-note"); i >= 0 {
+		enml = enml[i:]
+	}
+	// HTML 解析器不认自闭合的自定义标签，需展开，否则后面的内容会被吞进标签内部
+	enml = selfClosingRegexp.ReplaceAllString(enml, "<$1$2></$1>")
+	markdown, err := converter.ConvertString(enml)
+	if err != nil {
+		return "", fmt.Errorf("笔记内容转换失败: %v", err)
+	}
+	return markdown, nil
+}
+
+// stripSpaces 去掉 base64 内容中的换行和空白
+func stripSpaces(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '\n', '\r', '\t':
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// extensionByMime 根据 MIME 类型推断扩展名
+func extensionByMime(mimeType string) string {
+	if exts, _ := mime.ExtensionsByType(mimeType); len(exts) > 0 {
+		return exts[0]
+	}
+	return ""
+}
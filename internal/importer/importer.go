@@ -0,0 +1,231 @@
+package importer
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+const (
+	maxTitleLen     = 100       // 与 Note.Title 字段长度一致
+	maxCategoryLen  = 255       // 与 Note.Category 字段长度一致（笔记本完整路径）
+	maxFileSize     = 10 << 20  // 单个笔记文件解压后最大 10MB
+	maxResourceSize = 100 << 20 // 附件和内层压缩包解压后最大 100MB
+)
+
+// ErrTooLarge 文件超过大小上限
+var ErrTooLarge = errors.New("文件超过大小上限")
+
+// Note 待导入的笔记（各种来源解析后的统一结构）
+type Note struct {
+	SourceID  string // 在来源中的唯一标识（用于改写笔记间链接，可为空）
+	Title     string
+	Content   string
+	Category  string
+	Tags      []string
+	CreatedAt time.Time // 零值表示使用导入时间
+	UpdatedAt time.Time
+
+	Attachments []Attachment // 附件（内容中以 attachment://<Hash> 引用）
+	Warnings    []string     // 不影响导入的问题（如跳过的超大附件）
+}
+
+// Result 单个来源文件的解析结果（Err 不为空时解析失败）
+type Result struct {
+	File string
+	Note *Note
+	Err  error
+}
+
+// Attachment 待导入的附件
+type Attachment struct {
+	FileName string
+	MimeType string
+	Data     []byte
+}
+
+// Normalize 补全标题并截断超长字段
+func (n *Note) Normalize(fallbackTitle string) {
+	n.Title = strings.TrimSpace(n.Title)
+	if n.Title == "" {
+		n.Title = strings.TrimSpace(fallbackTitle)
+	}
+	if n.Title == "" {
+		n.Title = "未命名"
+	}
+	n.Title = truncateRunes(n.Title, maxTitleLen)
+	n.Category = truncatePath(strings.TrimSpace(n.Category), maxCategoryLen)
+	if n.UpdatedAt.IsZero() {
+		n.UpdatedAt = n.CreatedAt
+	}
+}
+
+// ArchiveFile 压缩包（ZIP/TAR）中的文件
+type ArchiveFile struct {
+	Name string
+	open func() (io.ReadCloser, error)
+}
+
+// Read 读取笔记文件内容（限制解压后大小，防止压缩炸弹）
+func (f *ArchiveFile) Read() ([]byte, error) {
+	return f.read(maxFileSize)
+}
+
+// ReadResource 读取附件内容（上限比笔记文件大）
+func (f *ArchiveFile) ReadResource() ([]byte, error) {
+	return f.read(maxResourceSize)
+}
+
+// CopyTo 把文件内容写入 w（用于把内层压缩包解压到临时文件，上限同附件）
+func (f *ArchiveFile) CopyTo(w io.Writer) error {
+	rc, err := f.open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	n, err := io.Copy(w, io.LimitReader(rc, maxResourceSize+1))
+	if err != nil {
+		return err
+	}
+	if n > maxResourceSize {
+		return fmt.Errorf("%w（%dMB）", ErrTooLarge, maxResourceSize>>20)
+	}
+	return nil
+}
+
+func (f *ArchiveFile) read(limit int64) ([]byte, error) {
+	rc, err := f.open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(io.LimitReader(rc, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("%w（%dMB）", ErrTooLarge, limit>>20)
+	}
+	return data, nil
+}
+
+// OpenZip 打开 ZIP 压缩包并返回其中的普通文件（跳过目录、隐藏文件和 macOS 元数据）
+// 文件内容在 Read 时才从 r 中读取，r 在导入结束前不能关闭
+func OpenZip(r io.ReaderAt, size int64) ([]*ArchiveFile, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+	var files []*ArchiveFile
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() || isHidden(f.Name) {
+			continue
+		}
+		files = append(files, &ArchiveFile{Name: f.Name, open: f.Open})
+	}
+	return files, nil
+}
+
+// OpenTar 打开 TAR 归档（如 Joplin 的 .jex）并返回其中的普通文件
+// 只顺序扫描一遍文件头并记录各文件的位置，内容在 Read 时才从 r 中读取（大小上限也在读取时检查）
+func OpenTar(r io.ReaderAt, size int64) ([]*ArchiveFile, error) {
+	counter := &countingReader{r: io.NewSectionReader(r, 0, size)}
+	tr := tar.NewReader(counter)
+	var files []*ArchiveFile
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		name := strings.TrimPrefix(header.Name, "./")
+		if header.Typeflag != tar.TypeReg || isHidden(name) {
+			continue
+		}
+		// 读完文件头后，当前位置即为文件内容的起始位置
+		section := io.NewSectionReader(r, counter.n, header.Size)
+		files = append(files, &ArchiveFile{
+			Name: name,
+			open: func() (io.ReadCloser, error) {
+				return io.NopCloser(io.NewSectionReader(section, 0, section.Size())), nil
+			},
+		})
+	}
+	return files, nil
+}
+
+// OpenFile 把本地文件（如上传的临时文件）包装为 ArchiveFile，读取时同样限制大小
+func OpenFile(name, filePath string) *ArchiveFile {
+	return &ArchiveFile{
+		Name: name,
+		open: func() (io.ReadCloser, error) {
+			return os.Open(filePath)
+		},
+	}
+}
+
+// countingReader 记录已读取的字节数
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// isHidden 判断是否为隐藏文件或系统生成的元数据
+func isHidden(name string) bool {
+	for _, part := range strings.Split(name, "/") {
+		if strings.HasPrefix(part, ".") || part == "__MACOSX" {
+			return true
+		}
+	}
+	return false
+}
+
+// baseName 去掉目录和扩展名的文件名
+func baseName(name string) string {
+	base := path.Base(name)
+	return strings.TrimSuffix(base, path.Ext(base))
+}
+
+// truncateRunes 按字符截断字符串
+func truncateRunes(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max])
+}
+
+// truncatePath 截断笔记本路径：超长时在 / 处截断，只保留完整的上级笔记本（第一级就超长时按字截断）
+func truncatePath(path string, max int) string {
+	runes := []rune(path)
+	if len(runes) <= max {
+		return path
+	}
+	cut := strings.LastIndex(string(runes[:max+1]), "/")
+	if cut <= 0 {
+		return string(runes[:max])
+	}
+	return strings.TrimRight(strings.TrimSpace(string(runes[:max+1])[:cut]), "/")
+}
+
+// md5Hex 计算内容的 MD5（部分笔记软件用它引用附件）
+func md5Hex(data []byte) string {
+	sum := md5.Sum(data)
+	return hex.EncodeToString(sum[:])
+}
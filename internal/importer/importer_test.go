@@ -0,0 +1,28 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTruncatePath(t *testing.T) {
+	long := strings.Repeat("长", 60)
+	tests := []struct {
+		path string
+		max  int
+		want string
+	}{
+		{"工作/项目A", 255, "工作/项目A"},
+		{"工作/项目A/设计", 8, "工作/项目A"},
+		{"工作/项目A/设计", 6, "工作/项目A"},
+		{"工作/项目A/设计", 5, "工作"},
+		{"工作 / 项目A //设计", 9, "工作 / 项目A"},
+		{long + "/a", 50, strings.Repeat("长", 50)},
+		{"/" + long, 10, "/" + strings.Repeat("长", 9)},
+	}
+	for _, tt := range tests {
+		if got := truncatePath(tt.path, tt.max); got != tt.want {
+			t.Errorf("truncatePath(%q, %d) = %q, want %q", tt.path, tt.max, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,224 @@
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/JokerYuan-lang/MyNoteBook/internal/model"
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/storage"
+)
+
+// Joplin 条目类型（元数据中的 type_）
+const (
+	joplinTypeNote     = "1"
+	joplinTypeFolder   = "2"
+	joplinTypeResource = "4"
+	joplinTypeTag      = "5"
+	joplinTypeNoteTag  = "6"
+)
+
+var (
+	joplinMetaRegexp = regexp.MustCompile(`^([a-z_]+): ?(.*)$`)
+	joplinLinkRegexp = regexp.MustCompile(`\]\(:/([0-9a-f]{32})\)`) // 内部链接：[文字](:/<id>)
+)
+
+// joplinItem Joplin 导出中的一个条目（笔记、笔记本、标签、附件等）
+type joplinItem struct {
+	file  string
+	title string
+	body  string
+	meta  map[string]string
+}
+
+// ParseJoplin 解析 Joplin 导出（.jex 解包后的文件或 RAW 导出目录）
+// 笔记本映射为分类（多级用 / 连接），标签映射为标签，笔记间链接改写为临时链接，附件链接改写为 attachment://
+func ParseJoplin(files []*ArchiveFile) ([]Result, error) {
+	// 1. 解析所有条目，按类型归类；资源文件单独记录
+	var (
+		items     = make(map[string]*joplinItem)
+		resFiles  = make(map[string]*ArchiveFile) // 资源ID -> 文件
+		noteOrder []string
+	)
+	for _, f := range files {
+		dir, name := path.Split(f.Name)
+		if strings.HasSuffix(strings.TrimSuffix(dir, "/"), "resources") {
+			id := strings.TrimSuffix(name, path.Ext(name))
+			resFiles[id] = f
+			continue
+		}
+		if path.Ext(name) != ".md" {
+			continue
+		}
+		data, err := f.Read()
+		if err != nil {
+			return nil, fmt.Errorf("读取 %s 失败: %v", f.Name, err)
+		}
+		item := parseJoplinItem(f.Name, string(data))
+		id := item.meta["id"]
+		if id == "" {
+			continue
+		}
+		items[id] = item
+		if item.meta["type_"] == joplinTypeNote {
+			noteOrder = append(noteOrder, id)
+		}
+	}
+	if len(noteOrder) == 0 {
+		return nil, fmt.Errorf("没有找到 Joplin 笔记")
+	}
+
+	// 2. 建立笔记和标签的对应关系
+	noteTags := make(map[string][]string)
+	for _, item := range items {
+		if item.meta["type_"] != joplinTypeNoteTag {
+			continue
+		}
+		if tag, ok := items[item.meta["tag_id"]]; ok {
+			noteID := item.meta["note_id"]
+			noteTags[noteID] = append(noteTags[noteID], tag.title)
+		}
+	}
+
+	// 3. 逐条转换笔记
+	sort.SliceStable(noteOrder, func(i, j int) bool {
+		return items[noteOrder[i]].meta["created_time"] < items[noteOrder[j]].meta["created_time"]
+	})
+	results := make([]Result, 0, len(noteOrder))
+	for _, id := range noteOrder {
+		item := items[id]
+		note, err := convertJoplinNote(item, items, resFiles)
+		if err != nil {
+			results = append(results, Result{File: item.file, Err: err})
+			continue
+		}
+		note.Tags = noteTags[id]
+		results = append(results, Result{File: item.file, Note: note})
+	}
+	return results, nil
+}
+
+// convertJoplinNote 把 Joplin 笔记条目转换为待导入笔记
+func convertJoplinNote(item *joplinItem, items map[string]*joplinItem, resFiles map[string]*ArchiveFile) (*Note, error) {
+	note := &Note{
+		SourceID:  item.meta["id"],
+		Title:     item.title,
+		Category:  joplinFolderPath(item.meta["parent_id"], items),
+		CreatedAt: joplinTime(item.meta, "user_created_time", "created_time"),
+		UpdatedAt: joplinTime(item.meta, "user_updated_time", "updated_time"),
+	}
+
+	// 改写内部链接：指向附件的改为 attachment://，指向笔记的改为临时链接
+	var linkErr error
+	saved := make(map[string]string) // 资源ID -> 文件哈希
+	note.Content = joplinLinkRegexp.ReplaceAllStringFunc(item.body, func(link string) string {
+		id := joplinLinkRegexp.FindStringSubmatch(link)[1]
+		target, ok := items[id]
+		if !ok {
+			return link
+		}
+		switch target.meta["type_"] {
+		case joplinTypeNote:
+			return "](" + NoteRef(id) + ")"
+		case joplinTypeResource:
+			hash, ok := saved[id]
+			if !ok {
+				att, err := joplinResource(target, resFiles)
+				if errors.Is(err, ErrTooLarge) {
+					// 超大附件跳过，保留原链接，笔记照常导入
+					note.Warnings = append(note.Warnings, err.Error())
+					return link
+				}
+				if err != nil {
+					linkErr = err
+					return link
+				}
+				hash = storage.Hash(att.Data)
+				saved[id] = hash
+				note.Attachments = append(note.Attachments, *att)
+			}
+			return "](" + model.AttachmentScheme + hash + ")"
+		}
+		return link
+	})
+	if linkErr != nil {
+		return nil, linkErr
+	}
+
+	note.Normalize(item.title)
+	return note, nil
+}
+
+// joplinResource 读取 Joplin 资源文件作为附件
+func joplinResource(item *joplinItem, resFiles map[string]*ArchiveFile) (*Attachment, error) {
+	f, ok := resFiles[item.meta["id"]]
+	if !ok {
+		return nil, fmt.Errorf("附件 %s 缺失", item.title)
+	}
+	data, err := f.ReadResource()
+	if err != nil {
+		return nil, fmt.Errorf("读取附件 %s 失败: %w", item.title, err)
+	}
+	fileName := item.title
+	if fileName == "" {
+		fileName = path.Base(f.Name)
+	}
+	return &Attachment{FileName: fileName, MimeType: item.meta["mime"], Data: data}, nil
+}
+
+// joplinFolderPath 由笔记本层级生成分类名（如 工作/会议）
+func joplinFolderPath(folderID string, items map[string]*joplinItem) string {
+	var names []string
+	for depth := 0; folderID != "" && depth < 10; depth++ {
+		folder, ok := items[folderID]
+		if !ok || folder.meta["type_"] != joplinTypeFolder {
+			break
+		}
+		names = append([]string{folder.title}, names...)
+		folderID = folder.meta["parent_id"]
+	}
+	return strings.Join(names, "/")
+}
+
+// joplinTime 按优先级读取元数据中的时间
+func joplinTime(meta map[string]string, keys ...string) time.Time {
+	for _, key := range keys {
+		if t, err := time.Parse(time.RFC3339Nano, meta[key]); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// parseJoplinItem 解析 Joplin 条目文件：首行标题、空行、正文，末尾是 key: value 形式的元数据
+func parseJoplinItem(file, text string) *joplinItem {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	// 从末尾向前收集元数据
+	item := &joplinItem{file: file, meta: make(map[string]string)}
+	metaStart := len(lines)
+	for metaStart > 0 {
+		m := joplinMetaRegexp.FindStringSubmatch(lines[metaStart-1])
+		if m == nil {
+			break
+		}
+		item.meta[m[1]] = m[2]
+		metaStart--
+	}
+
+	content := lines[:metaStart]
+	if len(content) > 0 {
+		item.title = strings.TrimSpace(content[0])
+	}
+	if len(content) > 2 {
+		item.body = strings.TrimSpace(strings.Join(content[2:], "\n"))
+	}
+	return item
+}
@@ -0,0 +1,35 @@
+package importer
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/JokerYuan-lang/MyNoteBook/internal/model"
+)
+
+// 导入过程中指向来源笔记的临时链接：note-ref://<SourceID>，导入完成后改写为 note://<笔记ID>
+const noteRefScheme = "note-ref://"
+
+var noteRefRegexp = regexp.MustCompile(`\[([^\]]*)\]\(` + regexp.QuoteMeta(noteRefScheme) + `([^)\s]+)\)`)
+
+// NoteRef 生成指向来源笔记的临时链接
+func NoteRef(sourceID string) string {
+	return noteRefScheme + sourceID
+}
+
+// HasNoteRefs 判断内容中是否包含待改写的笔记链接
+func HasNoteRefs(content string) bool {
+	return strings.Contains(content, noteRefScheme)
+}
+
+// ResolveNoteRefs 把临时链接改写为 note://<笔记ID>；目标笔记未导入时只保留链接文字
+func ResolveNoteRefs(content string, resolve func(sourceID string) (uint, bool)) string {
+	return noteRefRegexp.ReplaceAllStringFunc(content, func(link string) string {
+		m := noteRefRegexp.FindStringSubmatch(link)
+		if noteID, ok := resolve(m[2]); ok {
+			return fmt.Sprintf("[%s](%s%d)", m[1], model.NoteLinkScheme, noteID)
+		}
+		return m[1]
+	})
+}
@@ -0,0 +1,60 @@
+package importer
+
+import (
+	"path"
+	"strings"
+
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/frontmatter"
+)
+
+// IsMarkdown 判断文件是否为 Markdown 文件
+func IsMarkdown(name string) bool {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".md", ".markdown", ".txt":
+		return true
+	}
+	return false
+}
+
+// ParseMarkdown 解析 Markdown 文件（front matter 提供标题/分类/标签/时间）
+// 没有 front matter 时：标题取一级标题或文件名，分类取所在目录名
+func ParseMarkdown(name string, data []byte) (*Note, error) {
+	meta, body, err := frontmatter.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+
+	note := &Note{
+		Title:     meta.Title,
+		Content:   body,
+		Category:  meta.Category,
+		Tags:      meta.Tags,
+		CreatedAt: meta.Created,
+		UpdatedAt: meta.Updated,
+	}
+	if note.Title == "" {
+		note.Title = firstHeading(body)
+	}
+	if note.Category == "" {
+		if dir := path.Dir(name); dir != "." && dir != "/" {
+			note.Category = path.Base(dir)
+		}
+	}
+	note.Normalize(baseName(name))
+	return note, nil
+}
+
+// firstHeading 返回正文第一行的一级标题（没有则返回空）
+func firstHeading(body string) string {
+	for _, line := range strings.Split(body, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "# ") {
+			return strings.TrimSpace(line[2:])
+		}
+		return ""
+	}
+	return ""
+}
@@ -0,0 +1,244 @@
+package importer
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/JokerYuan-lang/MyNoteBook/internal/model"
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/storage"
+)
+
+var (
+	notionNameRegexp = regexp.MustCompile(`^(.*?)\s*([0-9a-f]{32})$`)      // 文件名：标题 + 32位ID
+	notionLinkRegexp = regexp.MustCompile(`(!?)\[([^\]]*)\]\(([^)\s]+)\)`) // Markdown 链接/图片
+)
+
+// Notion 属性时间格式
+var notionTimeLayouts = []string{
+	"January 2, 2006 3:04 PM",
+	"January 2, 2006",
+	"2006/01/02 15:04",
+	"2006年1月2日 15:04",
+	time.RFC3339,
+}
+
+// notionPage Notion 导出中的一个页面
+type notionPage struct {
+	file  string
+	id    string
+	title string
+}
+
+// ParseNotion 解析 Notion 的 Markdown & CSV 导出
+// 父页面/数据库的层级映射为分类（多级用 / 连接），数据库的 Tags 属性映射为标签，
+// 页面间链接改写为临时链接，引用的图片等文件作为附件导入
+func ParseNotion(files []*ArchiveFile) ([]Result, error) {
+	// 1. 建立文件索引：页面、数据库表头
+	var (
+		byName  = make(map[string]*ArchiveFile)
+		pages   = make(map[string]*notionPage) // 文件路径 -> 页面
+		columns = make(map[string][]string)    // 数据库目录 -> 属性列名
+		order   []string
+	)
+	for _, f := range files {
+		byName[f.Name] = f
+		switch strings.ToLower(path.Ext(f.Name)) {
+		case ".md":
+			title, id := notionSplitName(f.Name)
+			if id == "" {
+				id = f.Name
+			}
+			pages[f.Name] = &notionPage{file: f.Name, id: id, title: title}
+			order = append(order, f.Name)
+		case ".csv":
+			header, err := notionCSVHeader(f)
+			if err != nil {
+				return nil, fmt.Errorf("读取 %s 失败: %v", f.Name, err)
+			}
+			columns[strings.TrimSuffix(f.Name, path.Ext(f.Name))] = header
+		}
+	}
+	if len(order) == 0 {
+		return nil, fmt.Errorf("没有找到 Notion 页面")
+	}
+	sort.Strings(order)
+
+	// 2. 逐页转换
+	results := make([]Result, 0, len(order))
+	for _, name := range order {
+		page := pages[name]
+		data, err := byName[name].Read()
+		if err != nil {
+			results = append(results, Result{File: name, Err: err})
+			continue
+		}
+		note, err := convertNotionPage(page, string(data), columns[path.Dir(name)], pages, byName)
+		results = append(results, Result{File: name, Note: note, Err: err})
+	}
+	return results, nil
+}
+
+// convertNotionPage 把 Notion 页面转换为待导入笔记（columns 不为空表示页面是数据库中的一行）
+func convertNotionPage(page *notionPage, text string, columns []string, pages map[string]*notionPage, byName map[string]*ArchiveFile) (*Note, error) {
+	note := &Note{
+		SourceID: page.id,
+		Title:    page.title,
+		Category: notionCategory(page.file),
+	}
+
+	// 1. 标题：首行一级标题
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	if len(lines) > 0 && strings.HasPrefix(lines[0], "# ") {
+		note.Title = strings.TrimSpace(lines[0][2:])
+		lines = lines[1:]
+	}
+
+	// 2. 数据库属性：紧随标题、以已知列名开头的 key: value 行
+	if len(columns) > 0 {
+		known := make(map[string]bool, len(columns))
+		for _, col := range columns {
+			known[col] = true
+		}
+		start := 0
+		for start < len(lines) && strings.TrimSpace(lines[start]) == "" {
+			start++
+		}
+		end := start
+		for ; end < len(lines); end++ {
+			key, value, ok := strings.Cut(lines[end], ": ")
+			if !ok || !known[key] {
+				break
+			}
+			applyNotionProperty(note, key, value)
+		}
+		if end > start {
+			lines = lines[end:]
+		}
+	}
+
+	// 3. 改写链接：页面链接改为临时链接，本地文件作为附件
+	var linkErr error
+	saved := make(map[string]string) // 文件路径 -> 文件哈希
+	content := strings.TrimSpace(strings.Join(lines, "\n"))
+	note.Content = notionLinkRegexp.ReplaceAllStringFunc(content, func(link string) string {
+		m := notionLinkRegexp.FindStringSubmatch(link)
+		image, text, target := m[1], m[2], m[3]
+		if strings.Contains(target, "://") || strings.HasPrefix(target, "#") || strings.HasPrefix(target, "mailto:") {
+			return link
+		}
+		unescaped, err := url.PathUnescape(target)
+		if err != nil {
+			return link
+		}
+		file := path.Join(path.Dir(page.file), unescaped)
+
+		if linked, ok := pages[file]; ok {
+			return fmt.Sprintf("%s[%s](%s)", image, text, NoteRef(linked.id))
+		}
+		f, ok := byName[file]
+		if !ok {
+			return link
+		}
+		if strings.EqualFold(path.Ext(file), ".csv") {
+			// 指向数据库的链接在导入后没有对应页面，只保留文字
+			return text
+		}
+		hash, ok := saved[file]
+		if !ok {
+			data, err := f.ReadResource()
+			if errors.Is(err, ErrTooLarge) {
+				// 超大附件跳过，保留原链接，笔记照常导入
+				note.Warnings = append(note.Warnings, fmt.Sprintf("附件 %s 已跳过: %v", file, err))
+				return link
+			}
+			if err != nil {
+				linkErr = fmt.Errorf("读取附件 %s 失败: %v", file, err)
+				return link
+			}
+			hash = storage.Hash(data)
+			saved[file] = hash
+			note.Attachments = append(note.Attachments, Attachment{FileName: path.Base(file), Data: data})
+		}
+		return fmt.Sprintf("%s[%s](%s%s)", image, text, model.AttachmentScheme, hash)
+	})
+	if linkErr != nil {
+		return nil, linkErr
+	}
+
+	note.Normalize(page.title)
+	return note, nil
+}
+
+// applyNotionProperty 识别标签和时间属性（其余属性忽略）
+func applyNotionProperty(note *Note, key, value string) {
+	switch strings.ToLower(key) {
+	case "tags", "tag", "标签":
+		for _, tag := range strings.Split(value, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				note.Tags = append(note.Tags, tag)
+			}
+		}
+	case "created", "created time", "创建时间":
+		note.CreatedAt = parseNotionTime(value)
+	case "last edited time", "updated", "更新时间", "上次编辑时间":
+		note.UpdatedAt = parseNotionTime(value)
+	}
+}
+
+// parseNotionTime 解析属性中的时间（无法识别返回零值）
+func parseNotionTime(value string) time.Time {
+	value = strings.TrimSpace(value)
+	for _, layout := range notionTimeLayouts {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// notionCategory 由父页面/数据库目录生成分类名（去掉 ID 后缀，多级用 / 连接）
+func notionCategory(file string) string {
+	dir := path.Dir(file)
+	if dir == "." || dir == "/" {
+		return ""
+	}
+	var names []string
+	for _, part := range strings.Split(dir, "/") {
+		title, id := notionSplitName(part)
+		// 跳过导出包外层不带 ID 的目录（如 Export-xxx）
+		if id == "" && len(names) == 0 {
+			continue
+		}
+		names = append(names, title)
+	}
+	return strings.Join(names, "/")
+}
+
+// notionSplitName 拆分文件名中的标题和 ID
+func notionSplitName(name string) (title, id string) {
+	base := strings.TrimSuffix(path.Base(name), path.Ext(name))
+	if m := notionNameRegexp.FindStringSubmatch(base); m != nil {
+		return m[1], m[2]
+	}
+	return base, ""
+}
+
+// notionCSVHeader 读取数据库 CSV 的表头（去掉 BOM）
+func notionCSVHeader(f *ArchiveFile) ([]string, error) {
+	data, err := f.Read()
+	if err != nil {
+		return nil, err
+	}
+	header, err := csv.NewReader(strings.NewReader(strings.TrimPrefix(string(data), "\ufeff"))).Read()
+	if err != nil {
+		return nil, err
+	}
+	return header, nil
+}
@@ -0,0 +1,60 @@
+package importer
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	md "github.com/JohannesKaufmann/html-to-markdown"
+	"github.com/JohannesKaufmann/html-to-markdown/plugin"
+	"github.com/PuerkitoBio/goquery"
+	"github.com/go-shiori/go-readability"
+)
+
+// maxClipImages 单个网页最多下载的图片数量
+const maxClipImages = 30
+
+// ParseWebPage 提取网页正文（readability 算法）并转换为 Markdown
+// 返回的笔记以来源域名为标签、末尾附上原文链接；images 为正文中引用的图片绝对地址（去重，最多 maxClipImages 个）
+func ParseWebPage(pageHTML string, pageURL *url.URL) (note *Note, images []string, err error) {
+	article, err := readability.FromReader(strings.NewReader(pageHTML), pageURL)
+	if err != nil {
+		return nil, nil, fmt.Errorf("无法提取网页正文: %v", err)
+	}
+	if strings.TrimSpace(article.Content) == "" {
+		return nil, nil, fmt.Errorf("网页中没有可提取的正文")
+	}
+
+	// 图片地址已由 readability 转为绝对地址，记录下来供下载
+	seen := make(map[string]bool)
+	converter := md.NewConverter(pageURL.Host, true, nil)
+	converter.Use(plugin.GitHubFlavored())
+	converter.AddRules(md.Rule{
+		Filter: []string{"img"},
+		Replacement: func(_ string, sel *goquery.Selection, _ *md.Options) *string {
+			src := strings.TrimSpace(sel.AttrOr("src", ""))
+			if src == "" || strings.HasPrefix(src, "data:") {
+				return md.String("")
+			}
+			if !seen[src] && len(images) < maxClipImages {
+				seen[src] = true
+				images = append(images, src)
+			}
+			alt := strings.NewReplacer("[", "", "]", "").Replace(sel.AttrOr("alt", ""))
+			return md.String(fmt.Sprintf("![%s](%s)", alt, src))
+		},
+	})
+	content, err := converter.ConvertString(article.Content)
+	if err != nil {
+		return nil, nil, fmt.Errorf("网页内容转换失败: %v", err)
+	}
+
+	domain := strings.TrimPrefix(strings.ToLower(pageURL.Hostname()), "www.")
+	note = &Note{
+		Title:   strings.TrimSpace(article.Title),
+		Content: fmt.Sprintf("%s\n\n---\n\n原文：<%s>", strings.TrimSpace(content), pageURL.String()),
+		Tags:    []string{domain},
+	}
+	note.Normalize(domain)
+	return note, images, nil
+}
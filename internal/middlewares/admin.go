@@ -0,0 +1,33 @@
+package middlewares
+
+import (
+	"github.com/JokerYuan-lang/MyNoteBook/internal/model"
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/errcode"
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/response"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// AdminCheck 管理员权限中间件（需放在 AuthCheck 之后）
+func AdminCheck(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, _ := c.Get("user_id")
+
+		// 角色以数据库为准（Token 中不携带角色，撤销管理员后立即生效）
+		var user model.User
+		if err := db.Select("id, role").First(&user, userID.(uint)).Error; err != nil {
+			zap.S().Errorf("查询用户角色失败: %v", err)
+			response.ErrorWithDefaultMsg(c, errcode.Forbidden)
+			c.Abort()
+			return
+		}
+		if user.Role != model.RoleAdmin {
+			response.ErrorWithDefaultMsg(c, errcode.Forbidden)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,17 @@
+package model
+
+import "gorm.io/gorm"
+
+// AttachmentScheme 笔记内容中引用附件的链接前缀：attachment://<文件 SHA-256>
+const AttachmentScheme = "attachment://"
+
+// Attachment 笔记附件（文件本体按 SHA-256 存储在磁盘上）
+type Attachment struct {
+	gorm.Model        // 继承 ID/CreatedAt/UpdatedAt/DeletedAt
+	UserID     uint   `gorm:"not null;index;comment:'所属用户ID'"`
+	NoteID     uint   `gorm:"not null;index;comment:'所属笔记ID'"`
+	FileName   string `gorm:"type:varchar(255);not null;comment:'文件名'"`
+	MimeType   string `gorm:"type:varchar(100);comment:'文件类型'"`
+	Size       int64  `gorm:"comment:'文件大小（字节）'"`
+	Hash       string `gorm:"type:varchar(64);not null;index;comment:'文件 SHA-256'"`
+}
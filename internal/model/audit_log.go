@@ -0,0 +1,37 @@
+package model
+
+import "gorm.io/gorm"
+
+// 审计动作
+const (
+	AuditActionRegister    = "register"     // 注册
+	AuditActionLogin       = "login"        // 登录成功
+	AuditActionLoginFailed = "login_failed" // 登录失败
+	AuditActionTokenIssued = "token_issued" // 签发 Token
+	AuditActionNoteCreate  = "note_create"  // 创建笔记
+	AuditActionNoteUpdate  = "note_update"  // 更新笔记
+	AuditActionNoteDelete  = "note_delete"  // 删除笔记
+	AuditActionNoteMerge   = "note_merge"   // 合并笔记
+	AuditActionNoteRestore = "note_restore" // 从合并回收站恢复笔记
+	AuditActionNoteImport  = "note_import"  // 导入笔记（每个导入任务一条）
+)
+
+// 审计对象类型
+const (
+	AuditTargetUser      = "user"
+	AuditTargetNote      = "note"
+	AuditTargetImportJob = "import_job"
+)
+
+// AuditLog 审计日志模型（记录谁在何时从哪里做了什么）
+type AuditLog struct {
+	gorm.Model        // 继承 ID/CreatedAt/UpdatedAt/DeletedAt
+	ActorID    uint   `gorm:"index;comment:'操作者用户ID（未登录为0）'"`
+	ActorName  string `gorm:"type:varchar(50);comment:'操作者用户名'"`
+	Action     string `gorm:"type:varchar(30);not null;index;comment:'动作'"`
+	TargetType string `gorm:"type:varchar(20);comment:'对象类型'"`
+	TargetID   uint   `gorm:"comment:'对象ID'"`
+	IP         string `gorm:"type:varchar(64);comment:'客户端IP'"`
+	UserAgent  string `gorm:"type:varchar(255);comment:'客户端UA'"`
+	Detail     string `gorm:"type:varchar(255);comment:'补充说明'"`
+}
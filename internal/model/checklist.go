@@ -0,0 +1,13 @@
+package model
+
+import "gorm.io/gorm"
+
+// ChecklistItem 笔记中的清单项
+type ChecklistItem struct {
+	gorm.Model        // 继承 ID/CreatedAt/UpdatedAt/DeletedAt
+	NoteID     uint   `gorm:"not null;index;comment:'所属笔记ID'"`
+	UserID     uint   `gorm:"not null;comment:'所属用户ID'"`
+	Text       string `gorm:"type:varchar(255);not null;comment:'清单内容'"`
+	Done       bool   `gorm:"default:false;comment:'是否完成'"`
+	Position   int    `gorm:"default:0;comment:'排序位置（越小越靠前）'"`
+}
@@ -0,0 +1,36 @@
+package model
+
+// 知识图谱节点类型
+const (
+	GraphNodeNote = "note"
+	GraphNodeTag  = "tag"
+)
+
+// 知识图谱边类型
+const (
+	GraphEdgeLink = "link" // 笔记链接（有向：Source 链接到 Target）
+	GraphEdgeTag  = "tag"  // 标签归属（Source 为笔记，Target 为标签）
+)
+
+// GraphNode 知识图谱节点（不落库）
+type GraphNode struct {
+	ID       string // 节点标识，如 note:12、tag:3
+	Type     string // note / tag
+	RefID    uint   // 笔记ID或标签ID
+	Label    string // 笔记标题或标签名
+	Category string // 笔记分类（标签节点为空）
+	Degree   int    // 连接的边数（便于前端按大小绘制）
+}
+
+// GraphEdge 知识图谱边
+type GraphEdge struct {
+	Source string
+	Target string
+	Type   string // link / tag
+}
+
+// Graph 知识图谱
+type Graph struct {
+	Nodes []GraphNode
+	Edges []GraphEdge
+}
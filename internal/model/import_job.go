@@ -0,0 +1,33 @@
+package model
+
+import "gorm.io/gorm"
+
+// 导入任务状态
+const (
+	ImportStatusPending = "pending" // 等待执行
+	ImportStatusRunning = "running" // 执行中
+	ImportStatusDone    = "done"    // 已完成（可能有部分文件失败）
+	ImportStatusFailed  = "failed"  // 整体失败（如压缩包无法解析，或服务重启导致任务中断）
+)
+
+// 导入来源
+const (
+	ImportSourceMarkdown = "markdown"
+	ImportSourceENEX     = "enex" // Evernote
+	ImportSourceJoplin   = "joplin"
+	ImportSourceNotion   = "notion"
+)
+
+// ImportJob 笔记导入任务（异步执行，记录进度和逐文件错误）
+type ImportJob struct {
+	gorm.Model        // 继承 ID/CreatedAt/UpdatedAt/DeletedAt
+	UserID     uint   `gorm:"not null;index;comment:'所属用户ID'"`
+	Source     string `gorm:"type:varchar(20);not null;comment:'导入来源'"`
+	Status     string `gorm:"type:varchar(20);not null;comment:'任务状态'"`
+	Total      int    `gorm:"default:0;comment:'待处理文件数（未知为0）'"`
+	Processed  int    `gorm:"default:0;comment:'已处理文件数'"`
+	Created    int    `gorm:"default:0;comment:'成功导入数'"`
+	Skipped    int    `gorm:"default:0;comment:'重复跳过数'"`
+	Failed     int    `gorm:"default:0;comment:'失败数'"`
+	Errors     string `gorm:"type:text;comment:'逐文件错误（JSON）'"`
+}
@@ -0,0 +1,22 @@
+package model
+
+import "time"
+
+// MergedNote 合并回收站：记录被合并（软删除）的笔记及其合并前的标签，可从回收站恢复
+type MergedNote struct {
+	ID        uint   `gorm:"primaryKey"`
+	UserID    uint   `gorm:"not null;index;comment:'所属用户ID'"`
+	NoteID    uint   `gorm:"not null;uniqueIndex;comment:'被合并的笔记ID（已软删除）'"`
+	TargetID  uint   `gorm:"not null;comment:'合并到的笔记ID'"`
+	TagNames  string `gorm:"type:text;comment:'合并前的标签（换行分隔，恢复时重新关联）'"`
+	CreatedAt time.Time
+}
+
+// MergedNoteItem 回收站中的一条笔记（不落库）
+type MergedNoteItem struct {
+	NoteID   uint
+	Title    string
+	Category string
+	TargetID uint
+	MergedAt time.Time
+}
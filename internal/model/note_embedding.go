@@ -0,0 +1,24 @@
+package model
+
+import "time"
+
+// NoteEmbedding 笔记语义向量（后台按标题和纯文本计算，向量提供方变化或笔记更新后重算）
+type NoteEmbedding struct {
+	NoteID      uint   `gorm:"primaryKey;autoIncrement:false;comment:'笔记ID'"`
+	UserID      uint   `gorm:"not null;index;comment:'所属用户ID'"`
+	Provider    string `gorm:"type:varchar(100);not null;comment:'向量提供方标识（含模型名）'"`
+	ContentHash string `gorm:"type:varchar(64);not null;comment:'计算向量所用文本的 SHA-256（内容未变时不重算）'"`
+	Vector      []byte `gorm:"type:mediumblob;not null;comment:'归一化向量（小端 float32；计算失败时为空，笔记更新后重试）'"`
+	UpdatedAt   time.Time
+}
+
+// SemanticHit 语义搜索结果（不落库）
+type SemanticHit struct {
+	NoteID        uint
+	Title         string
+	Category      string
+	Excerpt       string
+	Score         float64 // 综合得分（语义相似度与关键词得分加权）
+	SemanticScore float64 // 语义相似度（余弦相似度，0~1）
+	TextScore     float64 // 关键词得分（TF-IDF，按本次结果的最高分归一到 0~1）
+}
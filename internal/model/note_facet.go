@@ -0,0 +1,14 @@
+package model
+
+// FacetCount 分面中的一项及其笔记数量
+type FacetCount struct {
+	Value string
+	Count int64
+}
+
+// NoteFacets 笔记列表的分面统计（不落库）
+type NoteFacets struct {
+	Categories []FacetCount // 按分类
+	Tags       []FacetCount // 按标签
+	Months     []FacetCount // 按更新月份（如 2026-01）
+}
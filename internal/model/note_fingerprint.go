@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+// NoteFingerprint 笔记内容指纹（标题和纯文本分词的 SimHash，保存笔记时更新，用于查找近似重复的笔记）
+type NoteFingerprint struct {
+	NoteID    uint   `gorm:"primaryKey;autoIncrement:false;comment:'笔记ID'"`
+	UserID    uint   `gorm:"not null;index;comment:'所属用户ID'"`
+	SimHash   uint64 `gorm:"not null;comment:'64 位 SimHash'"`
+	Features  int    `gorm:"not null;default:0;comment:'参与计算的分词数（为 0 时不参与查重）'"`
+	UpdatedAt time.Time
+}
+
+// DuplicateNote 重复笔记组中的一条笔记（不落库）
+type DuplicateNote struct {
+	NoteID     uint
+	Title      string
+	Category   string
+	UpdatedAt  time.Time
+	Similarity float64 // 与组内第一条笔记（最近更新，建议作为合并目标）的相似度
+}
+
+// DuplicateCluster 一组近似重复的笔记（不落库）
+type DuplicateCluster struct {
+	Similarity float64         // 组内笔记与第一条笔记的最低相似度
+	Notes      []DuplicateNote // 按更新时间倒序
+}
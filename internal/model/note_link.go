@@ -0,0 +1,27 @@
+package model
+
+import "gorm.io/gorm"
+
+// NoteLink 笔记之间的链接（保存笔记时从内容中解析；目标不存在时 TargetNoteID 为空，即断链）
+type NoteLink struct {
+	gorm.Model
+	UserID       uint   `gorm:"not null;index;comment:'所属用户ID'"`
+	SourceNoteID uint   `gorm:"not null;index;comment:'链接所在笔记ID'"`
+	TargetNoteID *uint  `gorm:"index;comment:'目标笔记ID（断链为空）'"`
+	TargetTitle  string `gorm:"type:varchar(100);index;comment:'按标题链接时的目标标题'"`
+}
+
+// LinkedNote 笔记详情中展示的出链或反链
+type LinkedNote struct {
+	NoteID uint   // 关联笔记ID（断链为 0）
+	Title  string // 关联笔记标题（断链时为链接中写的标题）
+	Broken bool   // 是否断链
+}
+
+// BrokenLink 断链报告中的一条记录
+type BrokenLink struct {
+	SourceNoteID uint
+	SourceTitle  string
+	TargetTitle  string // 按标题链接时的目标标题
+	TargetNoteID uint   // 按 ID 链接时的目标ID（目标已删除）
+}
@@ -0,0 +1,17 @@
+package model
+
+// 索引词来源
+const (
+	TermFieldText   = "text"   // 标题、正文和标签的分词
+	TermFieldPinyin = "pinyin" // 标题和标签的拼音（全拼、首字母）
+)
+
+// NoteTerm 笔记搜索索引（每条笔记的每个索引词一行，保存笔记时重建）
+type NoteTerm struct {
+	ID     uint   `gorm:"primaryKey"`
+	UserID uint   `gorm:"not null;index:idx_note_terms_user_term,priority:1;comment:'所属用户ID'"`
+	NoteID uint   `gorm:"not null;index:idx_note_terms_note_term,priority:1;comment:'笔记ID'"`
+	Term   string `gorm:"type:varchar(64);not null;index:idx_note_terms_user_term,priority:2;index:idx_note_terms_note_term,priority:2;comment:'索引词'"`
+	Field  string `gorm:"type:varchar(10);not null;comment:'来源（text/pinyin）'"`
+	Count  int    `gorm:"not null;default:1;comment:'出现次数'"`
+}
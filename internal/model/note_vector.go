@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+// NoteVector 笔记 TF-IDF 词向量的模（后台按用户定期重算，用于计算余弦相似度）
+type NoteVector struct {
+	NoteID    uint    `gorm:"primaryKey;autoIncrement:false;comment:'笔记ID'"`
+	UserID    uint    `gorm:"not null;index;comment:'所属用户ID'"`
+	Norm      float64 `gorm:"not null;default:0;comment:'向量的模'"`
+	UpdatedAt time.Time
+}
+
+// RelatedNote 相关笔记推荐结果（不落库）
+type RelatedNote struct {
+	NoteID     uint
+	Title      string
+	Category   string
+	Score      float64  // 相似度（内容余弦相似度 + 标签重合加分）
+	SharedTags []string // 共同标签
+}
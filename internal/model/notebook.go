@@ -0,0 +1,22 @@
+package model
+
+import "gorm.io/gorm"
+
+// 笔记本路径
+const (
+	NotebookPathSeparator = "/"  // 路径分隔符（如「工作/项目A」）
+	DefaultNotebookName   = "默认" // 未指定笔记本时使用的顶级笔记本
+)
+
+// Notebook 笔记本（按用户分层级；笔记的 Category 冗余保存所在笔记本的完整路径，重命名、移动时同步更新）
+type Notebook struct {
+	gorm.Model        // 继承 ID/CreatedAt/UpdatedAt/DeletedAt
+	UserID     uint   `gorm:"not null;uniqueIndex:idx_notebooks_user_parent_name,priority:1;comment:'所属用户ID'"`
+	ParentID   uint   `gorm:"not null;default:0;uniqueIndex:idx_notebooks_user_parent_name,priority:2;comment:'上级笔记本ID（顶级为0）'"`
+	Name       string `gorm:"type:varchar(50);not null;uniqueIndex:idx_notebooks_user_parent_name,priority:3;comment:'笔记本名称'"`
+	Path       string `gorm:"type:varchar(255);not null;index;comment:'完整路径'"`
+
+	NoteCount  int        `gorm:"-"` // 直接属于该笔记本的笔记数（树形列表时统计）
+	TotalCount int        `gorm:"-"` // 含下级笔记本的笔记总数
+	Children   []Notebook `gorm:"-"` // 下级笔记本
+}
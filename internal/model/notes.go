@@ -1,13 +1,39 @@
 package model
 
-import "gorm.io/gorm"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// NoteLinkScheme 笔记内容中引用其他笔记的链接前缀：note://<笔记ID>
+const NoteLinkScheme = "note://"
 
 // Note 笔记模型
 type Note struct {
-	gorm.Model        // 继承 ID/CreatedAt/UpdatedAt/DeletedAt
-	Title      string `gorm:"type:varchar(100);not null;comment:'笔记标题'"`
-	Content    string `gorm:"type:text;not null;comment:'笔记内容'"`
-	Category   string `gorm:"type:varchar(50);default:'默认';comment:'笔记分类'"` // 新增分类字段
-	UserID     uint   `gorm:"not null;comment:'所属用户ID'"`
-	Tags       []Tag  `gorm:"many2many:note_tags;comment:'关联的标签'"` // 多对多（通过中间表 note_tags）
+	gorm.Model               // 继承 ID/CreatedAt/UpdatedAt/DeletedAt
+	Title         string     `gorm:"type:varchar(100);not null;comment:'笔记标题'"`
+	Content       string     `gorm:"type:text;not null;comment:'笔记内容'"`
+	ContentFormat string     `gorm:"type:varchar(20);default:'markdown';comment:'内容格式（plain/markdown/html）'"`
+	RenderedHTML  *string    `gorm:"type:mediumtext;comment:'渲染后的 HTML 缓存'"`
+	PlainText     *string    `gorm:"type:text;comment:'纯文本缓存（用于列表摘要和搜索）'"`
+	Category      string     `gorm:"type:varchar(255);default:'默认';index;comment:'所在笔记本的完整路径'"` // 冗余保存，便于按路径筛选和导出
+	NotebookID    uint       `gorm:"not null;default:0;index;comment:'所在笔记本ID'"`
+	UserID        uint       `gorm:"not null;comment:'所属用户ID'"`
+	RemindAt      *time.Time `gorm:"index;comment:'提醒时间'"`
+	DueAt         *time.Time `gorm:"index;comment:'截止时间'"`
+	Reminded      bool       `gorm:"default:false;comment:'本次提醒是否已发送'"`
+	CompletedAt   *time.Time `gorm:"comment:'完成时间'"`
+	Pinned        bool       `gorm:"not null;default:false;comment:'是否置顶'"`
+	Favorite      bool       `gorm:"not null;default:false;comment:'是否收藏'"`
+	Archived      bool       `gorm:"not null;default:false;index;comment:'是否归档（默认列表不显示，搜索可见）'"`
+	ImportHash    string     `gorm:"type:varchar(64);index;comment:'导入时原始内容的 SHA-256（改写链接前计算，用于重复导入去重）'" json:"-"`
+	Tags          []Tag      `gorm:"many2many:note_tags;comment:'关联的标签'"` // 多对多（通过中间表 note_tags）
+
+	ChecklistItems []ChecklistItem `gorm:"foreignKey:NoteID"` // 清单项（一对多）
+	ChecklistTotal int             `gorm:"-"`                 // 清单项总数（列表查询时统计）
+	ChecklistDone  int             `gorm:"-"`                 // 已完成清单项数
+	Excerpt        string          `gorm:"-"`                 // 摘要（列表查询时由纯文本缓存生成）
+	OutgoingLinks  []LinkedNote    `gorm:"-"`                 // 出链（详情查询时填充）
+	Backlinks      []LinkedNote    `gorm:"-"`                 // 反链（详情查询时填充）
 }
@@ -0,0 +1,25 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 通知类型
+const (
+	NotificationTypeMention  = "mention"  // 在笔记中被 @
+	NotificationTypeReminder = "reminder" // 笔记提醒到期
+)
+
+// Notification 站内通知模型
+type Notification struct {
+	gorm.Model            // 继承 ID/CreatedAt/UpdatedAt/DeletedAt
+	UserID     uint       `gorm:"not null;index;comment:'接收者用户ID'"`
+	ActorID    uint       `gorm:"not null;comment:'触发者用户ID（系统为0）'"`
+	Type       string     `gorm:"type:varchar(20);not null;comment:'通知类型'"`
+	NoteID     uint       `gorm:"comment:'关联笔记ID'"`
+	Content    string     `gorm:"type:varchar(255);not null;comment:'通知内容'"`
+	IsRead     bool       `gorm:"default:false;index;comment:'是否已读'"`
+	ReadAt     *time.Time `gorm:"comment:'阅读时间'"`
+}
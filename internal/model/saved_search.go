@@ -0,0 +1,11 @@
+package model
+
+import "gorm.io/gorm"
+
+// SavedSearch 保存的搜索（智能文件夹），执行时按搜索语句实时查询笔记
+type SavedSearch struct {
+	gorm.Model
+	UserID uint   `gorm:"not null;uniqueIndex:idx_user_search_name;comment:'所属用户ID'"`
+	Name   string `gorm:"type:varchar(50);not null;uniqueIndex:idx_user_search_name;comment:'名称'"`
+	Query  string `gorm:"type:varchar(500);not null;comment:'搜索语句'"`
+}
@@ -0,0 +1,26 @@
+package model
+
+import "gorm.io/gorm"
+
+// 自动打标签规则的匹配方式
+const (
+	TagRuleKeyword = "keyword" // 标题或内容包含关键词（不区分大小写）
+	TagRuleRegex   = "regex"   // 标题或内容匹配正则表达式
+)
+
+// TagRule 自动打标签规则：保存笔记时标题或内容匹配则自动加上标签
+type TagRule struct {
+	gorm.Model
+	UserID    uint   `gorm:"not null;index;comment:'所属用户ID'"`
+	TagName   string `gorm:"type:varchar(100);not null;comment:'匹配时添加的标签'"`
+	MatchType string `gorm:"type:varchar(10);not null;comment:'匹配方式（keyword/regex）'"`
+	Pattern   string `gorm:"type:varchar(255);not null;comment:'关键词或正则表达式'"`
+	Enabled   bool   `gorm:"default:true;comment:'是否启用'"`
+}
+
+// TagSuggestion 标签推荐结果（不落库）
+type TagSuggestion struct {
+	Name   string
+	Score  float64
+	Reason string // 推荐原因：内容包含标签词 / 相似笔记使用
+}
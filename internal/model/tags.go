@@ -2,10 +2,13 @@ package model
 
 import "gorm.io/gorm"
 
+// TagPathSeparator 多级标签的分隔符（如 project/alpha/design，按上级标签筛选时包含全部下级标签）
+const TagPathSeparator = "/"
+
 // Tag 标签模型
 type Tag struct {
 	gorm.Model        // 继承 ID/CreatedAt/UpdatedAt/DeletedAt
-	Name       string `gorm:"type:varchar(50);not null;comment:'标签名称'"`
+	Name       string `gorm:"type:varchar(100);not null;comment:'标签名称（多级标签为完整路径）'"`
 	UserID     uint   `gorm:"not null;comment:'所属用户ID'"`           // 新增用户ID，确保标签按用户隔离
 	Notes      []Note `gorm:"many2many:note_tags;comment:'关联的笔记'"` // 多对多
 }
@@ -16,3 +19,13 @@ type NoteTag struct {
 	NoteID uint `gorm:"primaryKey;comment:'笔记ID'"`
 	TagID  uint `gorm:"primaryKey;comment:'标签ID'"`
 }
+
+// TagNode 标签树节点（不落库；只作为上级出现、本身没有笔记使用的标签 TagID 为 0）
+type TagNode struct {
+	TagID      uint
+	Name       string    // 本级名称
+	Path       string    // 完整标签名
+	NoteCount  int       // 直接使用该标签的笔记数
+	TotalCount int       // 使用该标签或其下级标签的笔记数（去重）
+	Children   []TagNode // 下级标签
+}
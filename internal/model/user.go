@@ -6,13 +6,21 @@ import (
 	"gorm.io/gorm"
 )
 
+// 用户角色
+const (
+	RoleUser  = "user"  // 普通用户
+	RoleAdmin = "admin" // 管理员（可查看全局审计日志）
+)
+
 // User 用户模型
 type User struct {
-	gorm.Model        // 继承 ID/CreatedAt/UpdatedAt/DeletedAt
-	Username   string `gorm:"type:varchar(50);unique;not null;comment:'用户名'"`
-	Password   string `gorm:"type:varchar(255);not null;comment:'密码（bcrypt加密）'"`
-	Email      string `gorm:"type:varchar(100);unique;not null;comment:'邮箱'"`
-	Notes      []Note `gorm:"foreignKey:UserID;references:ID;comment:'关联的笔记'"` // 一对多
+	gorm.Model            // 继承 ID/CreatedAt/UpdatedAt/DeletedAt
+	Username      string  `gorm:"type:varchar(50);unique;not null;comment:'用户名'"`
+	Password      string  `gorm:"type:varchar(255);not null;comment:'密码（bcrypt加密）'"`
+	Email         string  `gorm:"type:varchar(100);unique;not null;comment:'邮箱'"`
+	Role          string  `gorm:"type:varchar(20);default:'user';comment:'角色（user/admin）'"`
+	CalendarToken *string `gorm:"type:varchar(64);uniqueIndex;comment:'日历订阅令牌（未生成时为 NULL）'"`
+	Notes         []Note  `gorm:"foreignKey:UserID;references:ID;comment:'关联的笔记'"` // 一对多
 }
 
 // BeforeSave 保存前加密密码（钩子函数）
@@ -0,0 +1,48 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Webhook 事件类型
+const (
+	WebhookEventNoteCreated = "note.created" // 创建笔记
+	WebhookEventNoteUpdated = "note.updated" // 更新笔记
+	WebhookEventNoteDeleted = "note.deleted" // 删除笔记
+	WebhookEventPing        = "ping"         // 测试推送
+)
+
+// Webhook 投递状态
+const (
+	DeliveryStatusPending    = "pending"    // 等待投递/重试中
+	DeliveryStatusDelivering = "delivering" // 投递中（已被投递协程领取）
+	DeliveryStatusSuccess    = "success"    // 投递成功
+	DeliveryStatusFailed     = "failed"     // 超过重试次数，放弃投递
+)
+
+// Webhook 用户配置的外发 Webhook 订阅
+type Webhook struct {
+	gorm.Model        // 继承 ID/CreatedAt/UpdatedAt/DeletedAt
+	UserID     uint   `gorm:"not null;index;comment:'所属用户ID'"`
+	URL        string `gorm:"type:varchar(500);not null;comment:'推送地址'"`
+	Secret     string `gorm:"type:varchar(64);not null;comment:'签名密钥'" json:"-"` // 只在创建时返回一次
+	Events     string `gorm:"type:varchar(255);not null;comment:'订阅事件（逗号分隔）'"`
+	TagFilter  string `gorm:"type:varchar(255);comment:'标签过滤（逗号分隔，为空不过滤）'"`
+	Enabled    bool   `gorm:"default:true;comment:'是否启用'"`
+}
+
+// WebhookDelivery Webhook 投递记录
+type WebhookDelivery struct {
+	gorm.Model              // 继承 ID/CreatedAt/UpdatedAt/DeletedAt
+	WebhookID    uint       `gorm:"not null;index;comment:'Webhook ID'"`
+	UserID       uint       `gorm:"not null;index;comment:'所属用户ID'"`
+	Event        string     `gorm:"type:varchar(30);not null;comment:'事件类型'"`
+	Payload      string     `gorm:"type:text;not null;comment:'推送内容（JSON）'"`
+	Status       string     `gorm:"type:varchar(20);not null;index;comment:'投递状态'"`
+	Attempts     int        `gorm:"default:0;comment:'已尝试次数'"`
+	ResponseCode int        `gorm:"comment:'最近一次响应码'"`
+	Error        string     `gorm:"type:varchar(255);comment:'最近一次错误'"`
+	NextRetryAt  *time.Time `gorm:"comment:'下次重试时间'"`
+}
@@ -0,0 +1,77 @@
+package service
+
+import (
+	"errors"
+	"os"
+
+	"github.com/JokerYuan-lang/MyNoteBook/internal/model"
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/errcode"
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/storage"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// AttachmentService 笔记附件业务逻辑
+type AttachmentService struct {
+	db      *gorm.DB
+	storage *storage.LocalStorage
+}
+
+// NewAttachmentService 创建 AttachmentService 实例
+func NewAttachmentService(db *gorm.DB, storage *storage.LocalStorage) *AttachmentService {
+	return &AttachmentService{db: db, storage: storage}
+}
+
+// SaveAttachment 保存附件文件并关联到笔记
+func (s *AttachmentService) SaveAttachment(userID, noteID uint, fileName, mimeType string, data []byte) (*model.Attachment, error) {
+	hash, err := s.storage.Save(data)
+	if err != nil {
+		zap.S().Errorf("保存附件文件失败: %v", err)
+		return nil, errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+
+	attachment := model.Attachment{
+		UserID:   userID,
+		NoteID:   noteID,
+		FileName: fileName,
+		MimeType: mimeType,
+		Size:     int64(len(data)),
+		Hash:     hash,
+	}
+	if err := s.db.Create(&attachment).Error; err != nil {
+		zap.S().Errorf("创建附件记录失败: %v", err)
+		return nil, errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+	return &attachment, nil
+}
+
+// GetAttachmentList 查询笔记的附件列表
+func (s *AttachmentService) GetAttachmentList(userID, noteID uint) ([]model.Attachment, error) {
+	var attachments []model.Attachment
+	err := s.db.Where("user_id = ? AND note_id = ?", userID, noteID).Order("id").Find(&attachments).Error
+	if err != nil {
+		zap.S().Errorf("查询附件列表失败: %v", err)
+		return nil, errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+	return attachments, nil
+}
+
+// OpenAttachment 按文件哈希打开当前用户的附件（笔记中以 attachment://<hash> 引用）
+func (s *AttachmentService) OpenAttachment(userID uint, hash string) (*model.Attachment, *os.File, error) {
+	var attachment model.Attachment
+	err := s.db.Where("user_id = ? AND hash = ?", userID, hash).First(&attachment).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, errors.New(errcode.GetMsg(errcode.NotFound))
+		}
+		zap.S().Errorf("查询附件失败: %v", err)
+		return nil, nil, errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+
+	f, err := s.storage.Open(attachment.Hash)
+	if err != nil {
+		zap.S().Errorf("打开附件文件失败: %v", err)
+		return nil, nil, errors.New(errcode.GetMsg(errcode.NotFound))
+	}
+	return &attachment, f, nil
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/JokerYuan-lang/MyNoteBook/internal/model"
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/errcode"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// AuditFilter 审计日志筛选条件（零值表示不筛选）
+type AuditFilter struct {
+	ActorID    uint
+	Action     string
+	TargetType string
+	IP         string
+	StartTime  time.Time
+	EndTime    time.Time
+}
+
+// AuditService 审计日志业务逻辑
+type AuditService struct {
+	db *gorm.DB
+}
+
+// NewAuditService 创建 AuditService 实例
+func NewAuditService(db *gorm.DB) *AuditService {
+	return &AuditService{db: db}
+}
+
+// Record 写入一条审计日志（失败只记录错误，不影响业务）
+func (s *AuditService) Record(log *model.AuditLog) {
+	if err := s.db.Create(log).Error; err != nil {
+		zap.S().Errorf("写入审计日志失败: %v", err)
+	}
+}
+
+// GetAuditList 分页查询审计日志
+func (s *AuditService) GetAuditList(filter AuditFilter, page, pageSize int) ([]model.AuditLog, int64, error) {
+	var (
+		logs  []model.AuditLog
+		total int64
+	)
+	db := s.filterQuery(filter)
+
+	if err := db.Count(&total).Error; err != nil {
+		zap.S().Errorf("统计审计日志失败: %v", err)
+		return nil, 0, errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+
+	offset := (page - 1) * pageSize
+	if err := db.Offset(offset).Limit(pageSize).Order("id DESC").Find(&logs).Error; err != nil {
+		zap.S().Errorf("查询审计日志失败: %v", err)
+		return nil, 0, errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+
+	return logs, total, nil
+}
+
+// ExportCSV 按筛选条件导出审计日志为 CSV（逐行读取，避免一次性加载）
+func (s *AuditService) ExportCSV(filter AuditFilter, w io.Writer) error {
+	rows, err := s.filterQuery(filter).Order("id ASC").Rows()
+	if err != nil {
+		zap.S().Errorf("导出审计日志失败: %v", err)
+		return errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+	defer rows.Close()
+
+	// 写入 UTF-8 BOM，方便 Excel 正确识别中文
+	if _, err := w.Write([]byte("\xEF\xBB\xBF")); err != nil {
+		return err
+	}
+	writer := csv.NewWriter(w)
+	_ = writer.Write([]string{"ID", "时间", "操作者ID", "操作者", "动作", "对象类型", "对象ID", "IP", "UA", "说明"})
+	for rows.Next() {
+		var log model.AuditLog
+		if err := s.db.ScanRows(rows, &log); err != nil {
+			zap.S().Errorf("读取审计日志失败: %v", err)
+			return errors.New(errcode.GetMsg(errcode.ServerError))
+		}
+		_ = writer.Write([]string{
+			strconv.FormatUint(uint64(log.ID), 10),
+			log.CreatedAt.Format(time.DateTime),
+			strconv.FormatUint(uint64(log.ActorID), 10),
+			csvSafe(log.ActorName),
+			csvSafe(log.Action),
+			csvSafe(log.TargetType),
+			strconv.FormatUint(uint64(log.TargetID), 10),
+			csvSafe(log.IP),
+			csvSafe(log.UserAgent),
+			csvSafe(log.Detail),
+		})
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// csvSafe 防止 CSV 注入：以 = + - @ 制表符或回车开头的内容加 ' 前缀，Excel 不会当作公式执行
+func csvSafe(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+// filterQuery 根据筛选条件构建查询
+func (s *AuditService) filterQuery(filter AuditFilter) *gorm.DB {
+	db := s.db.Model(&model.AuditLog{})
+	if filter.ActorID > 0 {
+		db = db.Where("actor_id = ?", filter.ActorID)
+	}
+	if filter.Action != "" {
+		db = db.Where("action = ?", filter.Action)
+	}
+	if filter.TargetType != "" {
+		db = db.Where("target_type = ?", filter.TargetType)
+	}
+	if filter.IP != "" {
+		db = db.Where("ip = ?", filter.IP)
+	}
+	if !filter.StartTime.IsZero() {
+		db = db.Where("created_at >= ?", filter.StartTime)
+	}
+	if !filter.EndTime.IsZero() {
+		db = db.Where("created_at < ?", filter.EndTime)
+	}
+	return db
+}
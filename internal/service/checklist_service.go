@@ -0,0 +1,140 @@
+package service
+
+import (
+	"errors"
+
+	"github.com/JokerYuan-lang/MyNoteBook/internal/model"
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/errcode"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ChecklistService 笔记清单业务逻辑
+type ChecklistService struct {
+	db *gorm.DB
+}
+
+// NewChecklistService 创建 ChecklistService 实例
+func NewChecklistService(db *gorm.DB) *ChecklistService {
+	return &ChecklistService{db: db}
+}
+
+// AddItem 在笔记末尾添加清单项
+func (s *ChecklistService) AddItem(userID, noteID uint, text string) (*model.ChecklistItem, error) {
+	// 1. 检查笔记是否存在（且属于当前用户）
+	var note model.Note
+	err := s.db.Select("id").Where("user_id = ? AND id = ?", userID, noteID).First(&note).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New(errcode.GetMsg(errcode.NotFound))
+		}
+		zap.S().Errorf("查询笔记失败: %v", err)
+		return nil, errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+
+	// 2. 追加到最后
+	var maxPosition int
+	err = s.db.Model(&model.ChecklistItem{}).Where("note_id = ?", noteID).
+		Select("COALESCE(MAX(position), 0)").Scan(&maxPosition).Error
+	if err != nil {
+		zap.S().Errorf("查询清单位置失败: %v", err)
+		return nil, errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+
+	item := model.ChecklistItem{
+		NoteID:   noteID,
+		UserID:   userID,
+		Text:     text,
+		Position: maxPosition + 1,
+	}
+	if err := s.db.Create(&item).Error; err != nil {
+		zap.S().Errorf("创建清单项失败: %v", err)
+		return nil, errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+	return &item, nil
+}
+
+// ToggleItem 切换清单项完成状态（done 为 nil 时取反）
+func (s *ChecklistService) ToggleItem(userID, itemID uint, done *bool) (*model.ChecklistItem, error) {
+	item, err := s.getItem(userID, itemID)
+	if err != nil {
+		return nil, err
+	}
+	if done != nil {
+		item.Done = *done
+	} else {
+		item.Done = !item.Done
+	}
+	if err := s.db.Model(item).Update("done", item.Done).Error; err != nil {
+		zap.S().Errorf("更新清单项失败: %v", err)
+		return nil, errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+	return item, nil
+}
+
+// ReorderItems 按 itemIDs 的顺序重排笔记的清单项（必须包含该笔记的全部清单项）
+func (s *ChecklistService) ReorderItems(userID, noteID uint, itemIDs []uint) error {
+	var items []model.ChecklistItem
+	if err := s.db.Where("user_id = ? AND note_id = ?", userID, noteID).Find(&items).Error; err != nil {
+		zap.S().Errorf("查询清单项失败: %v", err)
+		return errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+	if len(items) == 0 {
+		return errors.New(errcode.GetMsg(errcode.NotFound))
+	}
+
+	exist := make(map[uint]bool, len(items))
+	for _, item := range items {
+		exist[item.ID] = true
+	}
+	if len(itemIDs) != len(items) {
+		return errors.New("清单项数量不一致")
+	}
+	for _, id := range itemIDs {
+		if !exist[id] {
+			return errors.New("清单项不属于该笔记")
+		}
+		delete(exist, id) // 防止重复ID
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		for i, id := range itemIDs {
+			if err := tx.Model(&model.ChecklistItem{}).Where("id = ?", id).Update("position", i+1).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		zap.S().Errorf("清单排序失败: %v", err)
+		return errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+	return nil
+}
+
+// DeleteItem 删除清单项
+func (s *ChecklistService) DeleteItem(userID, itemID uint) error {
+	item, err := s.getItem(userID, itemID)
+	if err != nil {
+		return err
+	}
+	if err := s.db.Delete(item).Error; err != nil {
+		zap.S().Errorf("删除清单项失败: %v", err)
+		return errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+	return nil
+}
+
+// getItem 查询属于当前用户的清单项
+func (s *ChecklistService) getItem(userID, itemID uint) (*model.ChecklistItem, error) {
+	var item model.ChecklistItem
+	err := s.db.Where("user_id = ? AND id = ?", userID, itemID).First(&item).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New(errcode.GetMsg(errcode.NotFound))
+		}
+		zap.S().Errorf("查询清单项失败: %v", err)
+		return nil, errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+	return &item, nil
+}
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/JokerYuan-lang/MyNoteBook/internal/importer"
+	"github.com/JokerYuan-lang/MyNoteBook/internal/model"
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/render"
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/storage"
+	"go.uber.org/zap"
+)
+
+const (
+	clipCategory         = "网页剪藏"           // 未指定分类时使用的分类
+	clipMaxImageSize     = 5 << 20          // 单张图片最大 5MB
+	clipDownloadTimeout  = 30 * time.Second // 全部图片的下载总时长
+	clipDownloadParallel = 5                // 同时下载的图片数
+)
+
+// ClipService 网页剪藏业务逻辑
+type ClipService struct {
+	noteService       *NoteService
+	attachmentService *AttachmentService
+	client            *http.Client
+}
+
+// NewClipService 创建 ClipService 实例
+func NewClipService(noteService *NoteService, attachmentService *AttachmentService) *ClipService {
+	return &ClipService{
+		noteService:       noteService,
+		attachmentService: attachmentService,
+		client:            newPublicHTTPClient(15*time.Second, nil),
+	}
+}
+
+// ClipPage 把网页剪藏为笔记：提取正文转为 Markdown，下载正文图片为附件，以来源域名为标签，返回新笔记ID
+// 图片并发下载且总时长有上限，下载失败或超时的图片保留原地址，不影响剪藏
+// 笔记和附件记录在同一事务中写入，附件保存失败时整体回滚，@ 通知和 Webhook 在提交后才发送
+func (s *ClipService) ClipPage(userID uint, pageURL, pageHTML, category string, tagNames []string) (uint, error) {
+	u, err := url.Parse(pageURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return 0, errors.New("来源地址格式错误")
+	}
+	note, images, err := importer.ParseWebPage(pageHTML, u)
+	if err != nil {
+		return 0, err
+	}
+
+	// 1. 下载图片，正文中的图片地址改为附件引用
+	var attachments []importer.Attachment
+	for i, att := range s.downloadImages(images) {
+		if att == nil {
+			continue
+		}
+		note.Content = strings.ReplaceAll(note.Content, "]("+images[i]+")", "]("+model.AttachmentScheme+storage.Hash(att.Data)+")")
+		attachments = append(attachments, *att)
+	}
+
+	// 2. 在事务中创建笔记并保存附件（回滚时已写入存储的文件按哈希去重，留给之后相同内容的附件复用）
+	if category == "" {
+		category = clipCategory
+	}
+	var noteID uint
+	err = s.noteService.Transaction(func(tx *NoteService) error {
+		var err error
+		noteID, err = tx.CreateNote(userID, note.Title, note.Content, render.FormatMarkdown, category, append(note.Tags, tagNames...))
+		if err != nil {
+			return err
+		}
+		attachmentService := &AttachmentService{db: tx.db, storage: s.attachmentService.storage}
+		for _, att := range attachments {
+			if _, err := attachmentService.SaveAttachment(userID, noteID, att.FileName, att.MimeType, att.Data); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return noteID, nil
+}
+
+// downloadImages 在同一期限内并发下载图片，结果与 srcs 一一对应（失败的为 nil）
+func (s *ClipService) downloadImages(srcs []string) []*importer.Attachment {
+	ctx, cancel := context.WithTimeout(context.Background(), clipDownloadTimeout)
+	defer cancel()
+
+	results := make([]*importer.Attachment, len(srcs))
+	sem := make(chan struct{}, clipDownloadParallel)
+	var wg sync.WaitGroup
+	for i, src := range srcs {
+		wg.Add(1)
+		go func(i int, src string) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				zap.S().Warnf("剪藏图片下载超时 %s", src)
+				return
+			}
+			att, err := s.downloadImage(ctx, src)
+			if err != nil {
+				zap.S().Warnf("剪藏图片下载失败 %s: %v", src, err)
+				return
+			}
+			results[i] = att
+		}(i, src)
+	}
+	wg.Wait()
+	return results
+}
+
+// downloadImage 下载图片（只接受 image/* 且不超过大小限制）
+func (s *ClipService) downloadImage(ctx context.Context, src string) (*importer.Attachment, error) {
+	u, err := url.Parse(src)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return nil, errors.New("不支持的图片地址")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, src, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("状态码 %d", resp.StatusCode)
+	}
+	mimeType := strings.TrimSpace(strings.Split(resp.Header.Get("Content-Type"), ";")[0])
+	if !strings.HasPrefix(mimeType, "image/") {
+		return nil, fmt.Errorf("不是图片: %s", mimeType)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, clipMaxImageSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > clipMaxImageSize {
+		return nil, errors.New("图片超过 5MB")
+	}
+
+	fileName := path.Base(u.Path)
+	if fileName == "." || fileName == "/" {
+		fileName = "image"
+	}
+	return &importer.Attachment{FileName: fileName, MimeType: mimeType, Data: data}, nil
+}
@@ -0,0 +1,408 @@
+package service
+
+import (
+	"errors"
+	"html"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/JokerYuan-lang/MyNoteBook/internal/model"
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/errcode"
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/render"
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/simhash"
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/wikilink"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// DuplicateService 重复笔记查找和合并
+type DuplicateService struct {
+	db          *gorm.DB
+	noteService *NoteService
+}
+
+// NewDuplicateService 创建 DuplicateService 实例
+func NewDuplicateService(db *gorm.DB, noteService *NoteService) *DuplicateService {
+	return &DuplicateService{db: db, noteService: noteService}
+}
+
+// GetDuplicateClusters 按内容指纹查找近似重复的笔记组（指纹汉明距离不超过 maxDistance 的笔记归为一组）
+func (s *DuplicateService) GetDuplicateClusters(userID uint, maxDistance int) ([]model.DuplicateCluster, error) {
+	clusters := []model.DuplicateCluster{}
+
+	// 1. 用户全部笔记的指纹（没有分词的空笔记不参与）
+	var notes []struct {
+		NoteID    uint
+		Title     string
+		Category  string
+		UpdatedAt time.Time
+		SimHash   uint64
+	}
+	err := s.db.Model(&model.NoteFingerprint{}).
+		Select("note_fingerprints.note_id, notes.title, notes.category, notes.updated_at, note_fingerprints.sim_hash").
+		Joins("JOIN notes ON notes.id = note_fingerprints.note_id AND notes.deleted_at IS NULL").
+		Where("note_fingerprints.user_id = ? AND note_fingerprints.features > 0", userID).
+		Order("notes.updated_at DESC, notes.id DESC").
+		Scan(&notes).Error
+	if err != nil {
+		zap.S().Errorf("查询内容指纹失败: %v", err)
+		return nil, errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+
+	// 2. 按指纹距离分组：组内按更新时间倒序，第一条（最近更新）作为建议的合并目标
+	hashes := make([]uint64, len(notes))
+	for i, note := range notes {
+		hashes[i] = note.SimHash
+	}
+	for _, members := range clusterFingerprints(hashes, maxDistance) {
+		cluster := model.DuplicateCluster{Similarity: 1}
+		first := notes[members[0]]
+		for _, i := range members {
+			similarity := math.Round(simhash.Similarity(first.SimHash, notes[i].SimHash)*1000) / 1000
+			cluster.Similarity = math.Min(cluster.Similarity, similarity)
+			cluster.Notes = append(cluster.Notes, model.DuplicateNote{
+				NoteID:     notes[i].NoteID,
+				Title:      notes[i].Title,
+				Category:   notes[i].Category,
+				UpdatedAt:  notes[i].UpdatedAt,
+				Similarity: similarity,
+			})
+		}
+		clusters = append(clusters, cluster)
+	}
+
+	// 笔记多的组在前，同样多时相似度高的在前
+	sort.SliceStable(clusters, func(i, j int) bool {
+		if len(clusters[i].Notes) != len(clusters[j].Notes) {
+			return len(clusters[i].Notes) > len(clusters[j].Notes)
+		}
+		return clusters[i].Similarity > clusters[j].Similarity
+	})
+	return clusters, nil
+}
+
+// clusterFingerprints 把汉明距离不超过 maxDistance 的指纹（可经由组内其他指纹间接相连）归为一组，
+// 返回至少两条的组（元素为 hashes 的下标，组和组内下标都按首次出现的顺序）。
+// 指纹切成 maxDistance+1 段按段分桶，同桶的指纹再核对距离，用并查集合并成组
+func clusterFingerprints(hashes []uint64, maxDistance int) [][]int {
+	parent := make([]int, len(hashes))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(i int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	type bucketKey struct {
+		band  int
+		value uint64
+	}
+	buckets := make(map[bucketKey][]int)
+	for i, hash := range hashes {
+		for band, value := range simhash.Bands(hash, maxDistance+1) {
+			key := bucketKey{band: band, value: value}
+			for _, j := range buckets[key] {
+				if find(i) != find(j) && simhash.Distance(hash, hashes[j]) <= maxDistance {
+					parent[find(i)] = find(j)
+				}
+			}
+			buckets[key] = append(buckets[key], i)
+		}
+	}
+
+	groups := make(map[int][]int)
+	var roots []int
+	for i := range hashes {
+		root := find(i)
+		if _, ok := groups[root]; !ok {
+			roots = append(roots, root)
+		}
+		groups[root] = append(groups[root], i)
+	}
+	var clusters [][]int
+	for _, root := range roots {
+		if len(groups[root]) >= 2 {
+			clusters = append(clusters, groups[root])
+		}
+	}
+	return clusters
+}
+
+// MergeNotes 把多条笔记合并到目标笔记：内容依次追加到目标笔记后（与已有内容相同的跳过），标签取并集，
+// 附件和清单项移到目标笔记，其他笔记中按 ID 指向被合并笔记的链接改为指向目标笔记，被合并的笔记随后删除并放入合并回收站（可用 RestoreMergedNote 恢复）
+// 全部修改在一个事务中完成
+func (s *DuplicateService) MergeNotes(userID, targetID uint, sourceIDs []uint) error {
+	// 1. 查询目标笔记和被合并的笔记
+	var ids []uint
+	seen := map[uint]bool{targetID: true}
+	for _, id := range sourceIDs {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return errors.New("请选择要合并的笔记")
+	}
+
+	var target model.Note
+	err := s.db.Where("user_id = ? AND id = ?", userID, targetID).Preload("Tags").First(&target).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New(errcode.GetMsg(errcode.NotFound))
+		}
+		zap.S().Errorf("查询笔记失败: %v", err)
+		return errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+	var sources []model.Note
+	err = s.db.Where("user_id = ? AND id IN ?", userID, ids).Preload("Tags").Order("updated_at ASC, id ASC").Find(&sources).Error
+	if err != nil {
+		zap.S().Errorf("查询笔记失败: %v", err)
+		return errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+	if len(sources) != len(ids) {
+		return errors.New(errcode.GetMsg(errcode.NotFound))
+	}
+
+	// 2. 合并内容和标签
+	content := mergeContent(&target, sources)
+	var tagNames []string
+	for _, note := range append([]model.Note{target}, sources...) {
+		for _, tag := range note.Tags {
+			tagNames = append(tagNames, tag.Name)
+		}
+	}
+
+	// 3. 在一个事务中完成合并：任何一步失败都整体回滚
+	return s.noteService.Transaction(func(tx *NoteService) error {
+		// 附件和清单项移到目标笔记（清单项排在目标笔记原有清单项之后）
+		if err := tx.db.Model(&model.Attachment{}).Where("user_id = ? AND note_id IN ?", userID, ids).
+			Update("note_id", target.ID).Error; err != nil {
+			zap.S().Errorf("移动附件失败: %v", err)
+			return errors.New(errcode.GetMsg(errcode.ServerError))
+		}
+		var maxPosition int
+		if err := tx.db.Model(&model.ChecklistItem{}).Select("COALESCE(MAX(position), 0)").
+			Where("note_id = ?", target.ID).Scan(&maxPosition).Error; err != nil {
+			zap.S().Errorf("查询清单项失败: %v", err)
+			return errors.New(errcode.GetMsg(errcode.ServerError))
+		}
+		if err := tx.db.Model(&model.ChecklistItem{}).Where("note_id IN ?", ids).UpdateColumns(map[string]interface{}{
+			"note_id":  target.ID,
+			"position": gorm.Expr("position + ?", maxPosition+1),
+		}).Error; err != nil {
+			zap.S().Errorf("移动清单项失败: %v", err)
+			return errors.New(errcode.GetMsg(errcode.ServerError))
+		}
+
+		// 其他笔记中按 ID 指向被合并笔记的链接改为指向目标笔记
+		targets := make(map[uint]uint, len(ids))
+		for _, id := range ids {
+			targets[id] = target.ID
+		}
+		if err := retargetLinks(tx, userID, targets); err != nil {
+			return err
+		}
+
+		// 更新目标笔记（同时重建链接、索引，提交后推送 Webhook），再删除被合并的笔记
+		content = wikilink.Retarget(content, targets)
+		if err := tx.UpdateNote(userID, target.ID, target.Title, content, target.ContentFormat, target.Category, tagNames); err != nil {
+			return err
+		}
+		for _, note := range sources {
+			if err := tx.DeleteNote(userID, note.ID); err != nil {
+				return err
+			}
+		}
+
+		// 被合并的笔记放入回收站（保存合并前的标签，删除时已清空关联）
+		trash := make([]model.MergedNote, 0, len(sources))
+		for _, note := range sources {
+			names := make([]string, 0, len(note.Tags))
+			for _, tag := range note.Tags {
+				names = append(names, tag.Name)
+			}
+			trash = append(trash, model.MergedNote{UserID: userID, NoteID: note.ID, TargetID: target.ID, TagNames: strings.Join(names, "\n")})
+		}
+		if err := tx.db.Create(&trash).Error; err != nil {
+			zap.S().Errorf("保存合并回收站失败: %v", err)
+			return errors.New(errcode.GetMsg(errcode.ServerError))
+		}
+		return nil
+	})
+}
+
+// GetMergedNotes 合并回收站中的笔记（按合并时间倒序）
+func (s *DuplicateService) GetMergedNotes(userID uint) ([]model.MergedNoteItem, error) {
+	items := []model.MergedNoteItem{}
+	err := s.db.Model(&model.MergedNote{}).
+		Select("merged_notes.note_id, notes.title, notes.category, merged_notes.target_id, merged_notes.created_at AS merged_at").
+		Joins("JOIN notes ON notes.id = merged_notes.note_id").
+		Where("merged_notes.user_id = ?", userID).
+		Order("merged_notes.created_at DESC, merged_notes.id DESC").
+		Scan(&items).Error
+	if err != nil {
+		zap.S().Errorf("查询合并回收站失败: %v", err)
+		return nil, errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+	return items, nil
+}
+
+// RestoreMergedNote 从合并回收站恢复笔记：取消软删除，重新关联合并前的标签，重建链接、搜索索引和内容指纹
+// 合并时移到目标笔记的附件、清单项和改写过的 ID 链接保留在目标笔记，不随之恢复
+func (s *DuplicateService) RestoreMergedNote(userID, noteID uint) error {
+	var merged model.MergedNote
+	err := s.db.Where("user_id = ? AND note_id = ?", userID, noteID).First(&merged).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New(errcode.GetMsg(errcode.NotFound))
+		}
+		zap.S().Errorf("查询合并回收站失败: %v", err)
+		return errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+
+	return s.noteService.Transaction(func(tx *NoteService) error {
+		// 1. 取消软删除，移出回收站
+		if err := tx.db.Unscoped().Model(&model.Note{}).Where("user_id = ? AND id = ?", userID, noteID).
+			Update("deleted_at", nil).Error; err != nil {
+			zap.S().Errorf("恢复笔记失败: %v", err)
+			return errors.New(errcode.GetMsg(errcode.ServerError))
+		}
+		if err := tx.db.Delete(&merged).Error; err != nil {
+			zap.S().Errorf("删除合并回收站记录失败: %v", err)
+			return errors.New(errcode.GetMsg(errcode.ServerError))
+		}
+		var note model.Note
+		if err := tx.db.Where("user_id = ? AND id = ?", userID, noteID).First(&note).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New(errcode.GetMsg(errcode.NotFound))
+			}
+			zap.S().Errorf("查询笔记失败: %v", err)
+			return errors.New(errcode.GetMsg(errcode.ServerError))
+		}
+
+		// 2. 重新关联合并前的标签
+		var tagNames []string
+		if merged.TagNames != "" {
+			tagNames = strings.Split(merged.TagNames, "\n")
+		}
+		tags, err := tx.findOrCreateTags(userID, tagNames)
+		if err != nil {
+			return err
+		}
+		if err := tx.db.Model(&note).Association("Tags").Replace(&tags); err != nil {
+			zap.S().Errorf("关联标签失败: %v", err)
+			return errors.New(errcode.GetMsg(errcode.ServerError))
+		}
+
+		// 3. 按新建笔记重建链接（关联指向该标题的断链）和搜索索引，标记词向量待重算
+		if err := tx.syncLinks(&note, ""); err != nil {
+			return err
+		}
+		if err := tx.indexService.IndexNote(&note, tagNames); err != nil {
+			return err
+		}
+		if err := tx.relatedService.MarkStale(userID); err != nil {
+			return err
+		}
+
+		// 4. 推送 Webhook（按新建笔记推送）
+		tx.afterCommit(func() { tx.webhookService.Dispatch(model.WebhookEventNoteCreated, &note, tagNames) })
+		return nil
+	})
+}
+
+// retargetLinks 改写按 ID 链接到 targets 键的笔记内容（不修改更新时间），并重建这些笔记的链接和索引
+// 链接来源本身是合并的笔记时跳过（它们的内容由合并处理）
+func retargetLinks(tx *NoteService, userID uint, targets map[uint]uint) error {
+	ids := make([]uint, 0, len(targets))
+	exclude := make([]uint, 0, len(targets)*2)
+	for id, to := range targets {
+		ids = append(ids, id)
+		exclude = append(exclude, id, to)
+	}
+	var sourceIDs []uint
+	err := tx.db.Model(&model.NoteLink{}).Distinct("source_note_id").
+		Where("user_id = ? AND target_note_id IN ? AND target_title = ''", userID, ids).
+		Pluck("source_note_id", &sourceIDs).Error
+	if err != nil {
+		zap.S().Errorf("查询反链失败: %v", err)
+		return errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+
+	var notes []model.Note
+	if len(sourceIDs) > 0 {
+		err = tx.db.Select("id, user_id, title, content, content_format").
+			Where("user_id = ? AND id IN ? AND id NOT IN ?", userID, sourceIDs, exclude).Find(&notes).Error
+		if err != nil {
+			zap.S().Errorf("查询链接笔记失败: %v", err)
+			return errors.New(errcode.GetMsg(errcode.ServerError))
+		}
+	}
+	noteIDs := make([]uint, 0, len(notes))
+	for i := range notes {
+		notes[i].Content = wikilink.Retarget(notes[i].Content, targets)
+		if err := prepareContent(&notes[i]); err != nil {
+			return err
+		}
+		err := tx.db.Model(&model.Note{}).Where("id = ?", notes[i].ID).UpdateColumns(map[string]interface{}{
+			"content":       notes[i].Content,
+			"rendered_html": notes[i].RenderedHTML,
+			"plain_text":    notes[i].PlainText,
+		}).Error
+		if err != nil {
+			zap.S().Errorf("改写笔记链接失败: %v", err)
+			return errors.New(errcode.GetMsg(errcode.ServerError))
+		}
+		if err := tx.linkService.SyncLinks(&notes[i]); err != nil {
+			return err
+		}
+		noteIDs = append(noteIDs, notes[i].ID)
+	}
+	return tx.indexService.ReindexNotes(noteIDs)
+}
+
+// mergeContent 把被合并笔记的内容依次追加到目标笔记内容后：纯文本与已有内容相同的跳过，
+// 格式与目标笔记不同时追加纯文本
+func mergeContent(target *model.Note, sources []model.Note) string {
+	seen := map[string]bool{normalizedPlain(target): true}
+	parts := []string{target.Content}
+	for i := range sources {
+		source := &sources[i]
+		key := normalizedPlain(source)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		content := source.Content
+		if source.ContentFormat != target.ContentFormat {
+			content = key
+			if target.ContentFormat == render.FormatHTML {
+				content = "<p>" + html.EscapeString(content) + "</p>"
+			}
+		}
+		parts = append(parts, content)
+	}
+
+	separator := "\n\n---\n\n"
+	if target.ContentFormat == render.FormatHTML {
+		separator = "\n<hr>\n"
+	}
+	return strings.Join(parts, separator)
+}
+
+// normalizedPlain 笔记纯文本（合并连续空白；没有纯文本缓存时用原始内容）
+func normalizedPlain(note *model.Note) string {
+	plain := note.Content
+	if note.PlainText != nil {
+		plain = *note.PlainText
+	}
+	return strings.Join(strings.Fields(plain), " ")
+}
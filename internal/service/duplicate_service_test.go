@@ -0,0 +1,69 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/JokerYuan-lang/MyNoteBook/internal/model"
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/render"
+)
+
+func TestClusterFingerprints(t *testing.T) {
+	hashes := []uint64{
+		0,            // 0
+		0b111,        // 1：与 0 距离 3
+		1 << 63,      // 2：与 0 距离 1
+		^uint64(0),   // 3：与其他都远
+		0b111 << 3,   // 4：与 1 距离 6，与 0 距离 3
+		0xFFFF << 20, // 5：单独一条
+	}
+	tests := []struct {
+		maxDistance int
+		want        [][]int
+	}{
+		{1, [][]int{{0, 2}}},
+		{3, [][]int{{0, 1, 2, 4}}},
+		{0, nil},
+	}
+	for _, tt := range tests {
+		if got := clusterFingerprints(hashes, tt.maxDistance); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("clusterFingerprints(maxDistance=%d) = %v, want %v", tt.maxDistance, got, tt.want)
+		}
+	}
+	if got := clusterFingerprints([]uint64{5, 5}, 0); !reflect.DeepEqual(got, [][]int{{0, 1}}) {
+		t.Errorf("identical fingerprints = %v, want [[0 1]]", got)
+	}
+}
+
+func TestMergeContent(t *testing.T) {
+	plain := func(s string) *string { return &s }
+	target := &model.Note{Content: "# 周报\n\n进度正常", ContentFormat: render.FormatMarkdown, PlainText: plain("周报 进度正常")}
+	tests := []struct {
+		name    string
+		target  *model.Note
+		sources []model.Note
+		want    string
+	}{
+		{"追加", target, []model.Note{
+			{Content: "补充内容", ContentFormat: render.FormatMarkdown},
+		}, "# 周报\n\n进度正常\n\n---\n\n补充内容"},
+		{"与目标相同的跳过", target, []model.Note{
+			{Content: "周报\n\n进度正常", ContentFormat: render.FormatMarkdown, PlainText: plain("周报  进度正常")},
+			{Content: "a", ContentFormat: render.FormatMarkdown},
+			{Content: "*a*", ContentFormat: render.FormatMarkdown, PlainText: plain("a")},
+		}, "# 周报\n\n进度正常\n\n---\n\na"},
+		{"格式不同时追加纯文本", target, []model.Note{
+			{Content: "<p>网页</p>", ContentFormat: render.FormatHTML, PlainText: plain("网页")},
+		}, "# 周报\n\n进度正常\n\n---\n\n网页"},
+		{"HTML 目标转义纯文本", &model.Note{Content: "<p>x</p>", ContentFormat: render.FormatHTML, PlainText: plain("x")}, []model.Note{
+			{Content: "a < b", ContentFormat: render.FormatPlain},
+		}, "<p>x</p>\n<hr>\n<p>a &lt; b</p>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mergeContent(tt.target, tt.sources); got != tt.want {
+				t.Errorf("mergeContent() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,517 @@
+package service
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/JokerYuan-lang/MyNoteBook/internal/config"
+	"github.com/JokerYuan-lang/MyNoteBook/internal/model"
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/embedding"
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/errcode"
+	"github.com/go-redis/redis/v8"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	embeddingLockKey   = "lock:note_embeddings" // 向量计算锁（多实例只有一个在计算）
+	embeddingLockTTL   = 10 * time.Minute
+	embeddingRunLimit  = 8 * time.Minute // 单次计算的时长上限（短于锁有效期，未处理完的留到下次）
+	embeddingInterval  = time.Minute
+	embeddingBatchSize = 16   // 每次请求向量提供方的笔记数
+	embeddingMaxRunes  = 2000 // 参与计算的文本最大长度（字），超出截断
+	semanticCandidates = 100  // 语义、关键词各取前若干条作为候选
+
+	defaultEmbeddingCacheVectors = 100000 // 默认最多缓存的向量数
+)
+
+// DefaultSemanticBias 综合得分中语义相似度的默认权重
+const DefaultSemanticBias = 0.7
+
+// EmbeddingService 语义搜索：后台为笔记计算语义向量，搜索时按余弦相似度（逐条比较）与关键词得分综合排序
+type EmbeddingService struct {
+	db             *gorm.DB
+	rdb            *redis.Client
+	provider       embedding.Provider
+	indexService   *IndexService
+	relatedService *RelatedService
+
+	cache *vectorCache // 按用户缓存向量，向量数量或最后更新时间变化时重新加载
+}
+
+// userVectors 一个用户的全部笔记向量
+type userVectors struct {
+	userID  uint
+	count   int64
+	latest  time.Time
+	noteIDs []uint
+	vectors [][]float32
+}
+
+// vectorCache 按用户缓存笔记向量，总向量数超过上限时淘汰最久未使用的用户
+type vectorCache struct {
+	mu         sync.Mutex
+	maxVectors int
+	size       int                    // 当前缓存的向量数
+	order      *list.List             // 最近使用的在前
+	items      map[uint]*list.Element // 用户ID -> order 中的元素
+}
+
+// newVectorCache 创建向量缓存
+func newVectorCache(maxVectors int) *vectorCache {
+	return &vectorCache{maxVectors: maxVectors, order: list.New(), items: make(map[uint]*list.Element)}
+}
+
+// get 读取用户的缓存并标记为最近使用
+func (c *vectorCache) get(userID uint) (*userVectors, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.items[userID]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(elem)
+	return elem.Value.(*userVectors), true
+}
+
+// put 缓存用户的向量（单个用户超过上限时不缓存），并淘汰超出上限的部分
+func (c *vectorCache) put(user *userVectors) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.items[user.userID]; ok {
+		c.remove(elem)
+	}
+	if len(user.vectors) > c.maxVectors {
+		return
+	}
+	c.items[user.userID] = c.order.PushFront(user)
+	c.size += len(user.vectors)
+	for c.size > c.maxVectors {
+		c.remove(c.order.Back())
+	}
+}
+
+// remove 移除一个用户的缓存（调用方持有锁）
+func (c *vectorCache) remove(elem *list.Element) {
+	user := c.order.Remove(elem).(*userVectors)
+	delete(c.items, user.userID)
+	c.size -= len(user.vectors)
+}
+
+// NewEmbeddingService 创建 EmbeddingService 实例（向量提供方创建失败时退回本地特征哈希并记录警告）
+func NewEmbeddingService(db *gorm.DB, rdb *redis.Client, conf config.EmbeddingConfig, indexService *IndexService, relatedService *RelatedService) *EmbeddingService {
+	provider, err := embedding.New(embedding.Config{
+		Provider:  conf.Provider,
+		URL:       conf.URL,
+		APIKey:    conf.APIKey,
+		Model:     conf.Model,
+		Dimension: conf.Dimension,
+		Timeout:   time.Duration(conf.Timeout) * time.Second,
+	})
+	if err != nil {
+		zap.S().Warnf("创建向量提供方失败，改为本地特征哈希: %v", err)
+		provider = embedding.NewLocal(conf.Dimension)
+	}
+	cacheVectors := conf.CacheVectors
+	if cacheVectors <= 0 {
+		cacheVectors = defaultEmbeddingCacheVectors
+	}
+	return &EmbeddingService{
+		db:             db,
+		rdb:            rdb,
+		provider:       provider,
+		indexService:   indexService,
+		relatedService: relatedService,
+		cache:          newVectorCache(cacheVectors),
+	}
+}
+
+// SemanticSearch 语义搜索：综合得分 = bias * 语义相似度 + (1 - bias) * 关键词得分，bias 为 1 时只按语义、为 0 时只按关键词
+func (s *EmbeddingService) SemanticSearch(userID uint, query string, limit int, bias float64) ([]model.SemanticHit, error) {
+	hits := []model.SemanticHit{}
+
+	// 1. 语义相似度：搜索语句转向量后与用户全部笔记向量逐条比较
+	semantic := make(map[uint]float64)
+	if bias > 0 {
+		vectors, err := s.embed(context.Background(), []string{query})
+		if err != nil {
+			zap.S().Errorf("计算搜索语句向量失败: %v", err)
+			return nil, errors.New(errcode.GetMsg(errcode.ServerError))
+		}
+		user, err := s.userVectors(userID)
+		if err != nil {
+			zap.S().Errorf("加载笔记向量失败: %v", err)
+			return nil, errors.New(errcode.GetMsg(errcode.ServerError))
+		}
+		semantic = topScores(user.noteIDs, func(i int) float64 {
+			return embedding.Dot(vectors[0], user.vectors[i])
+		}, semanticCandidates)
+	}
+
+	// 2. 关键词得分
+	text := make(map[uint]float64)
+	if bias < 1 {
+		var err error
+		if text, err = s.textScores(userID, query); err != nil {
+			return nil, err
+		}
+	}
+
+	// 3. 综合排序
+	scores := make(map[uint]float64, len(semantic)+len(text))
+	for id, score := range semantic {
+		scores[id] += bias * score
+	}
+	for id, score := range text {
+		scores[id] += (1 - bias) * score
+	}
+	noteIDs := make([]uint, 0, len(scores))
+	for id, score := range scores {
+		if score > 0 {
+			noteIDs = append(noteIDs, id)
+		}
+	}
+	sort.Slice(noteIDs, func(i, j int) bool {
+		if scores[noteIDs[i]] != scores[noteIDs[j]] {
+			return scores[noteIDs[i]] > scores[noteIDs[j]]
+		}
+		return noteIDs[i] > noteIDs[j]
+	})
+	if len(noteIDs) > limit {
+		noteIDs = noteIDs[:limit]
+	}
+	if len(noteIDs) == 0 {
+		return hits, nil
+	}
+
+	// 4. 补充笔记信息（已删除的笔记忽略）
+	var notes []model.Note
+	if err := s.db.Select("id, title, category, plain_text").Where("user_id = ? AND id IN ?", userID, noteIDs).Find(&notes).Error; err != nil {
+		zap.S().Errorf("查询笔记失败: %v", err)
+		return nil, errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+	byID := make(map[uint]*model.Note, len(notes))
+	for i := range notes {
+		byID[notes[i].ID] = &notes[i]
+	}
+	round := func(v float64) float64 { return math.Round(v*1000) / 1000 }
+	for _, id := range noteIDs {
+		note, ok := byID[id]
+		if !ok {
+			continue
+		}
+		hit := model.SemanticHit{
+			NoteID:        id,
+			Title:         note.Title,
+			Category:      note.Category,
+			Score:         round(scores[id]),
+			SemanticScore: round(semantic[id]),
+			TextScore:     round(text[id]),
+		}
+		if note.PlainText != nil {
+			hit.Excerpt = excerpt(*note.PlainText)
+		}
+		hits = append(hits, hit)
+	}
+	return hits, nil
+}
+
+// textScores 关键词得分：搜索语句分词的 TF-IDF 权重乘以笔记中该词的词频权重之和，按最高分归一到 0~1
+func (s *EmbeddingService) textScores(userID uint, query string) (map[uint]float64, error) {
+	scores := make(map[uint]float64)
+	counts := s.indexService.TermCounts(query)
+	if len(counts) == 0 {
+		return scores, nil
+	}
+	weights, err := s.relatedService.termWeights(userID, counts)
+	if err != nil {
+		return nil, err
+	}
+	terms := make([]string, 0, len(counts))
+	for term := range counts {
+		terms = append(terms, term)
+	}
+
+	var rows []struct {
+		NoteID uint
+		Term   string
+		Count  int
+	}
+	err = s.db.Model(&model.NoteTerm{}).Select("note_id, term, count").
+		Where("user_id = ? AND field = ? AND term IN ?", userID, model.TermFieldText, terms).Scan(&rows).Error
+	if err != nil {
+		zap.S().Errorf("查询搜索索引失败: %v", err)
+		return nil, errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+	all := make(map[uint]float64)
+	for _, row := range rows {
+		all[row.NoteID] += weights.weights[row.Term] * (1 + math.Log(float64(row.Count)))
+	}
+
+	noteIDs := make([]uint, 0, len(all))
+	for id := range all {
+		noteIDs = append(noteIDs, id)
+	}
+	scores = topScores(noteIDs, func(i int) float64 { return all[noteIDs[i]] }, semanticCandidates)
+	var maxScore float64
+	for _, score := range scores {
+		maxScore = math.Max(maxScore, score)
+	}
+	for id := range scores {
+		scores[id] /= maxScore
+	}
+	return scores, nil
+}
+
+// topScores 计算每条笔记的得分，返回得分大于 0 的前 n 条
+func topScores(noteIDs []uint, score func(i int) float64, n int) map[uint]float64 {
+	type scored struct {
+		noteID uint
+		score  float64
+	}
+	all := make([]scored, 0, len(noteIDs))
+	for i, id := range noteIDs {
+		if v := score(i); v > 0 {
+			all = append(all, scored{noteID: id, score: v})
+		}
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].score > all[j].score })
+	if len(all) > n {
+		all = all[:n]
+	}
+	result := make(map[uint]float64, len(all))
+	for _, item := range all {
+		result[item.noteID] = item.score
+	}
+	return result
+}
+
+// userVectors 加载用户的笔记向量（只取当前提供方计算成功的向量；缓存未变化时直接使用缓存）
+func (s *EmbeddingService) userVectors(userID uint) (*userVectors, error) {
+	var stat struct {
+		Count  int64
+		Latest *time.Time
+	}
+	err := s.db.Model(&model.NoteEmbedding{}).Select("COUNT(*) AS count, MAX(updated_at) AS latest").
+		Where("user_id = ? AND provider = ? AND LENGTH(vector) > 0", userID, s.provider.Name()).Scan(&stat).Error
+	if err != nil {
+		return nil, err
+	}
+	var latest time.Time
+	if stat.Latest != nil {
+		latest = *stat.Latest
+	}
+
+	if cached, ok := s.cache.get(userID); ok && cached.count == stat.Count && cached.latest.Equal(latest) {
+		return cached, nil
+	}
+
+	user := &userVectors{userID: userID, count: stat.Count, latest: latest}
+	rows, err := s.db.Model(&model.NoteEmbedding{}).Select("note_id, vector").
+		Where("user_id = ? AND provider = ? AND LENGTH(vector) > 0", userID, s.provider.Name()).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			noteID uint
+			data   []byte
+		)
+		if err := rows.Scan(&noteID, &data); err != nil {
+			return nil, err
+		}
+		user.noteIDs = append(user.noteIDs, noteID)
+		user.vectors = append(user.vectors, embedding.Decode(data))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	s.cache.put(user)
+	return user, nil
+}
+
+// embed 调用向量提供方计算向量
+func (s *EmbeddingService) embed(ctx context.Context, texts []string) ([][]float32, error) {
+	vectors, err := s.provider.Embed(ctx, texts)
+	if err != nil {
+		return nil, err
+	}
+	if len(vectors) != len(texts) {
+		return nil, fmt.Errorf("向量数量 %d 与文本数量 %d 不一致", len(vectors), len(texts))
+	}
+	return vectors, nil
+}
+
+// Run 定期为新增、修改的笔记计算语义向量（阻塞，应在 goroutine 中调用）
+func (s *EmbeddingService) Run(ctx context.Context) {
+	ticker := time.NewTicker(embeddingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.runOnce(ctx)
+		}
+	}
+}
+
+// runOnce 抢锁后分批计算向量缺失、提供方变化或早于笔记更新时间的笔记，直到处理完、出错或超过时长上限
+func (s *EmbeddingService) runOnce(ctx context.Context) {
+	owner := fmt.Sprintf("%d", time.Now().UnixNano())
+	ok, err := s.rdb.SetNX(ctx, embeddingLockKey, owner, embeddingLockTTL).Result()
+	if err != nil {
+		zap.S().Errorf("获取向量计算锁失败: %v", err)
+		return
+	}
+	if !ok {
+		return // 其他实例正在处理
+	}
+	defer releaseLockScript.Run(ctx, s.rdb, []string{embeddingLockKey}, owner)
+
+	// 在锁过期前结束，避免锁过期后其他实例同时计算
+	runCtx, cancel := context.WithTimeout(ctx, embeddingRunLimit)
+	defer cancel()
+
+	var lastID uint
+	for runCtx.Err() == nil {
+		n, err := s.embedPending(runCtx, &lastID)
+		if err != nil {
+			if runCtx.Err() == nil {
+				zap.S().Errorf("计算笔记向量失败: %v", err)
+			}
+			return
+		}
+		if n < embeddingBatchSize {
+			return
+		}
+	}
+}
+
+// embedPending 计算一批 ID 大于 lastID 的待处理笔记的向量，返回本批笔记数并把 lastID 移到本批最后一条
+// 提供方拒绝的笔记保存为空向量（记录日志，笔记更新后重试），不影响同批其他笔记
+func (s *EmbeddingService) embedPending(ctx context.Context, lastID *uint) (int, error) {
+	var notes []struct {
+		ID          uint
+		UserID      uint
+		Title       string
+		PlainText   *string
+		ContentHash *string
+	}
+	err := s.db.WithContext(ctx).Model(&model.Note{}).
+		Select("notes.id, notes.user_id, notes.title, notes.plain_text, note_embeddings.content_hash").
+		Joins("LEFT JOIN note_embeddings ON note_embeddings.note_id = notes.id").
+		Where("notes.id > ?", *lastID).
+		Where("note_embeddings.note_id IS NULL OR note_embeddings.provider <> ? OR note_embeddings.updated_at < notes.updated_at", s.provider.Name()).
+		Order("notes.id ASC").Limit(embeddingBatchSize).Scan(&notes).Error
+	if err != nil {
+		return 0, err
+	}
+	if len(notes) > 0 {
+		*lastID = notes[len(notes)-1].ID
+	}
+
+	// 内容未变（如只修改了提醒时间）的只刷新时间，其余重新计算
+	var (
+		texts   []string
+		indexes []int
+		hashes  = make([]string, len(notes))
+		touched []uint
+	)
+	for i, note := range notes {
+		text := note.Title
+		if note.PlainText != nil {
+			text += "\n" + *note.PlainText
+		}
+		if runes := []rune(text); len(runes) > embeddingMaxRunes {
+			text = string(runes[:embeddingMaxRunes])
+		}
+		sum := sha256.Sum256([]byte(s.provider.Name() + "\n" + text))
+		hashes[i] = hex.EncodeToString(sum[:])
+		if note.ContentHash != nil && *note.ContentHash == hashes[i] {
+			touched = append(touched, note.ID)
+			continue
+		}
+		texts = append(texts, text)
+		indexes = append(indexes, i)
+	}
+
+	if len(touched) > 0 {
+		if err := s.db.WithContext(ctx).Model(&model.NoteEmbedding{}).Where("note_id IN ?", touched).
+			UpdateColumn("updated_at", time.Now()).Error; err != nil {
+			return 0, err
+		}
+	}
+	if len(texts) > 0 {
+		vectors, errs, err := s.embedBatch(ctx, texts)
+		if err != nil {
+			return 0, err
+		}
+		rows := make([]model.NoteEmbedding, 0, len(texts))
+		for k, i := range indexes {
+			row := model.NoteEmbedding{
+				NoteID:      notes[i].ID,
+				UserID:      notes[i].UserID,
+				Provider:    s.provider.Name(),
+				ContentHash: hashes[i],
+				Vector:      []byte{},
+				UpdatedAt:   time.Now(),
+			}
+			if errs[k] != nil {
+				// 内容摘要留空，笔记更新后一定重算
+				zap.S().Warnf("计算笔记 %d 的向量失败，跳过: %v", notes[i].ID, errs[k])
+				row.ContentHash = ""
+			} else {
+				row.Vector = embedding.Encode(vectors[k])
+			}
+			rows = append(rows, row)
+		}
+		if err := s.db.WithContext(ctx).Clauses(clause.OnConflict{UpdateAll: true}).Create(&rows).Error; err != nil {
+			return 0, err
+		}
+	}
+	return len(notes), nil
+}
+
+// embedBatch 计算一批文本的向量；整批失败时逐条重试，返回每条的向量和错误。
+// 逐条也全部失败（或只有一条）时视为提供方不可用，返回 err，本批不做标记
+func (s *EmbeddingService) embedBatch(ctx context.Context, texts []string) ([][]float32, []error, error) {
+	errs := make([]error, len(texts))
+	vectors, err := s.embed(ctx, texts)
+	if err == nil {
+		return vectors, errs, nil
+	}
+	if len(texts) == 1 {
+		return nil, nil, err
+	}
+
+	vectors = make([][]float32, len(texts))
+	succeeded := 0
+	for i, text := range texts {
+		vector, textErr := s.embed(ctx, []string{text})
+		if ctx.Err() != nil {
+			return nil, nil, ctx.Err()
+		}
+		if textErr != nil {
+			errs[i] = textErr
+			continue
+		}
+		vectors[i] = vector[0]
+		succeeded++
+	}
+	if succeeded == 0 {
+		return nil, nil, err
+	}
+	return vectors, errs, nil
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// fakeProvider 包含 "bad" 的文本计算失败（整批请求中有一条失败则整批失败），down 时全部失败
+type fakeProvider struct {
+	down  bool
+	calls int
+}
+
+func (p *fakeProvider) Name() string { return "fake" }
+
+func (p *fakeProvider) Embed(_ context.Context, texts []string) ([][]float32, error) {
+	p.calls++
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		if p.down || strings.Contains(text, "bad") {
+			return nil, errors.New("rejected")
+		}
+		vectors[i] = []float32{float32(len(text))}
+	}
+	return vectors, nil
+}
+
+func TestEmbedBatch(t *testing.T) {
+	provider := &fakeProvider{}
+	s := &EmbeddingService{provider: provider}
+
+	vectors, errs, err := s.embedBatch(context.Background(), []string{"a", "bb"})
+	if err != nil || provider.calls != 1 || errs[0] != nil || errs[1] != nil || vectors[1][0] != 2 {
+		t.Fatalf("embedBatch(ok) = %v, %v, %v after %d calls", vectors, errs, err, provider.calls)
+	}
+
+	// 整批失败时逐条重试，只跳过失败的一条
+	provider.calls = 0
+	vectors, errs, err = s.embedBatch(context.Background(), []string{"a", "bad", "ccc"})
+	if err != nil {
+		t.Fatalf("embedBatch(one bad) error: %v", err)
+	}
+	if provider.calls != 4 {
+		t.Errorf("calls = %d, want 4", provider.calls)
+	}
+	if errs[0] != nil || errs[1] == nil || errs[2] != nil || vectors[0][0] != 1 || vectors[1] != nil || vectors[2][0] != 3 {
+		t.Errorf("embedBatch(one bad) = %v, %v", vectors, errs)
+	}
+
+	// 全部失败或只有一条时视为提供方不可用
+	provider.down = true
+	if _, _, err := s.embedBatch(context.Background(), []string{"a", "b"}); err == nil {
+		t.Error("embedBatch with provider down should fail")
+	}
+	provider.down = false
+	if _, _, err := s.embedBatch(context.Background(), []string{"bad"}); err == nil {
+		t.Error("embedBatch with a single bad text should fail")
+	}
+}
@@ -0,0 +1,395 @@
+package service
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/JokerYuan-lang/MyNoteBook/internal/config"
+	"github.com/JokerYuan-lang/MyNoteBook/internal/exporter"
+	"github.com/JokerYuan-lang/MyNoteBook/internal/model"
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/errcode"
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/frontmatter"
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/render"
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/storage"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const exportBatchSize = 100 // 每批读取的笔记数量
+
+// 匹配笔记内容中的附件链接 attachment://<sha256>
+var attachmentLinkRegexp = regexp.MustCompile(regexp.QuoteMeta(model.AttachmentScheme) + `[0-9a-f]{64}`)
+
+// ExportService 笔记导出业务逻辑
+type ExportService struct {
+	db      *gorm.DB
+	storage *storage.LocalStorage
+	pdfFont []byte // PDF 字体（未配置时使用内置西文字体，含中文的笔记无法导出）
+}
+
+// NewExportService 创建 ExportService 实例（字体读取失败只记录警告）
+func NewExportService(db *gorm.DB, storage *storage.LocalStorage, renderConf config.RenderConfig) *ExportService {
+	s := &ExportService{db: db, storage: storage}
+	if renderConf.PdfFont == "" {
+		zap.S().Warn("未配置 render.pdf_font，含中文的笔记无法导出 PDF")
+	} else {
+		font, err := os.ReadFile(renderConf.PdfFont)
+		if err != nil {
+			zap.S().Warnf("读取 PDF 字体失败，含中文的笔记无法导出 PDF: %v", err)
+		}
+		s.pdfFont = font
+	}
+	return s
+}
+
+// ExportMarkdownZip 把用户全部笔记导出为 ZIP（按分类分目录，每条笔记一个带 front matter 的 Markdown 文件）
+// 附件放在 _attachments/<hash>/<文件名>，笔记中的 attachment://<hash> 链接改写为指向该文件的相对路径
+// 笔记分批读取并直接写入 w，不会一次性加载到内存
+func (s *ExportService) ExportMarkdownZip(userID uint, w io.Writer) error {
+	zw := zip.NewWriter(w)
+	usedNames := make(map[string]bool)    // 已使用的文件路径（处理同名笔记）
+	writtenFiles := make(map[string]bool) // 已写入的附件哈希
+
+	var writeErr error
+	var notes []model.Note
+	result := s.db.Where("user_id = ?", userID).Preload("Tags").Order("id").
+		FindInBatches(&notes, exportBatchSize, func(tx *gorm.DB, batch int) error {
+			noteIDs := make([]uint, 0, len(notes))
+			var hashes []string // 内容中引用的附件（可能属于其他笔记）
+			for i := range notes {
+				noteIDs = append(noteIDs, notes[i].ID)
+				for _, link := range attachmentLinkRegexp.FindAllString(notes[i].Content, -1) {
+					hashes = append(hashes, strings.TrimPrefix(link, model.AttachmentScheme))
+				}
+			}
+			attachments, err := s.noteAttachments(userID, noteIDs, hashes)
+			if err != nil {
+				return err
+			}
+			files := make(map[string]string, len(attachments)) // 附件哈希 -> 压缩包中的路径
+			for _, att := range attachments {
+				files[att.Hash] = attachmentPath(&att)
+			}
+
+			for i := range notes {
+				if err := writeNoteFile(zw, &notes[i], usedNames, files); err != nil {
+					writeErr = err
+					return err
+				}
+			}
+			for i := range attachments {
+				if writtenFiles[attachments[i].Hash] {
+					continue
+				}
+				writtenFiles[attachments[i].Hash] = true
+				if err := s.writeAttachment(zw, &attachments[i]); err != nil {
+					writeErr = err
+					return err
+				}
+			}
+			return nil
+		})
+	if writeErr != nil {
+		// 写入失败一般是客户端断开，无需按服务器错误处理
+		return writeErr
+	}
+	if result.Error != nil {
+		zap.S().Errorf("导出笔记失败: %v", result.Error)
+		return errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+
+	return zw.Close()
+}
+
+// ExportPDF 把单条笔记（noteID 不为 0）或整个笔记本（含下级笔记本，按路径和标题排序）的笔记导出为 PDF
+// 笔记中引用的图片附件会嵌入 PDF
+func (s *ExportService) ExportPDF(userID, noteID uint, category string, w io.Writer) error {
+	var notes []model.Note
+	query := s.db.Where("user_id = ?", userID)
+	if noteID > 0 {
+		query = query.Where("id = ?", noteID)
+	} else {
+		cond, args := notebookCondition("category", category)
+		query = query.Where(cond, args...).Order("category ASC, title ASC, id ASC")
+	}
+	if err := query.Find(&notes).Error; err != nil {
+		zap.S().Errorf("查询导出笔记失败: %v", err)
+		return errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+	if len(notes) == 0 {
+		return errors.New(errcode.GetMsg(errcode.NotFound))
+	}
+
+	docs := make([]render.PDFDocument, 0, len(notes))
+	for _, note := range notes {
+		docs = append(docs, render.PDFDocument{
+			Title:    note.Title,
+			Subtitle: fmt.Sprintf("分类：%s    更新时间：%s", note.Category, note.UpdatedAt.Format("2006-01-02 15:04")),
+			Format:   note.ContentFormat,
+			Content:  note.Content,
+		})
+	}
+	err := render.WritePDF(w, docs, render.PDFOptions{
+		Font:  s.pdfFont,
+		Image: s.attachmentImageLoader(userID),
+	})
+	if errors.Is(err, render.ErrFontRequired) {
+		return errcode.NewError(errcode.ServerError, "导出中文 PDF 需要在配置文件中设置 render.pdf_font（TrueType 中文字体）")
+	}
+	if err != nil {
+		zap.S().Errorf("生成 PDF 失败: %v", err)
+		return errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+	return nil
+}
+
+// ExportEPUB 把分类下的笔记导出为 EPUB 电子书（noteIDs 不为空时按其顺序排列，其余笔记按标题排在后面）
+func (s *ExportService) ExportEPUB(userID uint, category string, noteIDs []uint, w io.Writer) error {
+	notes, err := s.getCategoryNotes(userID, category, noteIDs)
+	if err != nil {
+		return err
+	}
+	var user model.User // 作者名查不到时留空，不影响导出
+	s.db.Select("username").Where("id = ?", userID).First(&user)
+
+	book := exporter.Book{Title: category, Author: user.Username, Language: "zh-CN"}
+	if err := exporter.WriteEPUB(w, book, notes, s.assetLoader(userID)); err != nil {
+		zap.S().Errorf("生成 EPUB 失败: %v", err)
+		return errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+	return nil
+}
+
+// ExportSite 把分类下的笔记导出为静态网站压缩包（排序规则同 ExportEPUB）
+func (s *ExportService) ExportSite(userID uint, category string, noteIDs []uint, w io.Writer) error {
+	notes, err := s.getCategoryNotes(userID, category, noteIDs)
+	if err != nil {
+		return err
+	}
+	if err := exporter.WriteSite(w, exporter.Site{Title: category}, notes, s.assetLoader(userID)); err != nil {
+		zap.S().Errorf("生成静态网站失败: %v", err)
+		return errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+	return nil
+}
+
+// getCategoryNotes 查询笔记本（含下级笔记本）中的全部笔记并排序（noteIDs 指定手动顺序）
+func (s *ExportService) getCategoryNotes(userID uint, category string, noteIDs []uint) ([]exporter.Note, error) {
+	var notes []model.Note
+	cond, args := notebookCondition("category", category)
+	err := s.db.Where("user_id = ?", userID).Where(cond, args...).Preload("Tags").
+		Order("category ASC, title ASC, id ASC").Find(&notes).Error
+	if err != nil {
+		zap.S().Errorf("查询导出笔记失败: %v", err)
+		return nil, errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+	if len(notes) == 0 {
+		return nil, errors.New(errcode.GetMsg(errcode.NotFound))
+	}
+
+	// 手动顺序：按 noteIDs 中的位置排序，未列出的保持标题顺序排在最后
+	if len(noteIDs) > 0 {
+		position := make(map[uint]int, len(noteIDs))
+		for i, id := range noteIDs {
+			if _, ok := position[id]; !ok {
+				position[id] = i
+			}
+		}
+		rank := func(id uint) int {
+			if p, ok := position[id]; ok {
+				return p
+			}
+			return len(noteIDs)
+		}
+		sort.SliceStable(notes, func(i, j int) bool { return rank(notes[i].ID) < rank(notes[j].ID) })
+	}
+
+	result := make([]exporter.Note, 0, len(notes))
+	for _, note := range notes {
+		tagNames := make([]string, 0, len(note.Tags))
+		for _, tag := range note.Tags {
+			tagNames = append(tagNames, tag.Name)
+		}
+		result = append(result, exporter.Note{
+			ID:            note.ID,
+			Title:         note.Title,
+			Category:      note.Category,
+			Tags:          tagNames,
+			ContentFormat: note.ContentFormat,
+			Content:       note.Content,
+			CreatedAt:     note.CreatedAt,
+			UpdatedAt:     note.UpdatedAt,
+		})
+	}
+	return result, nil
+}
+
+// attachmentImageLoader 读取用户自己的附件图片（只处理 attachment:// 链接）
+func (s *ExportService) attachmentImageLoader(userID uint) render.ImageLoader {
+	load := s.assetLoader(userID)
+	return func(src string) ([]byte, bool) {
+		hash := strings.TrimPrefix(src, model.AttachmentScheme)
+		if hash == src {
+			return nil, false
+		}
+		asset, ok := load(hash)
+		if !ok {
+			return nil, false
+		}
+		return asset.Data, true
+	}
+}
+
+// assetLoader 按哈希读取用户自己的附件（其他用户的附件视为不存在）
+func (s *ExportService) assetLoader(userID uint) exporter.AssetLoader {
+	return func(hash string) (*exporter.Asset, bool) {
+		var att model.Attachment
+		if err := s.db.Where("user_id = ? AND hash = ?", userID, hash).First(&att).Error; err != nil {
+			return nil, false
+		}
+		f, err := s.storage.Open(hash)
+		if err != nil {
+			return nil, false
+		}
+		defer f.Close()
+		data, err := io.ReadAll(f)
+		if err != nil {
+			return nil, false
+		}
+		return &exporter.Asset{Hash: hash, FileName: att.FileName, MimeType: att.MimeType, Data: data}, true
+	}
+}
+
+// noteAttachments 查询一批笔记的附件，以及内容中引用的其他附件（按哈希）
+func (s *ExportService) noteAttachments(userID uint, noteIDs []uint, hashes []string) ([]model.Attachment, error) {
+	var attachments []model.Attachment
+	query := s.db.Where("user_id = ? AND note_id IN ?", userID, noteIDs)
+	if len(hashes) > 0 {
+		query = s.db.Where("user_id = ? AND (note_id IN ? OR hash IN ?)", userID, noteIDs, hashes)
+	}
+	if err := query.Find(&attachments).Error; err != nil {
+		zap.S().Errorf("查询附件失败: %v", err)
+		return nil, errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+	return attachments, nil
+}
+
+// attachmentPath 附件在压缩包中的路径
+func attachmentPath(att *model.Attachment) string {
+	return path.Join("_attachments", att.Hash, sanitizeFileName(att.FileName))
+}
+
+// writeAttachment 把单个附件文件复制进压缩包（文件丢失时跳过）
+func (s *ExportService) writeAttachment(zw *zip.Writer, att *model.Attachment) error {
+	f, err := s.storage.Open(att.Hash)
+	if err != nil {
+		zap.S().Warnf("附件文件丢失: %s", att.Hash)
+		return nil
+	}
+	defer f.Close()
+
+	fw, err := zw.CreateHeader(&zip.FileHeader{
+		Name:     attachmentPath(att),
+		Method:   zip.Deflate,
+		Modified: att.CreatedAt,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(fw, f)
+	return err
+}
+
+// writeNoteFile 写入单条笔记的 Markdown 文件（files 为附件哈希到压缩包中路径的映射）
+func writeNoteFile(zw *zip.Writer, note *model.Note, usedNames map[string]bool, files map[string]string) error {
+	name := notePath(note, usedNames)
+	body := rewriteAttachmentLinks(note.Content, path.Dir(name), files)
+
+	tagNames := make([]string, 0, len(note.Tags))
+	for _, tag := range note.Tags {
+		tagNames = append(tagNames, tag.Name)
+	}
+	content, err := frontmatter.Marshal(frontmatter.Meta{
+		Title:    note.Title,
+		Category: note.Category,
+		Tags:     tagNames,
+		Created:  note.CreatedAt,
+		Updated:  note.UpdatedAt,
+	}, body)
+	if err != nil {
+		return err
+	}
+
+	fw, err := zw.CreateHeader(&zip.FileHeader{
+		Name:     name,
+		Method:   zip.Deflate,
+		Modified: note.UpdatedAt,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = fw.Write(content)
+	return err
+}
+
+// rewriteAttachmentLinks 把内容中的 attachment://<hash> 改写为相对于笔记所在目录 dir 的附件路径
+// （找不到附件时保持原样）
+func rewriteAttachmentLinks(content, dir string, files map[string]string) string {
+	prefix := ""
+	if dir != "." {
+		prefix = strings.Repeat("../", strings.Count(dir, "/")+1)
+	}
+	return attachmentLinkRegexp.ReplaceAllStringFunc(content, func(link string) string {
+		file, ok := files[strings.TrimPrefix(link, model.AttachmentScheme)]
+		if !ok {
+			return link
+		}
+		parts := strings.Split(file, "/")
+		for i := range parts {
+			parts[i] = url.PathEscape(parts[i])
+		}
+		return prefix + strings.Join(parts, "/")
+	})
+}
+
+// notePath 生成笔记在压缩包中的路径：笔记本路径/标题.md（每级笔记本一层目录，同名时追加笔记ID）
+func notePath(note *model.Note, usedNames map[string]bool) string {
+	var dirs []string
+	for _, name := range notebookNames(note.Category) {
+		dirs = append(dirs, sanitizeFileName(name))
+	}
+	dir := path.Join(dirs...)
+	name := path.Join(dir, sanitizeFileName(note.Title)+".md")
+	if usedNames[strings.ToLower(name)] {
+		name = path.Join(dir, fmt.Sprintf("%s (%d).md", sanitizeFileName(note.Title), note.ID))
+	}
+	usedNames[strings.ToLower(name)] = true
+	return name
+}
+
+// sanitizeFileName 去掉文件名中不合法的字符并限制长度
+func sanitizeFileName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case r < 0x20, strings.ContainsRune(`/\:*?"<>|`, r):
+			return '_'
+		}
+		return r
+	}, strings.TrimSpace(name))
+	name = strings.Trim(name, ". ")
+	if runes := []rune(name); len(runes) > 80 {
+		name = string(runes[:80])
+	}
+	if name == "" {
+		name = "未命名"
+	}
+	return name
+}
@@ -0,0 +1,183 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/JokerYuan-lang/MyNoteBook/internal/model"
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/errcode"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// GraphService 知识图谱业务逻辑（笔记、标签节点，链接、标签归属边）
+type GraphService struct {
+	db *gorm.DB
+}
+
+// NewGraphService 创建 GraphService 实例
+func NewGraphService(db *gorm.DB) *GraphService {
+	return &GraphService{db: db}
+}
+
+// GraphFilter 知识图谱查询条件（零值表示不筛选）
+type GraphFilter struct {
+	Category string // 只包含该笔记本（含下级笔记本）中的笔记
+	NoteID   uint   // 以该笔记为中心查询邻域
+	Depth    int    // 邻域深度（经过的边数，NoteID 不为 0 时有效，默认 1）
+}
+
+// GetGraph 查询用户（或某个分类）的知识图谱；指定笔记时只返回距离该笔记 Depth 步以内的节点
+// 标签也是节点，因此「笔记-标签-笔记」算作两步
+func (s *GraphService) GetGraph(userID uint, filter GraphFilter) (*model.Graph, error) {
+	// 1. 笔记节点
+	var notes []model.Note
+	db := s.db.Select("id, title, category").Where("user_id = ?", userID)
+	if category := strings.TrimSpace(filter.Category); category != "" {
+		cond, args := notebookCondition("category", category)
+		db = db.Where(cond, args...)
+	}
+	if err := db.Order("id ASC").Find(&notes).Error; err != nil {
+		zap.S().Errorf("查询图谱笔记失败: %v", err)
+		return nil, errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+
+	graph := &model.Graph{Nodes: []model.GraphNode{}, Edges: []model.GraphEdge{}}
+	inGraph := make(map[uint]bool, len(notes))
+	for _, note := range notes {
+		inGraph[note.ID] = true
+		graph.Nodes = append(graph.Nodes, model.GraphNode{
+			ID:       noteNodeID(note.ID),
+			Type:     model.GraphNodeNote,
+			RefID:    note.ID,
+			Label:    note.Title,
+			Category: note.Category,
+		})
+	}
+	if filter.NoteID > 0 && !inGraph[filter.NoteID] {
+		return nil, errors.New(errcode.GetMsg(errcode.NotFound))
+	}
+
+	// 2. 链接边（两端都在图中，断链和自链接不计）
+	var links []struct {
+		SourceNoteID uint
+		TargetNoteID uint
+	}
+	err := s.db.Model(&model.NoteLink{}).Distinct("source_note_id", "target_note_id").
+		Where("user_id = ? AND target_note_id IS NOT NULL", userID).Scan(&links).Error
+	if err != nil {
+		zap.S().Errorf("查询图谱链接失败: %v", err)
+		return nil, errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+	for _, link := range links {
+		if inGraph[link.SourceNoteID] && inGraph[link.TargetNoteID] && link.SourceNoteID != link.TargetNoteID {
+			graph.Edges = append(graph.Edges, model.GraphEdge{
+				Source: noteNodeID(link.SourceNoteID),
+				Target: noteNodeID(link.TargetNoteID),
+				Type:   model.GraphEdgeLink,
+			})
+		}
+	}
+
+	// 3. 标签节点和标签归属边（只包含图中笔记用到的标签）
+	var tagRows []struct {
+		NoteID uint
+		TagID  uint
+		Name   string
+	}
+	err = s.db.Table("note_tags").Select("note_tags.note_id, tags.id AS tag_id, tags.name").
+		Joins("JOIN tags ON tags.id = note_tags.tag_id AND tags.deleted_at IS NULL").
+		Where("tags.user_id = ?", userID).Order("tags.id ASC, note_tags.note_id ASC").Scan(&tagRows).Error
+	if err != nil {
+		zap.S().Errorf("查询图谱标签失败: %v", err)
+		return nil, errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+	tagAdded := make(map[uint]bool)
+	for _, row := range tagRows {
+		if !inGraph[row.NoteID] {
+			continue
+		}
+		if !tagAdded[row.TagID] {
+			tagAdded[row.TagID] = true
+			graph.Nodes = append(graph.Nodes, model.GraphNode{
+				ID:    tagNodeID(row.TagID),
+				Type:  model.GraphNodeTag,
+				RefID: row.TagID,
+				Label: row.Name,
+			})
+		}
+		graph.Edges = append(graph.Edges, model.GraphEdge{
+			Source: noteNodeID(row.NoteID),
+			Target: tagNodeID(row.TagID),
+			Type:   model.GraphEdgeTag,
+		})
+	}
+
+	// 4. 邻域查询
+	if filter.NoteID > 0 {
+		depth := filter.Depth
+		if depth <= 0 {
+			depth = 1
+		}
+		graph = neighborhood(graph, noteNodeID(filter.NoteID), depth)
+	}
+
+	// 5. 统计节点度数
+	degrees := make(map[string]int, len(graph.Nodes))
+	for _, edge := range graph.Edges {
+		degrees[edge.Source]++
+		degrees[edge.Target]++
+	}
+	for i := range graph.Nodes {
+		graph.Nodes[i].Degree = degrees[graph.Nodes[i].ID]
+	}
+	return graph, nil
+}
+
+// neighborhood 按广度优先（忽略边的方向）保留距离中心节点 depth 步以内的节点及它们之间的边
+func neighborhood(graph *model.Graph, center string, depth int) *model.Graph {
+	adjacent := make(map[string][]string)
+	for _, edge := range graph.Edges {
+		adjacent[edge.Source] = append(adjacent[edge.Source], edge.Target)
+		adjacent[edge.Target] = append(adjacent[edge.Target], edge.Source)
+	}
+
+	visited := map[string]bool{center: true}
+	frontier := []string{center}
+	for step := 0; step < depth && len(frontier) > 0; step++ {
+		var next []string
+		for _, id := range frontier {
+			for _, neighbor := range adjacent[id] {
+				if !visited[neighbor] {
+					visited[neighbor] = true
+					next = append(next, neighbor)
+				}
+			}
+		}
+		frontier = next
+	}
+
+	result := &model.Graph{Nodes: []model.GraphNode{}, Edges: []model.GraphEdge{}}
+	for _, node := range graph.Nodes {
+		if visited[node.ID] {
+			result.Nodes = append(result.Nodes, node)
+		}
+	}
+	for _, edge := range graph.Edges {
+		if visited[edge.Source] && visited[edge.Target] {
+			result.Edges = append(result.Edges, edge)
+		}
+	}
+	return result
+}
+
+// noteNodeID 笔记节点标识
+func noteNodeID(noteID uint) string {
+	return fmt.Sprintf("%s:%d", model.GraphNodeNote, noteID)
+}
+
+// tagNodeID 标签节点标识
+func tagNodeID(tagID uint) string {
+	return fmt.Sprintf("%s:%d", model.GraphNodeTag, tagID)
+}
@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"syscall"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// cgnatNet 运营商级 NAT 共享地址段（RFC 6598），不属于公网地址
+var cgnatNet = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// hostAllowList 允许访问的内网主机白名单（主机名、IP 或 CIDR 网段），为 nil 时不放行任何内网地址
+type hostAllowList struct {
+	hosts map[string]bool // 主机名（小写）和 IP
+	nets  []*net.IPNet
+}
+
+// newHostAllowList 解析白名单配置（无效的网段记录日志后忽略）
+func newHostAllowList(entries []string) *hostAllowList {
+	list := &hostAllowList{hosts: make(map[string]bool)}
+	for _, entry := range entries {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		switch {
+		case entry == "":
+		case strings.Contains(entry, "/"):
+			_, ipNet, err := net.ParseCIDR(entry)
+			if err != nil {
+				zap.S().Warnf("忽略无效的内网白名单网段 %s", entry)
+				continue
+			}
+			list.nets = append(list.nets, ipNet)
+		default:
+			if ip := net.ParseIP(entry); ip != nil {
+				entry = ip.String()
+			}
+			list.hosts[entry] = true
+		}
+	}
+	return list
+}
+
+// allowHost 判断主机名或 IP 是否在白名单中
+func (l *hostAllowList) allowHost(host string) bool {
+	if l == nil {
+		return false
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return l.hosts[strings.ToLower(host)]
+	}
+	return l.allowIP(ip)
+}
+
+// allowIP 判断 IP 是否在白名单中
+func (l *hostAllowList) allowIP(ip net.IP) bool {
+	if l == nil {
+		return false
+	}
+	if l.hosts[ip.String()] {
+		return true
+	}
+	for _, ipNet := range l.nets {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkHost 检查主机名解析出的地址：须全部为公网地址，或主机名、地址在白名单中
+func (l *hostAllowList) checkHost(host string) error {
+	if l.allowHost(host) {
+		return nil
+	}
+	ips, err := net.LookupIP(host)
+	if err != nil || len(ips) == 0 {
+		return fmt.Errorf("地址 %s 无法解析", host)
+	}
+	for _, ip := range ips {
+		if !isPublicIP(ip) && !l.allowIP(ip) {
+			return fmt.Errorf("禁止访问内网地址 %s", host)
+		}
+	}
+	return nil
+}
+
+// newPublicHTTPClient 创建只能访问公网地址（及白名单中的内网主机）的 HTTP 客户端（防止借服务器访问内网，SSRF）
+func newPublicHTTPClient(timeout time.Duration, allow *hostAllowList) *http.Client {
+	dialer := &net.Dialer{Timeout: 5 * time.Second}
+	guarded := &net.Dialer{
+		Timeout: 5 * time.Second,
+		// 在建立连接前检查解析后的 IP，重定向和 DNS 重绑定同样会被拦截
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !(isPublicIP(ip) || allow.allowIP(ip)) {
+				return fmt.Errorf("禁止访问内网地址 %s", host)
+			}
+			return nil
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = func(ctx context.Context, network, address string) (net.Conn, error) {
+		// 白名单中的主机名不再检查解析结果
+		if host, _, err := net.SplitHostPort(address); err == nil && net.ParseIP(host) == nil && allow.allowHost(host) {
+			return dialer.DialContext(ctx, network, address)
+		}
+		return guarded.DialContext(ctx, network, address)
+	}
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
+
+// isPublicIP 判断是否为公网地址（排除回环、内网、运营商级 NAT、链路本地和组播地址）
+func isPublicIP(ip net.IP) bool {
+	return !(ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || cgnatNet.Contains(ip) ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsMulticast())
+}
@@ -0,0 +1,533 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/JokerYuan-lang/MyNoteBook/internal/importer"
+	"github.com/JokerYuan-lang/MyNoteBook/internal/model"
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/errcode"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	importMaxErrors     = 200              // 错误报告最多保留的条数
+	importFlushInterval = 20               // 每处理多少个文件保存一次进度
+	importMaxRunning    = 2                // 每个实例同时执行的导入任务数（其余任务排队等待）
+	importHeartbeat     = 30 * time.Second // 任务排队和执行期间刷新更新时间的间隔
+	importStaleAfter    = 2 * time.Minute  // 未结束的任务超过该时间未更新视为已中断（如服务重启），标记为失败
+)
+
+// errImportSkipped 笔记与已有笔记重复，跳过导入
+var errImportSkipped = errors.New("重复笔记已跳过")
+
+// UploadFile 上传的待导入文件（内容保存在临时文件中，由导入任务在结束后删除）
+type UploadFile struct {
+	Name string // 原始文件名
+	Path string // 临时文件路径
+}
+
+// RemoveUploadFiles 删除上传文件对应的临时文件
+func RemoveUploadFiles(files []UploadFile) {
+	for _, f := range files {
+		if err := os.Remove(f.Path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			zap.S().Warnf("删除导入临时文件失败: %v", err)
+		}
+	}
+}
+
+// uploadSet 一次导入的上传文件及其打开的句柄（压缩包在读取完之前不能关闭）
+type uploadSet struct {
+	files  []UploadFile
+	opened []*os.File
+	temps  []string // 导入过程中解压出的临时文件（如 Notion 的内层压缩包）
+}
+
+// open 打开上传文件（导入结束时统一关闭）
+func (u *uploadSet) open(f UploadFile) (*os.File, int64, error) {
+	file, err := os.Open(f.Path)
+	if err != nil {
+		return nil, 0, err
+	}
+	u.opened = append(u.opened, file)
+	info, err := file.Stat()
+	if err != nil {
+		return nil, 0, err
+	}
+	return file, info.Size(), nil
+}
+
+// openZip 打开上传的 ZIP 压缩包
+func (u *uploadSet) openZip(f UploadFile) ([]*importer.ArchiveFile, error) {
+	file, size, err := u.open(f)
+	if err != nil {
+		return nil, err
+	}
+	return importer.OpenZip(file, size)
+}
+
+// openTar 打开上传的 TAR 归档
+func (u *uploadSet) openTar(f UploadFile) ([]*importer.ArchiveFile, error) {
+	file, size, err := u.open(f)
+	if err != nil {
+		return nil, err
+	}
+	return importer.OpenTar(file, size)
+}
+
+// openNestedZip 把压缩包中的内层压缩包解压到临时文件后打开（不整体读入内存）
+func (u *uploadSet) openNestedZip(zf *importer.ArchiveFile) ([]*importer.ArchiveFile, error) {
+	file, err := os.CreateTemp("", "mynotebook-import-*")
+	if err != nil {
+		return nil, err
+	}
+	u.opened = append(u.opened, file)
+	u.temps = append(u.temps, file.Name())
+	if err := zf.CopyTo(file); err != nil {
+		return nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	return importer.OpenZip(file, info.Size())
+}
+
+// cleanup 关闭打开的文件并删除临时文件
+func (u *uploadSet) cleanup() {
+	for _, file := range u.opened {
+		file.Close()
+	}
+	u.opened = nil
+	RemoveUploadFiles(u.files)
+	for _, name := range u.temps {
+		if err := os.Remove(name); err != nil && !errors.Is(err, os.ErrNotExist) {
+			zap.S().Warnf("删除导入临时文件失败: %v", err)
+		}
+	}
+	u.temps = nil
+}
+
+// ImportError 单个文件的导入错误
+type ImportError struct {
+	File  string `json:"file"`
+	Error string `json:"error"`
+}
+
+// importHandler 处理一个待导入文件的解析结果（note 为 nil 时 err 为解析错误）
+type importHandler func(file string, note *importer.Note, err error)
+
+// importWalker 在后台逐个产出待导入笔记（总数在解析后才知道时调用 setTotal 更新），返回错误表示整体失败
+type importWalker func(handle importHandler, setTotal func(total int)) error
+
+// ImportService 笔记导入业务逻辑
+type ImportService struct {
+	db                *gorm.DB
+	noteService       *NoteService
+	attachmentService *AttachmentService
+	slots             chan struct{} // 执行名额（限制同时执行的任务数）
+}
+
+// NewImportService 创建 ImportService 实例
+func NewImportService(db *gorm.DB, noteService *NoteService, attachmentService *AttachmentService) *ImportService {
+	return &ImportService{
+		db:                db,
+		noteService:       noteService,
+		attachmentService: attachmentService,
+		slots:             make(chan struct{}, importMaxRunning),
+	}
+}
+
+// Run 启动时及之后定期把已中断的导入任务标记为失败（阻塞直到 ctx 结束，多实例部署安全）
+func (s *ImportService) Run(ctx context.Context) {
+	ticker := time.NewTicker(importHeartbeat)
+	defer ticker.Stop()
+	for {
+		s.failStaleJobs()
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// failStaleJobs 把长时间未更新的排队中、执行中任务标记为失败
+// 任务只在创建它的实例内存中执行，排队和执行期间会定期刷新更新时间；服务重启后不再刷新，超时后由任一实例标记
+func (s *ImportService) failStaleJobs() {
+	result := s.db.Model(&model.ImportJob{}).
+		Where("status IN ? AND updated_at < ?", []string{model.ImportStatusPending, model.ImportStatusRunning}, time.Now().Add(-importStaleAfter)).
+		Update("status", model.ImportStatusFailed)
+	if result.Error != nil {
+		zap.S().Errorf("标记中断的导入任务失败: %v", result.Error)
+		return
+	}
+	if result.RowsAffected > 0 {
+		zap.S().Warnf("%d 个导入任务已中断，标记为失败", result.RowsAffected)
+	}
+}
+
+// ImportMarkdown 导入 Markdown 文件或包含 Markdown 的 ZIP（异步执行，返回任务）
+func (s *ImportService) ImportMarkdown(userID uint, files []UploadFile) (*model.ImportJob, error) {
+	uploads := &uploadSet{files: files}
+
+	// 先展开压缩包目录，统计总数并尽早发现无法解析的压缩包
+	var entries []*importer.ArchiveFile
+	for _, f := range files {
+		if strings.EqualFold(path.Ext(f.Name), ".zip") {
+			zipFiles, err := uploads.openZip(f)
+			if err != nil {
+				uploads.cleanup()
+				return nil, fmt.Errorf("压缩包 %s 无法解析", f.Name)
+			}
+			for _, zf := range zipFiles {
+				if importer.IsMarkdown(zf.Name) {
+					entries = append(entries, zf)
+				}
+			}
+			continue
+		}
+		if !importer.IsMarkdown(f.Name) {
+			uploads.cleanup()
+			return nil, fmt.Errorf("不支持的文件类型: %s", f.Name)
+		}
+		entries = append(entries, importer.OpenFile(f.Name, f.Path))
+	}
+	if len(entries) == 0 {
+		uploads.cleanup()
+		return nil, errors.New("没有可导入的 Markdown 文件")
+	}
+
+	return s.startUploadJob(uploads, userID, model.ImportSourceMarkdown, len(entries), func(handle importHandler, _ func(int)) error {
+		for _, e := range entries {
+			data, err := e.Read()
+			if err != nil {
+				handle(e.Name, nil, err)
+				continue
+			}
+			note, err := importer.ParseMarkdown(e.Name, data)
+			handle(e.Name, note, err)
+		}
+		return nil
+	})
+}
+
+// ImportENEX 导入 Evernote 导出的 .enex 文件（异步执行，文件名作为分类）
+func (s *ImportService) ImportENEX(userID uint, files []UploadFile) (*model.ImportJob, error) {
+	uploads := &uploadSet{files: files}
+	for _, f := range files {
+		if !strings.EqualFold(path.Ext(f.Name), ".enex") {
+			uploads.cleanup()
+			return nil, fmt.Errorf("不支持的文件类型: %s", f.Name)
+		}
+	}
+
+	// ENEX 从临时文件逐条流式解析，笔记总数未知
+	return s.startUploadJob(uploads, userID, model.ImportSourceENEX, 0, func(handle importHandler, _ func(int)) error {
+		for _, f := range files {
+			file, _, err := uploads.open(f)
+			if err != nil {
+				handle(f.Name, nil, err)
+				continue
+			}
+			category := strings.TrimSuffix(path.Base(f.Name), path.Ext(f.Name))
+			index := 0
+			err = importer.ParseENEX(file, category, func(note *importer.Note, err error) {
+				index++
+				handle(fmt.Sprintf("%s#%d %s", f.Name, index, note.Title), note, err)
+			})
+			if err != nil {
+				// 单个文件损坏不影响其他文件
+				handle(f.Name, nil, err)
+			}
+		}
+		return nil
+	})
+}
+
+// ImportJoplin 导入 Joplin 导出（.jex 文件或 RAW 目录打包的 .zip，异步执行）
+// 请求中只打开压缩包检查格式，解析和导入都在后台任务中进行
+func (s *ImportService) ImportJoplin(userID uint, files []UploadFile) (*model.ImportJob, error) {
+	uploads := &uploadSet{files: files}
+	var archiveFiles []*importer.ArchiveFile
+	for _, f := range files {
+		var (
+			opened []*importer.ArchiveFile
+			err    error
+		)
+		switch strings.ToLower(path.Ext(f.Name)) {
+		case ".jex":
+			opened, err = uploads.openTar(f)
+		case ".zip":
+			opened, err = uploads.openZip(f)
+		default:
+			uploads.cleanup()
+			return nil, fmt.Errorf("不支持的文件类型: %s", f.Name)
+		}
+		if err != nil {
+			uploads.cleanup()
+			return nil, fmt.Errorf("压缩包 %s 无法解析", f.Name)
+		}
+		archiveFiles = append(archiveFiles, opened...)
+	}
+
+	return s.startUploadJob(uploads, userID, model.ImportSourceJoplin, 0, func(handle importHandler, setTotal func(int)) error {
+		results, err := importer.ParseJoplin(archiveFiles)
+		if err != nil {
+			return err
+		}
+		setTotal(len(results))
+		for _, r := range results {
+			handle(r.File, r.Note, r.Err)
+		}
+		return nil
+	})
+}
+
+// ImportNotion 导入 Notion 的 Markdown & CSV 导出（.zip，支持外层包裹的分卷 zip，异步执行）
+// 请求中只打开压缩包检查格式，内层压缩包的解压、解析和导入都在后台任务中进行
+func (s *ImportService) ImportNotion(userID uint, files []UploadFile) (*model.ImportJob, error) {
+	uploads := &uploadSet{files: files}
+	var archiveFiles []*importer.ArchiveFile
+	for _, f := range files {
+		if !strings.EqualFold(path.Ext(f.Name), ".zip") {
+			uploads.cleanup()
+			return nil, fmt.Errorf("不支持的文件类型: %s", f.Name)
+		}
+		opened, err := uploads.openZip(f)
+		if err != nil {
+			uploads.cleanup()
+			return nil, fmt.Errorf("压缩包 %s 无法解析", f.Name)
+		}
+		archiveFiles = append(archiveFiles, opened...)
+	}
+
+	return s.startUploadJob(uploads, userID, model.ImportSourceNotion, 0, func(handle importHandler, setTotal func(int)) error {
+		var pageFiles []*importer.ArchiveFile
+		for _, zf := range archiveFiles {
+			if !strings.EqualFold(path.Ext(zf.Name), ".zip") {
+				pageFiles = append(pageFiles, zf)
+				continue
+			}
+			// Notion 较大的导出会再包一层 Part-N.zip
+			inner, err := uploads.openNestedZip(zf)
+			if err != nil {
+				return fmt.Errorf("压缩包 %s 无法解析: %v", zf.Name, err)
+			}
+			pageFiles = append(pageFiles, inner...)
+		}
+
+		results, err := importer.ParseNotion(pageFiles)
+		if err != nil {
+			return err
+		}
+		setTotal(len(results))
+		for _, r := range results {
+			handle(r.File, r.Note, r.Err)
+		}
+		return nil
+	})
+}
+
+// GetJob 查询导入任务
+func (s *ImportService) GetJob(userID, jobID uint) (*model.ImportJob, error) {
+	var job model.ImportJob
+	err := s.db.Where("user_id = ? AND id = ?", userID, jobID).First(&job).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New(errcode.GetMsg(errcode.NotFound))
+		}
+		zap.S().Errorf("查询导入任务失败: %v", err)
+		return nil, errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+	return &job, nil
+}
+
+// ParseJobErrors 解析任务中保存的逐文件错误
+func ParseJobErrors(job *model.ImportJob) []ImportError {
+	var importErrors []ImportError
+	if job.Errors != "" {
+		_ = json.Unmarshal([]byte(job.Errors), &importErrors)
+	}
+	return importErrors
+}
+
+// GetJobList 查询最近的导入任务（不含错误详情）
+func (s *ImportService) GetJobList(userID uint) ([]model.ImportJob, error) {
+	var jobs []model.ImportJob
+	err := s.db.Omit("errors").Where("user_id = ?", userID).Order("id DESC").Limit(20).Find(&jobs).Error
+	if err != nil {
+		zap.S().Errorf("查询导入任务列表失败: %v", err)
+		return nil, errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+	return jobs, nil
+}
+
+// startUploadJob 同 startJob，任务结束（或创建任务失败）后关闭并删除上传的临时文件
+func (s *ImportService) startUploadJob(uploads *uploadSet, userID uint, source string, total int, walk importWalker) (*model.ImportJob, error) {
+	job, err := s.startJob(userID, source, total, func(handle importHandler, setTotal func(int)) error {
+		defer uploads.cleanup()
+		return walk(handle, setTotal)
+	})
+	if err != nil {
+		uploads.cleanup()
+	}
+	return job, err
+}
+
+// startJob 创建导入任务并在后台执行 walk（total 为 0 表示总数未知）
+func (s *ImportService) startJob(userID uint, source string, total int, walk importWalker) (*model.ImportJob, error) {
+	job := model.ImportJob{
+		UserID: userID,
+		Source: source,
+		Status: model.ImportStatusPending,
+		Total:  total,
+	}
+	if err := s.db.Create(&job).Error; err != nil {
+		zap.S().Errorf("创建导入任务失败: %v", err)
+		return nil, errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+
+	go s.runJob(job, walk)
+	return &job, nil
+}
+
+// runJob 等待执行名额后执行导入任务，定期保存进度
+func (s *ImportService) runJob(job model.ImportJob, walk importWalker) {
+	// 排队和执行期间定期刷新更新时间，表明任务仍在进行
+	done := make(chan struct{})
+	defer close(done)
+	go s.heartbeat(job.ID, done)
+
+	// 超出并发数的任务保持 pending 排队
+	s.slots <- struct{}{}
+	defer func() { <-s.slots }()
+
+	var importErrors []ImportError
+	addWarning := func(file, msg string) {
+		if len(importErrors) < importMaxErrors {
+			importErrors = append(importErrors, ImportError{File: file, Error: msg})
+		}
+	}
+	addError := func(file, msg string) {
+		job.Failed++
+		addWarning(file, msg)
+	}
+	save := func() {
+		data, _ := json.Marshal(importErrors)
+		job.Errors = string(data)
+		if err := s.db.Save(&job).Error; err != nil {
+			zap.S().Errorf("保存导入进度失败: %v", err)
+		}
+	}
+	defer func() {
+		// 导入过程中的异常不能影响服务
+		if r := recover(); r != nil {
+			zap.S().Errorf("导入任务异常: %v", r)
+			job.Status = model.ImportStatusFailed
+			save()
+		}
+	}()
+
+	job.Status = model.ImportStatusRunning
+	save()
+
+	// 记录来源ID对应的笔记ID，以及内容中含有笔记间链接、需要在全部导入后改写的笔记
+	type pendingLinks struct {
+		file    string
+		noteID  uint
+		content string
+	}
+	var (
+		noteIDs = make(map[string]uint)
+		pending []pendingLinks
+	)
+
+	setTotal := func(total int) {
+		job.Total = total
+		save()
+	}
+	err := walk(func(file string, note *importer.Note, err error) {
+		job.Processed++
+		var noteID uint
+		if err != nil {
+			addError(file, err.Error())
+		} else if noteID, err = s.importNote(job.UserID, note); errors.Is(err, errImportSkipped) {
+			job.Skipped++
+		} else if err != nil {
+			addError(file, err.Error())
+		} else {
+			job.Created++
+			for _, warning := range note.Warnings {
+				addWarning(file, warning)
+			}
+			if importer.HasNoteRefs(note.Content) {
+				pending = append(pending, pendingLinks{file: file, noteID: noteID, content: note.Content})
+			}
+		}
+		if noteID > 0 && note.SourceID != "" {
+			noteIDs[note.SourceID] = noteID
+		}
+		if job.Processed%importFlushInterval == 0 {
+			save()
+		}
+	}, setTotal)
+
+	// 改写笔记间链接（目标笔记未导入时只保留链接文字）
+	for _, p := range pending {
+		content := importer.ResolveNoteRefs(p.content, func(sourceID string) (uint, bool) {
+			noteID, ok := noteIDs[sourceID]
+			return noteID, ok
+		})
+		if err := s.noteService.UpdateImportedContent(job.UserID, p.noteID, content); err != nil {
+			addError(p.file, "笔记链接改写失败")
+		}
+	}
+
+	job.Status = model.ImportStatusDone
+	if err != nil {
+		job.Status = model.ImportStatusFailed
+		addError("", err.Error())
+	}
+	save()
+}
+
+// heartbeat 定期刷新任务的更新时间，直到 done 关闭
+func (s *ImportService) heartbeat(jobID uint, done <-chan struct{}) {
+	ticker := time.NewTicker(importHeartbeat)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			if err := s.db.Model(&model.ImportJob{}).Where("id = ?", jobID).UpdateColumn("updated_at", time.Now()).Error; err != nil {
+				zap.S().Errorf("刷新导入任务状态失败: %v", err)
+			}
+		}
+	}
+}
+
+// importNote 导入单条笔记及其附件，返回笔记ID（重复笔记返回已有笔记ID和 errImportSkipped）
+func (s *ImportService) importNote(userID uint, note *importer.Note) (uint, error) {
+	noteID, skipped, err := s.noteService.ImportNote(userID, note)
+	if err != nil {
+		return 0, err
+	}
+	if skipped {
+		return noteID, errImportSkipped
+	}
+	for _, att := range note.Attachments {
+		if _, err := s.attachmentService.SaveAttachment(userID, noteID, att.FileName, att.MimeType, att.Data); err != nil {
+			return noteID, fmt.Errorf("附件 %s 保存失败", att.FileName)
+		}
+	}
+	return noteID, nil
+}
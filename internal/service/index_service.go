@@ -0,0 +1,225 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+
+	"github.com/JokerYuan-lang/MyNoteBook/internal/config"
+	"github.com/JokerYuan-lang/MyNoteBook/internal/model"
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/analyzer"
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/errcode"
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/simhash"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// maxTermLen 索引词最大长度（字），超长的词不进入索引
+const maxTermLen = 64
+
+// IndexService 笔记搜索索引：保存笔记时分词写入 note_terms，关键词搜索按分词结果匹配
+type IndexService struct {
+	db        *gorm.DB
+	tokenizer analyzer.Tokenizer
+}
+
+// NewIndexService 创建 IndexService 实例（分词器加载失败时退回按空白切分并记录警告）
+func NewIndexService(db *gorm.DB, searchConf config.SearchConfig) *IndexService {
+	tokenizer, err := analyzer.New(analyzer.Config{Name: searchConf.Tokenizer, DictPath: searchConf.DictPath})
+	if err != nil {
+		zap.S().Warnf("加载搜索分词器失败，改为按空白切分: %v", err)
+		tokenizer = analyzer.Whitespace{}
+	}
+	return &IndexService{db: db, tokenizer: tokenizer}
+}
+
+// termKey 索引词及来源
+type termKey struct {
+	term  string
+	field string
+}
+
+// IndexNote 重建一条笔记的索引：标题、纯文本内容和标签分词，标题和标签另外加入拼音；同时更新内容指纹
+func (s *IndexService) IndexNote(note *model.Note, tagNames []string) error {
+	counts := make(map[termKey]int)
+	add := func(field string, terms ...string) {
+		for _, term := range terms {
+			if term != "" && len([]rune(term)) <= maxTermLen {
+				counts[termKey{term: term, field: field}]++
+			}
+		}
+	}
+
+	text := []string{note.Title}
+	if note.PlainText != nil {
+		text = append(text, *note.PlainText)
+	}
+	textCounts := s.TermCounts(text...)
+	for term, count := range textCounts {
+		counts[termKey{term: term, field: model.TermFieldText}] = count
+	}
+	for term, count := range s.TermCounts(tagNames...) {
+		counts[termKey{term: term, field: model.TermFieldText}] += count
+	}
+	for _, name := range append([]string{note.Title}, tagNames...) {
+		add(model.TermFieldPinyin, s.pinyinTerms(name)...)
+	}
+
+	rows := make([]model.NoteTerm, 0, len(counts))
+	for key, count := range counts {
+		rows = append(rows, model.NoteTerm{UserID: note.UserID, NoteID: note.ID, Term: key.term, Field: key.field, Count: count})
+	}
+	if err := s.RemoveNote(note.ID); err != nil {
+		return err
+	}
+	if len(rows) > 0 {
+		if err := s.db.CreateInBatches(rows, 500).Error; err != nil {
+			zap.S().Errorf("写入搜索索引失败: %v", err)
+			return errors.New(errcode.GetMsg(errcode.ServerError))
+		}
+	}
+
+	// 内容指纹只按标题和正文计算（标签不同的重复笔记也能找出来）
+	fingerprint := model.NoteFingerprint{
+		NoteID:   note.ID,
+		UserID:   note.UserID,
+		SimHash:  simhash.Fingerprint(textCounts),
+		Features: len(textCounts),
+	}
+	if err := s.db.Create(&fingerprint).Error; err != nil {
+		zap.S().Errorf("写入内容指纹失败: %v", err)
+		return errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+	return nil
+}
+
+// ReindexNotes 重建多条笔记的索引（标签变化或内容被改写后调用）
+func (s *IndexService) ReindexNotes(noteIDs []uint) error {
+	if len(noteIDs) == 0 {
+		return nil
+	}
+	var notes []model.Note
+	if err := s.db.Select("id, user_id, title, plain_text").Where("id IN ?", noteIDs).Preload("Tags").Find(&notes).Error; err != nil {
+		zap.S().Errorf("查询笔记失败: %v", err)
+		return errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+	for i := range notes {
+		tagNames := make([]string, 0, len(notes[i].Tags))
+		for _, tag := range notes[i].Tags {
+			tagNames = append(tagNames, tag.Name)
+		}
+		if err := s.IndexNote(&notes[i], tagNames); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// TermCounts 分词并统计词频（超长的词忽略）
+func (s *IndexService) TermCounts(texts ...string) map[string]int {
+	counts := make(map[string]int)
+	for _, term := range s.tokenizer.Tokenize(strings.Join(texts, "\n")) {
+		if len([]rune(term)) <= maxTermLen {
+			counts[term]++
+		}
+	}
+	return counts
+}
+
+// pinyinTerms 文本及其中每个中文词的拼音（全拼、首字母），便于用 hyjy、huiyi 等搜索「会议纪要」
+func (s *IndexService) pinyinTerms(text string) []string {
+	terms := analyzer.Pinyin(text)
+	for _, word := range s.tokenizer.Tokenize(text) {
+		if hasHan(word) {
+			terms = append(terms, analyzer.Pinyin(word)...)
+		}
+	}
+	return terms
+}
+
+// RemoveNote 删除笔记的索引和内容指纹（重建索引时也会调用，不删除语义向量）
+func (s *IndexService) RemoveNote(noteID uint) error {
+	if err := s.db.Where("note_id = ?", noteID).Delete(&model.NoteTerm{}).Error; err != nil {
+		zap.S().Errorf("删除搜索索引失败: %v", err)
+		return errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+	if err := s.db.Where("note_id = ?", noteID).Delete(&model.NoteFingerprint{}).Error; err != nil {
+		zap.S().Errorf("删除内容指纹失败: %v", err)
+		return errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+	return nil
+}
+
+// RemoveEmbedding 删除笔记的语义向量（只在删除笔记时调用；修改笔记后由后台按内容摘要判断是否重算）
+func (s *IndexService) RemoveEmbedding(noteID uint) error {
+	if err := s.db.Where("note_id = ?", noteID).Delete(&model.NoteEmbedding{}).Error; err != nil {
+		zap.S().Errorf("删除语义向量失败: %v", err)
+		return errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+	return nil
+}
+
+// KeywordCondition 关键词搜索条件：标题包含关键词，或关键词的每个分词都在笔记索引中（与词序、空格无关）
+// 关键词分词为空（如只有标点）时按标题和正文包含匹配
+func (s *IndexService) KeywordCondition(keyword string) (string, []interface{}) {
+	like := "%" + likeEscaper.Replace(keyword) + "%"
+	terms := uniqueTerms(s.tokenizer.Tokenize(keyword))
+	if len(terms) == 0 {
+		return "(notes.title LIKE ? OR notes.plain_text LIKE ?)", []interface{}{like, like}
+	}
+
+	conds := make([]string, 0, len(terms))
+	args := []interface{}{like}
+	for _, term := range terms {
+		conds = append(conds, "EXISTS (SELECT 1 FROM note_terms WHERE note_terms.note_id = notes.id AND note_terms.term = ?)")
+		args = append(args, term)
+	}
+	return "(notes.title LIKE ? OR (" + strings.Join(conds, " AND ") + "))", args
+}
+
+// RebuildIndex 为还没有索引或内容指纹的笔记（如升级前创建的笔记）建立索引，启动时在后台执行（应在补全渲染缓存之后）
+func (s *IndexService) RebuildIndex() {
+	var notes []model.Note
+	result := s.db.Select("id, user_id, title, plain_text").Preload("Tags").
+		Where("NOT EXISTS (SELECT 1 FROM note_fingerprints WHERE note_fingerprints.note_id = notes.id)").
+		FindInBatches(&notes, 100, func(tx *gorm.DB, batch int) error {
+			for i := range notes {
+				tagNames := make([]string, 0, len(notes[i].Tags))
+				for _, tag := range notes[i].Tags {
+					tagNames = append(tagNames, tag.Name)
+				}
+				if err := s.IndexNote(&notes[i], tagNames); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	if result.Error != nil {
+		zap.S().Errorf("建立搜索索引失败: %v", result.Error)
+	}
+}
+
+// uniqueTerms 去重（保持顺序）
+func uniqueTerms(terms []string) []string {
+	var (
+		result []string
+		seen   = make(map[string]bool)
+	)
+	for _, term := range terms {
+		if !seen[term] && len([]rune(term)) <= maxTermLen {
+			seen[term] = true
+			result = append(result, term)
+		}
+	}
+	return result
+}
+
+// hasHan 是否包含汉字
+func hasHan(text string) bool {
+	for _, r := range text {
+		if unicode.Is(unicode.Han, r) {
+			return true
+		}
+	}
+	return false
+}
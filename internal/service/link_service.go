@@ -0,0 +1,290 @@
+package service
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/JokerYuan-lang/MyNoteBook/internal/model"
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/errcode"
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/wikilink"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// LinkService 笔记间链接（[[标题]] / 笔记ID 链接）和反链业务逻辑
+type LinkService struct {
+	db           *gorm.DB
+	indexService *IndexService
+}
+
+// NewLinkService 创建 LinkService 实例
+func NewLinkService(db *gorm.DB, indexService *IndexService) *LinkService {
+	return &LinkService{db: db, indexService: indexService}
+}
+
+// linkRow 链接查询结果（目标笔记不存在或已删除时 NoteID 为空）
+type linkRow struct {
+	TargetTitle  string
+	TargetNoteID *uint
+	NoteID       *uint
+	Title        *string
+}
+
+// SyncLinks 保存笔记后重新解析内容中的链接（在事务中删除旧链接再写入；按标题链接的目标不存在时记为断链）
+func (s *LinkService) SyncLinks(note *model.Note) error {
+	var rows []model.NoteLink
+	for _, link := range wikilink.Parse(note.Content) {
+		row := model.NoteLink{UserID: note.UserID, SourceNoteID: note.ID}
+		if link.NoteID > 0 {
+			if link.NoteID == note.ID {
+				continue // 忽略指向自身的链接
+			}
+			targetID := link.NoteID
+			row.TargetNoteID = &targetID
+		} else {
+			if link.Title == note.Title {
+				continue
+			}
+			if runes := []rune(link.Title); len(runes) > 100 {
+				link.Title = string(runes[:100]) // 标题最多100位，超长的链接必然是断链
+			}
+			targetID, err := s.findByTitle(note.UserID, link.Title, 0)
+			if err != nil {
+				return err
+			}
+			row.TargetTitle = link.Title
+			if targetID > 0 {
+				row.TargetNoteID = &targetID
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	// 链接随内容整体替换，无需保留软删除记录
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("source_note_id = ?", note.ID).Delete(&model.NoteLink{}).Error; err != nil {
+			return err
+		}
+		if len(rows) > 0 {
+			return tx.Create(&rows).Error
+		}
+		return nil
+	})
+	if err != nil {
+		zap.S().Errorf("保存笔记链接失败: %v", err)
+		return errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+	return nil
+}
+
+// findByTitle 按标题查找用户的笔记（同名时取最早创建的一条，excludeID 用于排除指定笔记），未找到返回 0
+func (s *LinkService) findByTitle(userID uint, title string, excludeID uint) (uint, error) {
+	var note model.Note
+	err := s.db.Select("id").Where("user_id = ? AND title = ? AND id <> ?", userID, title, excludeID).
+		Order("id ASC").Limit(1).Find(&note).Error
+	if err != nil {
+		zap.S().Errorf("按标题查询笔记失败: %v", err)
+		return 0, errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+	return note.ID, nil
+}
+
+// ResolveTitle 把指向该标题的断链关联到新笔记（创建笔记或修改标题后调用）
+func (s *LinkService) ResolveTitle(userID, noteID uint, title string) error {
+	err := s.db.Model(&model.NoteLink{}).
+		Where("user_id = ? AND target_title = ? AND target_note_id IS NULL AND source_note_id <> ?", userID, title, noteID).
+		Update("target_note_id", noteID).Error
+	if err != nil {
+		zap.S().Errorf("关联断链失败: %v", err)
+		return errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+	return nil
+}
+
+// RenameTitle 笔记标题修改后，把其他笔记中指向它的 [[旧标题]] 改写为 [[新标题]]，保证链接不失效
+// 改写在一个事务中完成，不修改这些笔记的更新时间，完成后重建它们的搜索索引
+func (s *LinkService) RenameTitle(userID, noteID uint, oldTitle, newTitle string) error {
+	if oldTitle == newTitle {
+		return nil
+	}
+
+	// 1. 找出按旧标题链接到该笔记的笔记
+	var sourceIDs []uint
+	err := s.db.Model(&model.NoteLink{}).Distinct("source_note_id").
+		Where("user_id = ? AND target_note_id = ? AND target_title = ?", userID, noteID, oldTitle).
+		Pluck("source_note_id", &sourceIDs).Error
+	if err != nil {
+		zap.S().Errorf("查询反链失败: %v", err)
+		return errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+
+	// 2. 改写内容并刷新渲染缓存
+	if len(sourceIDs) > 0 {
+		var notes []model.Note
+		if err := s.db.Select("id, content, content_format").Where("user_id = ? AND id IN ?", userID, sourceIDs).Find(&notes).Error; err != nil {
+			zap.S().Errorf("查询链接笔记失败: %v", err)
+			return errors.New(errcode.GetMsg(errcode.ServerError))
+		}
+		for i := range notes {
+			notes[i].Content = wikilink.Rename(notes[i].Content, oldTitle, newTitle)
+			if err := prepareContent(&notes[i]); err != nil {
+				return err
+			}
+		}
+		err = s.db.Transaction(func(tx *gorm.DB) error {
+			for i := range notes {
+				err := tx.Model(&model.Note{}).Where("id = ?", notes[i].ID).UpdateColumns(map[string]interface{}{
+					"content":       notes[i].Content,
+					"rendered_html": notes[i].RenderedHTML,
+					"plain_text":    notes[i].PlainText,
+				}).Error
+				if err != nil {
+					return err
+				}
+			}
+			return tx.Model(&model.NoteLink{}).
+				Where("user_id = ? AND target_note_id = ? AND target_title = ?", userID, noteID, oldTitle).
+				Update("target_title", newTitle).Error
+		})
+		if err != nil {
+			zap.S().Errorf("改写笔记链接失败: %v", err)
+			return errors.New(errcode.GetMsg(errcode.ServerError))
+		}
+		if err := s.indexService.ReindexNotes(sourceIDs); err != nil {
+			return err
+		}
+	}
+
+	// 3. 指向新标题的断链关联到该笔记
+	return s.ResolveTitle(userID, noteID, newTitle)
+}
+
+// RemoveNote 删除笔记时清理链接：删除它的出链；按标题指向它的链接改为指向同名的其他笔记（没有则成为断链）
+// 按ID指向它的链接保留，在断链报告中体现
+func (s *LinkService) RemoveNote(userID, noteID uint, title string) error {
+	if err := s.db.Unscoped().Where("source_note_id = ?", noteID).Delete(&model.NoteLink{}).Error; err != nil {
+		zap.S().Errorf("删除笔记链接失败: %v", err)
+		return errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+
+	targetID, err := s.findByTitle(userID, title, noteID)
+	if err != nil {
+		return err
+	}
+	var target interface{} // 没有同名笔记时置空
+	if targetID > 0 {
+		target = targetID
+	}
+	err = s.db.Model(&model.NoteLink{}).
+		Where("user_id = ? AND target_note_id = ? AND target_title <> ''", userID, noteID).
+		Update("target_note_id", target).Error
+	if err != nil {
+		zap.S().Errorf("更新反链失败: %v", err)
+		return errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+	return nil
+}
+
+// GetLinks 查询笔记的出链和反链
+func (s *LinkService) GetLinks(userID, noteID uint) (outgoing, backlinks []model.LinkedNote, err error) {
+	// 1. 出链（目标笔记不存在、已删除或不属于当前用户时为断链）
+	var rows []linkRow
+	err = s.db.Table("note_links AS l").
+		Select("l.target_title, l.target_note_id, t.id AS note_id, t.title").
+		Joins("LEFT JOIN notes AS t ON t.id = l.target_note_id AND t.user_id = l.user_id AND t.deleted_at IS NULL").
+		Where("l.user_id = ? AND l.source_note_id = ? AND l.deleted_at IS NULL", userID, noteID).
+		Order("l.id ASC").Scan(&rows).Error
+	if err != nil {
+		zap.S().Errorf("查询笔记出链失败: %v", err)
+		return nil, nil, errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+	outgoing = make([]model.LinkedNote, 0, len(rows))
+	for _, row := range rows {
+		if row.NoteID == nil {
+			outgoing = append(outgoing, model.LinkedNote{Title: brokenTitle(row), Broken: true})
+			continue
+		}
+		outgoing = append(outgoing, model.LinkedNote{NoteID: *row.NoteID, Title: *row.Title})
+	}
+
+	// 2. 反链
+	err = s.db.Table("note_links AS l").
+		Select("DISTINCT s.id AS note_id, s.title").
+		Joins("JOIN notes AS s ON s.id = l.source_note_id AND s.deleted_at IS NULL").
+		Where("l.user_id = ? AND l.target_note_id = ? AND l.deleted_at IS NULL", userID, noteID).
+		Order("s.id ASC").Scan(&backlinks).Error
+	if err != nil {
+		zap.S().Errorf("查询笔记反链失败: %v", err)
+		return nil, nil, errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+	if backlinks == nil {
+		backlinks = []model.LinkedNote{}
+	}
+	return outgoing, backlinks, nil
+}
+
+// GetBrokenLinks 查询用户所有笔记中的断链
+func (s *LinkService) GetBrokenLinks(userID uint) ([]model.BrokenLink, error) {
+	var rows []struct {
+		SourceNoteID uint
+		SourceTitle  string
+		TargetTitle  string
+		TargetNoteID *uint
+	}
+	err := s.db.Table("note_links AS l").
+		Select("l.source_note_id, s.title AS source_title, l.target_title, l.target_note_id").
+		Joins("JOIN notes AS s ON s.id = l.source_note_id AND s.deleted_at IS NULL").
+		Joins("LEFT JOIN notes AS t ON t.id = l.target_note_id AND t.user_id = l.user_id AND t.deleted_at IS NULL").
+		Where("l.user_id = ? AND l.deleted_at IS NULL AND t.id IS NULL", userID).
+		Order("l.source_note_id ASC, l.id ASC").Scan(&rows).Error
+	if err != nil {
+		zap.S().Errorf("查询断链失败: %v", err)
+		return nil, errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+
+	links := make([]model.BrokenLink, 0, len(rows))
+	for _, row := range rows {
+		link := model.BrokenLink{SourceNoteID: row.SourceNoteID, SourceTitle: row.SourceTitle, TargetTitle: row.TargetTitle}
+		if row.TargetTitle == "" && row.TargetNoteID != nil {
+			link.TargetNoteID = *row.TargetNoteID
+		}
+		links = append(links, link)
+	}
+	return links, nil
+}
+
+// RebuildLinks 为还没有链接记录的用户补全链接（如升级前创建的笔记），启动时在后台执行
+func (s *LinkService) RebuildLinks() {
+	var userIDs []uint
+	err := s.db.Model(&model.Note{}).Distinct("user_id").
+		Where("user_id NOT IN (SELECT DISTINCT user_id FROM note_links)").Pluck("user_id", &userIDs).Error
+	if err != nil {
+		zap.S().Errorf("补全笔记链接失败: %v", err)
+		return
+	}
+	if len(userIDs) == 0 {
+		return
+	}
+
+	var notes []model.Note
+	result := s.db.Select("id, user_id, title, content").Where("user_id IN ?", userIDs).
+		FindInBatches(&notes, 100, func(tx *gorm.DB, batch int) error {
+			for i := range notes {
+				if err := s.SyncLinks(&notes[i]); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	if result.Error != nil {
+		zap.S().Errorf("补全笔记链接失败: %v", result.Error)
+	}
+}
+
+// brokenTitle 断链的展示标题（按ID链接时显示为 #ID）
+func brokenTitle(row linkRow) string {
+	if row.TargetTitle == "" && row.TargetNoteID != nil {
+		return "#" + strconv.FormatUint(uint64(*row.TargetNoteID), 10)
+	}
+	return row.TargetTitle
+}
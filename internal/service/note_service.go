@@ -3,41 +3,312 @@ package service
 import (
 	"errors"
 	"fmt"
+	"html"
 	"strings"
 
+	"github.com/JokerYuan-lang/MyNoteBook/internal/importer"
 	"github.com/JokerYuan-lang/MyNoteBook/internal/model"
 	"github.com/JokerYuan-lang/MyNoteBook/pkg/errcode"
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/render"
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/storage"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
 
 // NoteService 笔记业务逻辑
 type NoteService struct {
-	db *gorm.DB
+	db                  *gorm.DB
+	notificationService *NotificationService
+	webhookService      *WebhookService
+	linkService         *LinkService
+	indexService        *IndexService
+	tagService          *TagService
+	relatedService      *RelatedService
+	pending             *[]func() // 事务中推迟到提交后执行的操作（不在事务中时为 nil）
 }
 
 // NewNoteService 创建 NoteService 实例
-func NewNoteService(db *gorm.DB) *NoteService {
-	return &NoteService{db: db}
+func NewNoteService(db *gorm.DB, notificationService *NotificationService, webhookService *WebhookService, linkService *LinkService, indexService *IndexService, tagService *TagService, relatedService *RelatedService) *NoteService {
+	return &NoteService{
+		db:                  db,
+		notificationService: notificationService,
+		webhookService:      webhookService,
+		linkService:         linkService,
+		indexService:        indexService,
+		tagService:          tagService,
+		relatedService:      relatedService,
+	}
+}
+
+// Transaction 在一个数据库事务中执行 fn：fn 收到的 NoteService 及其链接、索引、向量服务都使用该事务，
+// @ 通知和 Webhook 推迟到提交后发送，回滚时不发送
+func (s *NoteService) Transaction(fn func(tx *NoteService) error) error {
+	var pending []func()
+	err := s.db.Transaction(func(db *gorm.DB) error {
+		indexService := &IndexService{db: db, tokenizer: s.indexService.tokenizer}
+		tx := *s
+		tx.db = db
+		tx.indexService = indexService
+		tx.linkService = &LinkService{db: db, indexService: indexService}
+		tx.relatedService = &RelatedService{db: db, rdb: s.relatedService.rdb}
+		tx.pending = &pending
+		return fn(&tx)
+	})
+	if err != nil {
+		return err
+	}
+	for _, fn := range pending {
+		fn()
+	}
+	return nil
 }
 
-// CreateNote 创建笔记（含标签）
-func (s *NoteService) CreateNote(userID uint, title, content, category string, tagNames []string) error {
-	// 1. 创建笔记
+// afterCommit 在事务中时推迟到提交后执行 fn，否则立即执行
+func (s *NoteService) afterCommit(fn func()) {
+	if s.pending != nil {
+		*s.pending = append(*s.pending, fn)
+		return
+	}
+	fn()
+}
+
+// CreateNote 创建笔记（含标签），返回新笔记ID（format 为空时按 Markdown 处理）
+func (s *NoteService) CreateNote(userID uint, title, content, format, category string, tagNames []string) (uint, error) {
+	// 1. 创建笔记（分类为笔记本路径，不存在时自动创建；HTML 内容先按白名单过滤，同时生成渲染缓存）
+	notebook, err := ensureNotebook(s.db, userID, category)
+	if err != nil {
+		return 0, err
+	}
 	note := model.Note{
-		Title:    title,
-		Content:  content,
-		Category: category,
-		UserID:   userID,
+		Title:         title,
+		Content:       content,
+		ContentFormat: format,
+		Category:      notebook.Path,
+		NotebookID:    notebook.ID,
+		UserID:        userID,
+	}
+	if err := prepareContent(&note); err != nil {
+		return 0, err
 	}
 	if err := s.db.Create(&note).Error; err != nil {
 		zap.S().Errorf("创建笔记失败: %v", err)
-		return fmt.Errorf(errcode.GetMsg(errcode.ServerError))
+		return 0, errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+
+	// 2. 处理标签（加上自动打标签规则命中的标签；不存在则创建，已存在则关联）
+	ruleTags, err := s.tagService.MatchTagRules(userID, note.Title, *note.PlainText)
+	if err != nil {
+		return 0, err
+	}
+	tagNames = append(tagNames, ruleTags...)
+	tags, err := s.findOrCreateTags(userID, tagNames)
+	if err != nil {
+		return 0, err
+	}
+
+	// 3. 关联笔记和标签（多对多）
+	if err := s.db.Model(&note).Association("Tags").Replace(&tags); err != nil {
+		zap.S().Errorf("关联标签失败: %v", err)
+		return 0, errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+
+	// 4. 解析笔记链接，并关联指向该标题的断链；建立搜索索引
+	if err := s.syncLinks(&note, ""); err != nil {
+		return 0, err
+	}
+	if err := s.indexService.IndexNote(&note, tagNames); err != nil {
+		return 0, err
+	}
+
+	// 5. 通知内容中 @ 到的用户，并推送 Webhook（在事务中时推迟到提交后）
+	s.afterCommit(func() {
+		s.notificationService.NotifyMentions(userID, &note, "")
+		s.webhookService.Dispatch(model.WebhookEventNoteCreated, &note, tagNames)
+	})
+
+	return note.ID, nil
+}
+
+// ImportNote 导入一条笔记（保留原始创建/更新时间；与已有笔记标题和内容相同，或同一内容已导入过时跳过）
+// 返回新笔记ID，跳过时 skipped 为 true 并返回已有笔记ID。导入不会触发 @ 通知和 Webhook
+func (s *NoteService) ImportNote(userID uint, n *importer.Note) (noteID uint, skipped bool, err error) {
+	// 1. 去重：同一用户下标题相同，且导入时的原始内容或当前内容相同视为重复
+	// 导入后笔记间链接会被改写，所以先按导入时记录的原始内容哈希比较；导出的 Markdown 文件末尾会补换行，比较时忽略
+	trimmed := strings.TrimRight(n.Content, "\n")
+	importHash := storage.Hash([]byte(trimmed))
+	var existing model.Note
+	err = s.db.Select("id").Where("user_id = ? AND title = ? AND (import_hash = ? OR content IN ?)", userID, n.Title, importHash, []string{n.Content, trimmed}).
+		Limit(1).Find(&existing).Error
+	if err != nil {
+		zap.S().Errorf("查询重复笔记失败: %v", err)
+		return 0, false, errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+	if existing.ID > 0 {
+		return existing.ID, true, nil
+	}
+
+	// 2. 创建笔记（CreatedAt/UpdatedAt 为零值时由 GORM 填充当前时间）
+	notebook, err := ensureNotebook(s.db, userID, n.Category)
+	if err != nil {
+		return 0, false, err
+	}
+	note := model.Note{
+		Title:         n.Title,
+		Content:       n.Content,
+		ContentFormat: render.FormatMarkdown,
+		Category:      notebook.Path,
+		NotebookID:    notebook.ID,
+		UserID:        userID,
+		ImportHash:    importHash,
+	}
+	note.CreatedAt = n.CreatedAt
+	note.UpdatedAt = n.UpdatedAt
+	if err := prepareContent(&note); err != nil {
+		return 0, false, err
+	}
+	if err := s.db.Create(&note).Error; err != nil {
+		zap.S().Errorf("导入笔记失败: %v", err)
+		return 0, false, errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+
+	// 3. 关联标签（加上自动打标签规则命中的标签）
+	ruleTags, err := s.tagService.MatchTagRules(userID, note.Title, *note.PlainText)
+	if err != nil {
+		return 0, false, err
+	}
+	tagNames := append(append([]string{}, n.Tags...), ruleTags...)
+	tags, err := s.findOrCreateTags(userID, tagNames)
+	if err != nil {
+		return 0, false, err
+	}
+	if len(tags) > 0 {
+		if err := s.db.Model(&note).Association("Tags").Replace(&tags); err != nil {
+			zap.S().Errorf("关联标签失败: %v", err)
+			return 0, false, errors.New(errcode.GetMsg(errcode.ServerError))
+		}
+	}
+
+	// 4. 解析笔记链接，建立搜索索引
+	if err := s.syncLinks(&note, ""); err != nil {
+		return 0, false, err
+	}
+	if err := s.indexService.IndexNote(&note, tagNames); err != nil {
+		return 0, false, err
 	}
 
-	// 2. 处理标签（不存在则创建，已存在则关联）
-	var tags []model.Tag
+	return note.ID, false, nil
+}
+
+// UpdateImportedContent 改写导入笔记的内容（用于导入完成后改写笔记间链接，不修改更新时间）
+func (s *NoteService) UpdateImportedContent(userID, noteID uint, content string) error {
+	note := model.Note{Content: content, ContentFormat: render.FormatMarkdown}
+	if err := prepareContent(&note); err != nil {
+		return err
+	}
+	err := s.db.Model(&model.Note{}).Where("user_id = ? AND id = ?", userID, noteID).UpdateColumns(map[string]interface{}{
+		"content":       note.Content,
+		"rendered_html": note.RenderedHTML,
+		"plain_text":    note.PlainText,
+	}).Error
+	if err != nil {
+		zap.S().Errorf("改写导入笔记内容失败: %v", err)
+		return errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+
+	// 改写后的 note:// 链接重新解析（只改写了链接地址，纯文本不变，无需重建搜索索引）
+	note.ID = noteID
+	note.UserID = userID
+	return s.linkService.SyncLinks(&note)
+}
+
+// RebuildContentCache 为没有渲染缓存的笔记（如升级前创建的笔记）补全缓存，启动时在后台执行
+// 处理失败的笔记按纯文本缓存原内容，避免每次启动都重试
+func (s *NoteService) RebuildContentCache() {
+	var notes []model.Note
+	result := s.db.Select("id, content, content_format").Where("rendered_html IS NULL").
+		FindInBatches(&notes, 100, func(tx *gorm.DB, batch int) error {
+			for i := range notes {
+				if err := prepareContent(&notes[i]); err != nil {
+					zap.S().Warnf("笔记 %d 渲染失败，按纯文本缓存: %v", notes[i].ID, err)
+					rendered := "<pre>" + html.EscapeString(notes[i].Content) + "</pre>"
+					plain := notes[i].Content
+					notes[i].RenderedHTML, notes[i].PlainText = &rendered, &plain
+				}
+				// UpdateColumns 不修改更新时间
+				err := s.db.Model(&model.Note{}).Where("id = ?", notes[i].ID).UpdateColumns(map[string]interface{}{
+					"content":       notes[i].Content,
+					"rendered_html": notes[i].RenderedHTML,
+					"plain_text":    notes[i].PlainText,
+				}).Error
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	if result.Error != nil {
+		zap.S().Errorf("补全笔记渲染缓存失败: %v", result.Error)
+	}
+}
+
+// prepareContent 按内容格式处理笔记：HTML 内容按白名单过滤后保存，并生成渲染缓存和纯文本缓存
+func prepareContent(note *model.Note) error {
+	if note.ContentFormat == "" {
+		note.ContentFormat = render.FormatMarkdown
+	}
+	if !render.ValidFormat(note.ContentFormat) {
+		return fmt.Errorf("不支持的内容格式: %s", note.ContentFormat)
+	}
+	if note.ContentFormat == render.FormatHTML {
+		note.Content = render.Sanitize(note.Content)
+	}
+
+	html, err := render.Content(note.ContentFormat, note.Content)
+	if err != nil {
+		zap.S().Errorf("渲染笔记失败: %v", err)
+		return errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+	plain := render.PlainText(html)
+	note.RenderedHTML = &html
+	note.PlainText = &plain
+	return nil
+}
+
+// syncLinks 保存笔记后解析内容中的笔记链接；新建笔记或标题变化时（oldTitle 为修改前标题，新建时为空）
+// 同步其他笔记中按标题指向它的链接
+func (s *NoteService) syncLinks(note *model.Note, oldTitle string) error {
+	if err := s.linkService.SyncLinks(note); err != nil {
+		return err
+	}
+	if oldTitle == "" {
+		return s.linkService.ResolveTitle(note.UserID, note.ID, note.Title)
+	}
+	return s.linkService.RenameTitle(note.UserID, note.ID, oldTitle, note.Title)
+}
+
+// excerpt 截取纯文本开头作为列表摘要
+func excerpt(plain string) string {
+	plain = strings.Join(strings.Fields(plain), " ")
+	if runes := []rune(plain); len(runes) > 120 {
+		return string(runes[:120]) + "…"
+	}
+	return plain
+}
+
+// findOrCreateTags 按名称查找用户的标签，不存在则创建
+// 创建、更新和导入共用：标签名统一规范化（去掉首尾及各级之间的空格），忽略空名称和重复名称
+func (s *NoteService) findOrCreateTags(userID uint, tagNames []string) ([]model.Tag, error) {
+	var (
+		tags []model.Tag
+		seen = make(map[string]bool)
+	)
 	for _, name := range tagNames {
+		name = normalizeTagName(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+
 		var tag model.Tag
 		// 按用户ID+标签名查询（确保标签用户隔离）
 		err := s.db.Where("user_id = ? AND name = ?", userID, name).First(&tag).Error
@@ -47,143 +318,373 @@ func (s *NoteService) CreateNote(userID uint, title, content, category string, t
 				tag = model.Tag{Name: name, UserID: userID}
 				if err := s.db.Create(&tag).Error; err != nil {
 					zap.S().Errorf("创建标签失败: %v", err)
-					return fmt.Errorf(errcode.GetMsg(errcode.ServerError))
+					return nil, errors.New(errcode.GetMsg(errcode.ServerError))
 				}
 			} else {
 				zap.S().Errorf("查询标签失败: %v", err)
-				return fmt.Errorf(errcode.GetMsg(errcode.ServerError))
+				return nil, errors.New(errcode.GetMsg(errcode.ServerError))
 			}
 		}
 		tags = append(tags, tag)
 	}
+	return tags, nil
+}
 
-	// 3. 关联笔记和标签（多对多）
-	if err := s.db.Model(&note).Association("Tags").Replace(&tags); err != nil {
-		zap.S().Errorf("关联标签失败: %v", err)
-		return fmt.Errorf(errcode.GetMsg(errcode.ServerError))
-	}
+// 笔记列表的归档筛选方式
+const (
+	ArchivedExclude = "exclude" // 不含已归档笔记（笔记列表默认）
+	ArchivedInclude = "include" // 包含已归档笔记
+	ArchivedOnly    = "only"    // 只看已归档笔记
+)
 
-	return nil
+// NoteListFilter 笔记列表筛选条件（零值表示不筛选）
+type NoteListFilter struct {
+	Category     string                    // 笔记本路径（包含下级笔记本）
+	Tag          string                    // 标签（包含下级标签）
+	HasOpenItems bool                      // 只看有未完成清单项的笔记
+	Pinned       bool                      // 只看置顶笔记
+	Favorite     bool                      // 只看收藏笔记
+	Archived     string                    // 归档筛选（ArchivedExclude/ArchivedOnly，空值或 ArchivedInclude 不筛选）
+	Keyword      string                    // 关键词（按分词匹配搜索索引，或标题包含）
+	Scopes       []func(*gorm.DB) *gorm.DB // 附加查询条件（如搜索语句编译结果）
+}
+
+// noteListQuery 按筛选条件构建笔记查询（列名带表名前缀，便于统计分面时联表）
+func (s *NoteService) noteListQuery(userID uint, filter NoteListFilter) *gorm.DB {
+	// 构建查询条件（用户ID必选，分类可选）
+	db := s.db.Model(&model.Note{}).Where("notes.user_id = ?", userID)
+	if category := strings.TrimSpace(filter.Category); category != "" {
+		cond, args := notebookCondition("notes.category", category)
+		db = db.Where(cond, args...)
+	}
+	if tag := normalizeTagName(filter.Tag); tag != "" {
+		cond, args := tagCondition("tags.name", tag)
+		db = db.Where("EXISTS (SELECT 1 FROM note_tags JOIN tags ON tags.id = note_tags.tag_id AND tags.deleted_at IS NULL WHERE note_tags.note_id = notes.id AND "+cond+")", args...)
+	}
+	if keyword := strings.TrimSpace(filter.Keyword); keyword != "" {
+		cond, args := s.indexService.KeywordCondition(keyword)
+		db = db.Where(cond, args...)
+	}
+	if filter.HasOpenItems {
+		db = db.Where("EXISTS (SELECT 1 FROM checklist_items WHERE checklist_items.note_id = notes.id AND checklist_items.done = ? AND checklist_items.deleted_at IS NULL)", false)
+	}
+	if filter.Pinned {
+		db = db.Where("notes.pinned = ?", true)
+	}
+	if filter.Favorite {
+		db = db.Where("notes.favorite = ?", true)
+	}
+	switch filter.Archived {
+	case ArchivedExclude:
+		db = db.Where("notes.archived = ?", false)
+	case ArchivedOnly:
+		db = db.Where("notes.archived = ?", true)
+	}
+	return db.Scopes(filter.Scopes...)
 }
 
-// GetNoteList 分页查询笔记列表（支持分类筛选）
-func (s *NoteService) GetNoteList(userID uint, page, pageSize int, category string) ([]model.Note, int64, error) {
+// GetNoteList 分页查询笔记列表（支持分类、未完成清单、关键词等筛选；置顶笔记在前；不返回渲染缓存）
+func (s *NoteService) GetNoteList(userID uint, page, pageSize int, filter NoteListFilter) ([]model.Note, int64, error) {
 	var (
 		notes []model.Note
 		total int64
 	)
-	category = strings.TrimSpace(category)
-	// 构建查询条件（用户ID必选，分类可选）
-	db := s.db.Model(&model.Note{}).Where("user_id = ?", userID).Preload("Tags") // Preload 关联查询标签
-	if category != "" {
-		db = db.Where("category = ?", category)
-	}
+	// 列表不返回正文和渲染缓存，只用纯文本缓存生成摘要；Preload 关联查询标签
+	db := s.noteListQuery(userID, filter).Omit("content", "rendered_html").Preload("Tags")
 
 	// 统计总数
 	if err := db.Count(&total).Error; err != nil {
 		zap.S().Errorf("统计笔记总数失败: %v", err)
-		return nil, 0, fmt.Errorf(errcode.GetMsg(errcode.ServerError))
+		return nil, 0, errors.New(errcode.GetMsg(errcode.ServerError))
 	}
 
-	// 分页查询（offset = (page-1)*pageSize）
+	// 分页查询（offset = (page-1)*pageSize；置顶笔记在前，其余按更新时间倒序）
 	offset := (page - 1) * pageSize
-	if err := db.Offset(offset).Limit(pageSize).Order("updated_at DESC").Find(&notes).Error; err != nil {
+	if err := db.Offset(offset).Limit(pageSize).Order("pinned DESC, updated_at DESC").Find(&notes).Error; err != nil {
 		zap.S().Errorf("查询笔记列表失败: %v", err)
-		return nil, 0, fmt.Errorf(errcode.GetMsg(errcode.ServerError))
+		return nil, 0, errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+
+	// 统计每条笔记的清单完成情况，生成摘要
+	if err := s.fillChecklistCounts(notes); err != nil {
+		return nil, 0, err
+	}
+	for i := range notes {
+		if notes[i].PlainText != nil {
+			notes[i].Excerpt = excerpt(*notes[i].PlainText)
+			notes[i].PlainText = nil
+		}
 	}
 
 	return notes, total, nil
 }
 
-// GetNoteByID 查询单条笔记
+// GetNoteFacets 统计符合筛选条件的笔记按分类、标签和更新月份的数量（按数量或月份倒序）
+func (s *NoteService) GetNoteFacets(userID uint, filter NoteListFilter) (*model.NoteFacets, error) {
+	facets := &model.NoteFacets{Categories: []model.FacetCount{}, Tags: []model.FacetCount{}, Months: []model.FacetCount{}}
+	err := s.noteListQuery(userID, filter).Select("notes.category AS value, COUNT(*) AS count").
+		Group("notes.category").Order("count DESC, value ASC").Scan(&facets.Categories).Error
+	if err != nil {
+		zap.S().Errorf("统计分类分面失败: %v", err)
+		return nil, errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+
+	err = s.noteListQuery(userID, filter).Select("tags.name AS value, COUNT(*) AS count").
+		Joins("JOIN note_tags ON note_tags.note_id = notes.id").
+		Joins("JOIN tags ON tags.id = note_tags.tag_id AND tags.deleted_at IS NULL").
+		Group("tags.name").Order("count DESC, value ASC").Scan(&facets.Tags).Error
+	if err != nil {
+		zap.S().Errorf("统计标签分面失败: %v", err)
+		return nil, errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+
+	err = s.noteListQuery(userID, filter).Select("DATE_FORMAT(notes.updated_at, '%Y-%m') AS value, COUNT(*) AS count").
+		Group("value").Order("value DESC").Scan(&facets.Months).Error
+	if err != nil {
+		zap.S().Errorf("统计月份分面失败: %v", err)
+		return nil, errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+	return facets, nil
+}
+
+// fillChecklistCounts 批量统计笔记的清单项总数和已完成数
+func (s *NoteService) fillChecklistCounts(notes []model.Note) error {
+	if len(notes) == 0 {
+		return nil
+	}
+	ids := make([]uint, 0, len(notes))
+	for _, note := range notes {
+		ids = append(ids, note.ID)
+	}
+
+	var counts []struct {
+		NoteID uint
+		Total  int
+		Done   int
+	}
+	err := s.db.Model(&model.ChecklistItem{}).
+		Select("note_id, COUNT(*) AS total, SUM(CASE WHEN done THEN 1 ELSE 0 END) AS done").
+		Where("note_id IN ?", ids).Group("note_id").Scan(&counts).Error
+	if err != nil {
+		zap.S().Errorf("统计清单项失败: %v", err)
+		return errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+
+	byNote := make(map[uint]int, len(counts))
+	for i, c := range counts {
+		byNote[c.NoteID] = i
+	}
+	for i := range notes {
+		if idx, ok := byNote[notes[i].ID]; ok {
+			notes[i].ChecklistTotal = counts[idx].Total
+			notes[i].ChecklistDone = counts[idx].Done
+		}
+	}
+	return nil
+}
+
+// GetNoteByID 查询单条笔记（含出链和反链）
 func (s *NoteService) GetNoteByID(userID, noteID uint) (*model.Note, error) {
 	var note model.Note
-	err := s.db.Where("user_id = ? AND id = ?", userID, noteID).Preload("Tags").First(&note).Error
+	err := s.db.Where("user_id = ? AND id = ?", userID, noteID).Preload("Tags").
+		Preload("ChecklistItems", func(db *gorm.DB) *gorm.DB {
+			return db.Order("position ASC, id ASC")
+		}).First(&note).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, fmt.Errorf(errcode.GetMsg(errcode.NotFound))
+			return nil, errors.New(errcode.GetMsg(errcode.NotFound))
 		}
 		zap.S().Errorf("查询笔记失败: %v", err)
-		return nil, fmt.Errorf(errcode.GetMsg(errcode.ServerError))
+		return nil, errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+
+	// 出链和反链
+	note.OutgoingLinks, note.Backlinks, err = s.linkService.GetLinks(userID, noteID)
+	if err != nil {
+		return nil, err
 	}
 	return &note, nil
 }
 
-// UpdateNote 更新笔记（含标签）
-func (s *NoteService) UpdateNote(userID, noteID uint, title, content, category string, tagNames []string) error {
+// RenderNote 把笔记内容渲染为经过白名单过滤的 HTML（优先使用渲染缓存）
+func (s *NoteService) RenderNote(userID, noteID uint) (string, error) {
+	note, err := s.GetNoteByID(userID, noteID)
+	if err != nil {
+		return "", err
+	}
+	if note.RenderedHTML != nil {
+		return *note.RenderedHTML, nil
+	}
+	html, err := render.Content(note.ContentFormat, note.Content)
+	if err != nil {
+		zap.S().Errorf("渲染笔记失败: %v", err)
+		return "", errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+	return html, nil
+}
+
+// UpdateNote 更新笔记（含标签；format 为空时保持原格式）
+func (s *NoteService) UpdateNote(userID, noteID uint, title, content, format, category string, tagNames []string) error {
 	// 1. 检查笔记是否存在（且属于当前用户）
 	var note model.Note
 	err := s.db.Where("user_id = ? AND id = ?", userID, noteID).First(&note).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return fmt.Errorf(errcode.GetMsg(errcode.NotFound))
+			return errors.New(errcode.GetMsg(errcode.NotFound))
 		}
 		zap.S().Errorf("查询笔记失败: %v", err)
-		return fmt.Errorf(errcode.GetMsg(errcode.ServerError))
+		return errors.New(errcode.GetMsg(errcode.ServerError))
 	}
 
 	// 2. 更新笔记基本信息
+	notebook, err := ensureNotebook(s.db, userID, category)
+	if err != nil {
+		return err
+	}
+	oldContent, oldTitle := note.Content, note.Title
 	note.Title = title
 	note.Content = content
-	note.Category = category
+	note.Category = notebook.Path
+	note.NotebookID = notebook.ID
+	if format != "" {
+		note.ContentFormat = format
+	}
+	if err := prepareContent(&note); err != nil {
+		return err
+	}
 	if err := s.db.Save(&note).Error; err != nil {
 		zap.S().Errorf("更新笔记失败: %v", err)
-		return fmt.Errorf(errcode.GetMsg(errcode.ServerError))
+		return errors.New(errcode.GetMsg(errcode.ServerError))
 	}
 
-	// 3. 重新关联标签（先清空旧关联，再关联新标签）
-	var tags []model.Tag
-	for _, name := range tagNames {
-		var tag model.Tag
-		err := s.db.Where("user_id = ? AND name = ?", userID, name).First(&tag).Error
-		if err != nil {
-			if errors.Is(err, gorm.ErrRecordNotFound) {
-				tag = model.Tag{Name: name, UserID: userID}
-				if err := s.db.Create(&tag).Error; err != nil {
-					zap.S().Errorf("创建标签失败: %v", err)
-					return fmt.Errorf(errcode.GetMsg(errcode.ServerError))
-				}
-			} else {
-				zap.S().Errorf("查询标签失败: %v", err)
-				return fmt.Errorf(errcode.GetMsg(errcode.ServerError))
-			}
-		}
-		tags = append(tags, tag)
+	// 3. 重新关联标签（先清空旧关联，再关联新标签；自动打标签规则命中的标签一并加上）
+	ruleTags, err := s.tagService.MatchTagRules(userID, note.Title, *note.PlainText)
+	if err != nil {
+		return err
+	}
+	tagNames = append(tagNames, ruleTags...)
+	tags, err := s.findOrCreateTags(userID, tagNames)
+	if err != nil {
+		return err
 	}
 
 	// 替换标签关联
 	if err := s.db.Model(&note).Association("Tags").Replace(&tags); err != nil {
 		zap.S().Errorf("更新标签关联失败: %v", err)
-		return fmt.Errorf(errcode.GetMsg(errcode.ServerError))
+		return errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+
+	// 4. 重新解析笔记链接（标题变化时同步改写其他笔记中的 [[旧标题]]），重建搜索索引
+	if err := s.syncLinks(&note, oldTitle); err != nil {
+		return err
+	}
+	if err := s.indexService.IndexNote(&note, tagNames); err != nil {
+		return err
 	}
 
+	// 仅通知本次新增的 @ 用户
+	s.afterCommit(func() {
+		s.notificationService.NotifyMentions(userID, &note, oldContent)
+		s.webhookService.Dispatch(model.WebhookEventNoteUpdated, &note, tagNames)
+	})
+
 	return nil
 }
 
+// PinNote 置顶或取消置顶笔记（pinned 为 nil 时取反），返回新状态
+func (s *NoteService) PinNote(userID, noteID uint, pinned *bool) (bool, error) {
+	return s.setNoteState(userID, noteID, "pinned", pinned)
+}
+
+// FavoriteNote 收藏或取消收藏笔记（favorite 为 nil 时取反），返回新状态
+func (s *NoteService) FavoriteNote(userID, noteID uint, favorite *bool) (bool, error) {
+	return s.setNoteState(userID, noteID, "favorite", favorite)
+}
+
+// ArchiveNote 归档或取消归档笔记（archived 为 nil 时取反），返回新状态；归档时同时取消置顶
+func (s *NoteService) ArchiveNote(userID, noteID uint, archived *bool) (bool, error) {
+	return s.setNoteState(userID, noteID, "archived", archived)
+}
+
+// setNoteState 更新笔记的置顶/收藏/归档状态（不修改更新时间，避免改变列表顺序）
+func (s *NoteService) setNoteState(userID, noteID uint, column string, value *bool) (bool, error) {
+	var note model.Note
+	err := s.db.Select("id, pinned, favorite, archived").Where("user_id = ? AND id = ?", userID, noteID).First(&note).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, errors.New(errcode.GetMsg(errcode.NotFound))
+		}
+		zap.S().Errorf("查询笔记失败: %v", err)
+		return false, errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+
+	current := map[string]bool{"pinned": note.Pinned, "favorite": note.Favorite, "archived": note.Archived}[column]
+	next := !current
+	if value != nil {
+		next = *value
+	}
+	updates := map[string]interface{}{column: next}
+	if column == "archived" && next {
+		updates["pinned"] = false
+	}
+	if err := s.db.Model(&note).UpdateColumns(updates).Error; err != nil {
+		zap.S().Errorf("更新笔记状态失败: %v", err)
+		return false, errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+	return next, nil
+}
+
 // DeleteNote 删除笔记（含关联标签）
 func (s *NoteService) DeleteNote(userID, noteID uint) error {
 	// 1. 检查笔记是否存在
 	var note model.Note
-	err := s.db.Where("user_id = ? AND id = ?", userID, noteID).First(&note).Error
+	err := s.db.Where("user_id = ? AND id = ?", userID, noteID).Preload("Tags").First(&note).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return fmt.Errorf(errcode.GetMsg(errcode.NotFound))
+			return errors.New(errcode.GetMsg(errcode.NotFound))
 		}
 		zap.S().Errorf("查询笔记失败: %v", err)
-		return fmt.Errorf(errcode.GetMsg(errcode.ServerError))
+		return errors.New(errcode.GetMsg(errcode.ServerError))
 	}
 
 	// 2. 先删除笔记与标签的关联（多对多中间表）
 	if err := s.db.Model(&note).Association("Tags").Clear(); err != nil {
 		zap.S().Errorf("清空标签关联失败: %v", err)
-		return fmt.Errorf(errcode.GetMsg(errcode.ServerError))
+		return errors.New(errcode.GetMsg(errcode.ServerError))
 	}
 
-	// 3. 删除笔记
+	// 3. 删除笔记及其清单项
+	if err := s.db.Where("note_id = ?", note.ID).Delete(&model.ChecklistItem{}).Error; err != nil {
+		zap.S().Errorf("删除清单项失败: %v", err)
+		return errors.New(errcode.GetMsg(errcode.ServerError))
+	}
 	if err := s.db.Delete(&note).Error; err != nil {
 		zap.S().Errorf("删除笔记失败: %v", err)
-		return fmt.Errorf(errcode.GetMsg(errcode.ServerError))
+		return errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+
+	// 4. 清理笔记链接（指向它的标题链接改为指向同名笔记或成为断链）、搜索索引和语义向量
+	if err := s.linkService.RemoveNote(userID, note.ID, note.Title); err != nil {
+		return err
+	}
+	if err := s.indexService.RemoveNote(note.ID); err != nil {
+		return err
+	}
+	if err := s.indexService.RemoveEmbedding(note.ID); err != nil {
+		return err
+	}
+
+	// 5. 文档频率随删除变化，标记该用户的词向量待重算（新增、修改笔记时由更新时间触发）
+	if err := s.relatedService.MarkStale(userID); err != nil {
+		return err
+	}
+
+	// 6. 推送 Webhook（携带删除前的标签，便于按标签过滤）
+	tagNames := make([]string, 0, len(note.Tags))
+	for _, tag := range note.Tags {
+		tagNames = append(tagNames, tag.Name)
 	}
+	s.afterCommit(func() { s.webhookService.Dispatch(model.WebhookEventNoteDeleted, &note, tagNames) })
 
 	return nil
 }
@@ -0,0 +1,49 @@
+package service
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/JokerYuan-lang/MyNoteBook/internal/model"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+// dryRunDB 只生成 SQL、不连接数据库的 GORM 实例
+func dryRunDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(mysql.New(mysql.Config{DSN: "test:test@tcp(127.0.0.1:3306)/test", SkipInitializeWithVersion: true}),
+		&gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatalf("open dry-run db: %v", err)
+	}
+	return db
+}
+
+func TestNoteListArchivedFilter(t *testing.T) {
+	s := &NoteService{db: dryRunDB(t)}
+	tests := []struct {
+		archived string
+		want     string // 期望的归档条件（空表示不筛选）
+		vars     []interface{}
+	}{
+		{ArchivedExclude, "notes.archived = ?", []interface{}{uint(1), false}},
+		{ArchivedOnly, "notes.archived = ?", []interface{}{uint(1), true}},
+		{ArchivedInclude, "", []interface{}{uint(1)}},
+		{"", "", []interface{}{uint(1)}},
+	}
+	for _, tt := range tests {
+		stmt := s.noteListQuery(1, NoteListFilter{Archived: tt.archived}).Find(&[]model.Note{}).Statement
+		sql := stmt.SQL.String()
+		if tt.want == "" && strings.Contains(sql, "archived") {
+			t.Errorf("Archived=%q: unexpected archived condition in %s", tt.archived, sql)
+		}
+		if tt.want != "" && !strings.Contains(sql, tt.want) {
+			t.Errorf("Archived=%q: %s does not contain %q", tt.archived, sql, tt.want)
+		}
+		if !reflect.DeepEqual(stmt.Vars, tt.vars) {
+			t.Errorf("Archived=%q: vars = %v, want %v", tt.archived, stmt.Vars, tt.vars)
+		}
+	}
+}
@@ -0,0 +1,430 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/JokerYuan-lang/MyNoteBook/internal/model"
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/errcode"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	notebookMaxName = 50  // 笔记本名称最大长度（字）
+	notebookMaxPath = 255 // 笔记本完整路径最大长度（字）
+)
+
+// NotebookService 笔记本（分层级的分类）管理
+type NotebookService struct {
+	db          *gorm.DB
+	noteService *NoteService
+}
+
+// NewNotebookService 创建 NotebookService 实例
+func NewNotebookService(db *gorm.DB, noteService *NoteService) *NotebookService {
+	return &NotebookService{db: db, noteService: noteService}
+}
+
+// notebookNames 把笔记本路径切分为各级名称（去掉首尾空白和空的级别，为空时为默认笔记本）
+func notebookNames(path string) []string {
+	var names []string
+	for _, name := range strings.Split(path, model.NotebookPathSeparator) {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		names = []string{model.DefaultNotebookName}
+	}
+	return names
+}
+
+// joinNotebookPath 拼接上级路径和名称
+func joinNotebookPath(parentPath, name string) string {
+	if parentPath == "" {
+		return name
+	}
+	return parentPath + model.NotebookPathSeparator + name
+}
+
+// notebookCondition 按笔记本路径筛选笔记的条件（包含下级笔记本；column 为保存路径的列，如 notes.category）
+func notebookCondition(column, path string) (string, []interface{}) {
+	path = strings.Join(notebookNames(path), model.NotebookPathSeparator)
+	return "(" + column + " = ? OR " + column + " LIKE ?)", []interface{}{path, likeEscaper.Replace(path) + model.NotebookPathSeparator + "%"}
+}
+
+// validateNotebookName 检查笔记本名称
+func validateNotebookName(name string) error {
+	switch {
+	case name == "":
+		return errors.New("笔记本名称不能为空")
+	case strings.Contains(name, model.NotebookPathSeparator):
+		return fmt.Errorf("笔记本名称不能包含 %s", model.NotebookPathSeparator)
+	case len([]rune(name)) > notebookMaxName:
+		return fmt.Errorf("笔记本名称不能超过%d个字", notebookMaxName)
+	}
+	return nil
+}
+
+// validateNotebookPath 检查笔记本完整路径长度
+func validateNotebookPath(path string) error {
+	if len([]rune(path)) > notebookMaxPath {
+		return fmt.Errorf("笔记本路径不能超过%d个字: %s", notebookMaxPath, path)
+	}
+	return nil
+}
+
+// ensureNotebook 按路径（如「工作/项目A」）查找笔记本，不存在的各级自动创建；路径为空时使用默认笔记本。
+// 过长的名称截断（导入的笔记本名称可能很长）
+func ensureNotebook(db *gorm.DB, userID uint, path string) (*model.Notebook, error) {
+	var (
+		notebook   model.Notebook
+		parentID   uint
+		parentPath string
+	)
+	for _, name := range notebookNames(path) {
+		if runes := []rune(name); len(runes) > notebookMaxName {
+			name = strings.TrimSpace(string(runes[:notebookMaxName]))
+		}
+		fullPath := joinNotebookPath(parentPath, name)
+		if err := validateNotebookPath(fullPath); err != nil {
+			return nil, err
+		}
+
+		notebook = model.Notebook{}
+		query := db.Where("user_id = ? AND parent_id = ? AND name = ?", userID, parentID, name)
+		err := query.Attrs(model.Notebook{UserID: userID, ParentID: parentID, Name: name, Path: fullPath}).FirstOrCreate(&notebook).Error
+		if err != nil {
+			// 并发创建同名笔记本时唯一索引冲突，重新查询一次
+			if err := db.Where("user_id = ? AND parent_id = ? AND name = ?", userID, parentID, name).First(&notebook).Error; err != nil {
+				zap.S().Errorf("创建笔记本失败: %v", err)
+				return nil, errors.New(errcode.GetMsg(errcode.ServerError))
+			}
+		}
+		parentID, parentPath = notebook.ID, notebook.Path
+	}
+	return &notebook, nil
+}
+
+// GetNotebookTree 查询用户的笔记本树（同级按名称排序，附笔记数）
+func (s *NotebookService) GetNotebookTree(userID uint) ([]model.Notebook, error) {
+	var notebooks []model.Notebook
+	if err := s.db.Where("user_id = ?", userID).Order("name ASC").Find(&notebooks).Error; err != nil {
+		zap.S().Errorf("查询笔记本失败: %v", err)
+		return nil, errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+	var rows []struct {
+		NotebookID uint
+		Count      int
+	}
+	err := s.db.Model(&model.Note{}).Select("notebook_id, COUNT(*) AS count").
+		Where("user_id = ?", userID).Group("notebook_id").Scan(&rows).Error
+	if err != nil {
+		zap.S().Errorf("统计笔记本笔记数失败: %v", err)
+		return nil, errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+	counts := make(map[uint]int, len(rows))
+	for _, row := range rows {
+		counts[row.NotebookID] = row.Count
+	}
+
+	byParent := make(map[uint][]model.Notebook)
+	for _, notebook := range notebooks {
+		byParent[notebook.ParentID] = append(byParent[notebook.ParentID], notebook)
+	}
+	var build func(parentID uint) []model.Notebook
+	build = func(parentID uint) []model.Notebook {
+		nodes := []model.Notebook{}
+		for _, notebook := range byParent[parentID] {
+			notebook.NoteCount = counts[notebook.ID]
+			notebook.Children = build(notebook.ID)
+			notebook.TotalCount = notebook.NoteCount
+			for _, child := range notebook.Children {
+				notebook.TotalCount += child.TotalCount
+			}
+			nodes = append(nodes, notebook)
+		}
+		return nodes
+	}
+	return build(0), nil
+}
+
+// CreateNotebook 创建笔记本（parentID 为 0 时创建顶级笔记本）
+func (s *NotebookService) CreateNotebook(userID, parentID uint, name string) (*model.Notebook, error) {
+	name = strings.TrimSpace(name)
+	if err := validateNotebookName(name); err != nil {
+		return nil, err
+	}
+	parentPath := ""
+	if parentID > 0 {
+		parent, err := s.getNotebook(userID, parentID)
+		if err != nil {
+			return nil, err
+		}
+		parentPath = parent.Path
+	}
+	if err := s.checkSiblingName(userID, parentID, name, 0); err != nil {
+		return nil, err
+	}
+
+	notebook := model.Notebook{UserID: userID, ParentID: parentID, Name: name, Path: joinNotebookPath(parentPath, name)}
+	if err := validateNotebookPath(notebook.Path); err != nil {
+		return nil, err
+	}
+	if err := s.db.Create(&notebook).Error; err != nil {
+		zap.S().Errorf("创建笔记本失败: %v", err)
+		return nil, errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+	return &notebook, nil
+}
+
+// RenameNotebook 重命名笔记本（下级笔记本路径和笔记的分类同步更新）
+func (s *NotebookService) RenameNotebook(userID, notebookID uint, name string) error {
+	notebook, err := s.getNotebook(userID, notebookID)
+	if err != nil {
+		return err
+	}
+	name = strings.TrimSpace(name)
+	if err := validateNotebookName(name); err != nil {
+		return err
+	}
+	if name == notebook.Name {
+		return nil
+	}
+	if err := s.checkSiblingName(userID, notebook.ParentID, name, notebook.ID); err != nil {
+		return err
+	}
+
+	parentPath := strings.TrimSuffix(strings.TrimSuffix(notebook.Path, notebook.Name), model.NotebookPathSeparator)
+	return s.moveSubtree(userID, notebook, notebook.ParentID, name, joinNotebookPath(parentPath, name))
+}
+
+// MoveNotebook 移动笔记本到另一个笔记本下（parentID 为 0 时移到顶级），不能移到自身或下级笔记本下
+func (s *NotebookService) MoveNotebook(userID, notebookID, parentID uint) error {
+	notebook, err := s.getNotebook(userID, notebookID)
+	if err != nil {
+		return err
+	}
+	if parentID == notebook.ParentID {
+		return nil
+	}
+	parentPath := ""
+	if parentID > 0 {
+		parent, err := s.getNotebook(userID, parentID)
+		if err != nil {
+			return err
+		}
+		if parent.ID == notebook.ID || strings.HasPrefix(parent.Path, notebook.Path+model.NotebookPathSeparator) {
+			return errors.New("不能移动到自身或下级笔记本下")
+		}
+		parentPath = parent.Path
+	}
+	if err := s.checkSiblingName(userID, parentID, notebook.Name, notebook.ID); err != nil {
+		return err
+	}
+	return s.moveSubtree(userID, notebook, parentID, notebook.Name, joinNotebookPath(parentPath, notebook.Name))
+}
+
+// DeleteNotebook 删除笔记本：cascade 为 true 时连同下级笔记本和其中的笔记一起删除；
+// 否则下级笔记本和笔记移到上级笔记本（顶级笔记本的笔记移到默认笔记本）。默认笔记本不能删除
+// 全部修改在一个事务中完成，失败时整体回滚
+func (s *NotebookService) DeleteNotebook(userID, notebookID uint, cascade bool) error {
+	notebook, err := s.getNotebook(userID, notebookID)
+	if err != nil {
+		return err
+	}
+	if notebook.ParentID == 0 && notebook.Name == model.DefaultNotebookName {
+		return errors.New("默认笔记本不能删除")
+	}
+
+	return s.noteService.Transaction(func(tx *NoteService) error {
+		txs := &NotebookService{db: tx.db, noteService: tx}
+		if cascade {
+			return txs.deleteCascade(userID, notebook)
+		}
+		return txs.deleteAndReparent(userID, notebook)
+	})
+}
+
+// deleteCascade 删除笔记本、下级笔记本和其中的笔记
+func (s *NotebookService) deleteCascade(userID uint, notebook *model.Notebook) error {
+	// 1. 删除笔记本及下级笔记本中的笔记（同时清理标签关联、链接和索引）
+	subtree, err := s.subtree(userID, notebook)
+	if err != nil {
+		return err
+	}
+	ids := make([]uint, 0, len(subtree))
+	for _, item := range subtree {
+		ids = append(ids, item.ID)
+	}
+	var noteIDs []uint
+	if err := s.db.Model(&model.Note{}).Where("user_id = ? AND notebook_id IN ?", userID, ids).Pluck("id", &noteIDs).Error; err != nil {
+		zap.S().Errorf("查询笔记本中的笔记失败: %v", err)
+		return errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+	for _, noteID := range noteIDs {
+		if err := s.noteService.DeleteNote(userID, noteID); err != nil {
+			return err
+		}
+	}
+
+	// 2. 删除笔记本（硬删除，便于重新创建同名笔记本）
+	if err := s.db.Unscoped().Where("id IN ?", ids).Delete(&model.Notebook{}).Error; err != nil {
+		zap.S().Errorf("删除笔记本失败: %v", err)
+		return errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+	return nil
+}
+
+// deleteAndReparent 删除笔记本，下级笔记本和笔记移到上级笔记本
+func (s *NotebookService) deleteAndReparent(userID uint, notebook *model.Notebook) error {
+	// 1. 下级笔记本移到上级笔记本下（有同名笔记本时不允许删除）
+	var children []model.Notebook
+	if err := s.db.Where("user_id = ? AND parent_id = ?", userID, notebook.ID).Find(&children).Error; err != nil {
+		zap.S().Errorf("查询下级笔记本失败: %v", err)
+		return errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+	parentPath := strings.TrimSuffix(strings.TrimSuffix(notebook.Path, notebook.Name), model.NotebookPathSeparator)
+	for i := range children {
+		if err := s.checkSiblingName(userID, notebook.ParentID, children[i].Name, notebook.ID); err != nil {
+			return err
+		}
+	}
+	for i := range children {
+		if err := s.moveSubtree(userID, &children[i], notebook.ParentID, children[i].Name, joinNotebookPath(parentPath, children[i].Name)); err != nil {
+			return err
+		}
+	}
+
+	// 2. 笔记移到上级笔记本（顶级笔记本的笔记移到默认笔记本）
+	var (
+		target *model.Notebook
+		err    error
+	)
+	if notebook.ParentID > 0 {
+		target, err = s.getNotebook(userID, notebook.ParentID)
+	} else {
+		target, err = ensureNotebook(s.db, userID, model.DefaultNotebookName)
+	}
+	if err != nil {
+		return err
+	}
+	err = s.db.Model(&model.Note{}).Where("user_id = ? AND notebook_id = ?", userID, notebook.ID).UpdateColumns(map[string]interface{}{
+		"notebook_id": target.ID,
+		"category":    target.Path,
+	}).Error
+	if err != nil {
+		zap.S().Errorf("移动笔记失败: %v", err)
+		return errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+
+	// 3. 删除笔记本
+	if err := s.db.Unscoped().Delete(notebook).Error; err != nil {
+		zap.S().Errorf("删除笔记本失败: %v", err)
+		return errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+	return nil
+}
+
+// moveSubtree 修改笔记本的上级和名称，并把它和下级笔记本的路径、其中笔记的分类更新为新路径（不修改笔记更新时间）
+func (s *NotebookService) moveSubtree(userID uint, notebook *model.Notebook, parentID uint, name, newPath string) error {
+	subtree, err := s.subtree(userID, notebook)
+	if err != nil {
+		return err
+	}
+	oldPath := notebook.Path
+	for _, item := range subtree {
+		if err := validateNotebookPath(newPath + strings.TrimPrefix(item.Path, oldPath)); err != nil {
+			return err
+		}
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(notebook).Updates(map[string]interface{}{"parent_id": parentID, "name": name}).Error; err != nil {
+			return err
+		}
+		for _, item := range subtree {
+			path := newPath + strings.TrimPrefix(item.Path, oldPath)
+			if err := tx.Model(&model.Notebook{}).Where("id = ?", item.ID).UpdateColumn("path", path).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&model.Note{}).Where("notebook_id = ?", item.ID).UpdateColumn("category", path).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		zap.S().Errorf("更新笔记本路径失败: %v", err)
+		return errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+	return nil
+}
+
+// subtree 查询笔记本及其全部下级笔记本
+func (s *NotebookService) subtree(userID uint, notebook *model.Notebook) ([]model.Notebook, error) {
+	var notebooks []model.Notebook
+	cond, args := notebookCondition("path", notebook.Path)
+	if err := s.db.Where("user_id = ?", userID).Where(cond, args...).Find(&notebooks).Error; err != nil {
+		zap.S().Errorf("查询下级笔记本失败: %v", err)
+		return nil, errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+	return notebooks, nil
+}
+
+// checkSiblingName 检查同一层级下是否已有同名笔记本（excludeID 为要排除的笔记本）
+func (s *NotebookService) checkSiblingName(userID, parentID uint, name string, excludeID uint) error {
+	var count int64
+	err := s.db.Model(&model.Notebook{}).Where("user_id = ? AND parent_id = ? AND name = ? AND id <> ?", userID, parentID, name, excludeID).
+		Count(&count).Error
+	if err != nil {
+		zap.S().Errorf("查询笔记本失败: %v", err)
+		return errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+	if count > 0 {
+		return errcode.NewError(errcode.DuplicateData, fmt.Sprintf("同一层级下已有笔记本「%s」", name))
+	}
+	return nil
+}
+
+// getNotebook 查询用户的一个笔记本
+func (s *NotebookService) getNotebook(userID, notebookID uint) (*model.Notebook, error) {
+	var notebook model.Notebook
+	err := s.db.Where("user_id = ? AND id = ?", userID, notebookID).First(&notebook).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New(errcode.GetMsg(errcode.NotFound))
+		}
+		zap.S().Errorf("查询笔记本失败: %v", err)
+		return nil, errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+	return &notebook, nil
+}
+
+// RebuildNotebooks 把还没有笔记本的笔记（升级前按分类字符串保存的笔记）按分类建立笔记本，
+// 分类中的 / 视为层级（如「工作/项目A」），启动时在后台执行
+func (s *NotebookService) RebuildNotebooks() {
+	var rows []struct {
+		UserID   uint
+		Category string
+	}
+	err := s.db.Model(&model.Note{}).Distinct("user_id", "category").Where("notebook_id = ?", 0).Scan(&rows).Error
+	if err != nil {
+		zap.S().Errorf("查询待迁移的笔记分类失败: %v", err)
+		return
+	}
+	for _, row := range rows {
+		notebook, err := ensureNotebook(s.db, row.UserID, row.Category)
+		if err != nil {
+			zap.S().Errorf("为分类「%s」建立笔记本失败: %v", row.Category, err)
+			continue
+		}
+		err = s.db.Model(&model.Note{}).Where("user_id = ? AND category = ? AND notebook_id = ?", row.UserID, row.Category, 0).
+			UpdateColumns(map[string]interface{}{"notebook_id": notebook.ID, "category": notebook.Path}).Error
+		if err != nil {
+			zap.S().Errorf("迁移分类「%s」的笔记失败: %v", row.Category, err)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/JokerYuan-lang/MyNoteBook/internal/model"
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/errcode"
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/mailer"
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/mention"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// NotificationService 站内通知业务逻辑
+type NotificationService struct {
+	db     *gorm.DB
+	mailer *mailer.Mailer // 为 nil 时不发送邮件
+}
+
+// NewNotificationService 创建 NotificationService 实例
+func NewNotificationService(db *gorm.DB, m *mailer.Mailer) *NotificationService {
+	return &NotificationService{db: db, mailer: m}
+}
+
+// Notify 给指定用户发送一条通知（开启 SMTP 时同时发送邮件）
+func (s *NotificationService) Notify(userID, actorID uint, notifyType string, noteID uint, content string) error {
+	notification := model.Notification{
+		UserID:  userID,
+		ActorID: actorID,
+		Type:    notifyType,
+		NoteID:  noteID,
+		Content: content,
+	}
+	if err := s.db.Create(&notification).Error; err != nil {
+		zap.S().Errorf("创建通知失败: %v", err)
+		return errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+
+	s.sendEmail(userID, content)
+	return nil
+}
+
+// NotifyMentions 解析笔记内容中新增的 @用户名 并通知对应用户（不通知作者本人）
+// 被提及的用户无权查看该笔记，通知中不包含笔记标题和笔记ID
+func (s *NotificationService) NotifyMentions(author uint, note *model.Note, oldContent string) {
+	names := mention.Added(oldContent, note.Content)
+	if len(names) == 0 {
+		return
+	}
+
+	var (
+		actor model.User
+		users []model.User
+	)
+	if err := s.db.Select("id, username").First(&actor, author).Error; err != nil {
+		zap.S().Errorf("查询用户失败: %v", err)
+		return
+	}
+	if err := s.db.Where("username IN ?", names).Find(&users).Error; err != nil {
+		zap.S().Errorf("查询被提及用户失败: %v", err)
+		return
+	}
+
+	content := fmt.Sprintf("%s 在笔记中提到了你", actor.Username)
+	for _, user := range users {
+		if user.ID == author {
+			continue
+		}
+		// 通知失败不影响笔记保存，错误已在 Notify 中记录
+		_ = s.Notify(user.ID, author, model.NotificationTypeMention, 0, content)
+	}
+}
+
+// GetNotificationList 分页查询通知列表（可只看未读）
+func (s *NotificationService) GetNotificationList(userID uint, page, pageSize int, unreadOnly bool) ([]model.Notification, int64, error) {
+	var (
+		notifications []model.Notification
+		total         int64
+	)
+	db := s.db.Model(&model.Notification{}).Where("user_id = ?", userID)
+	if unreadOnly {
+		db = db.Where("is_read = ?", false)
+	}
+
+	if err := db.Count(&total).Error; err != nil {
+		zap.S().Errorf("统计通知总数失败: %v", err)
+		return nil, 0, errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+
+	offset := (page - 1) * pageSize
+	if err := db.Offset(offset).Limit(pageSize).Order("created_at DESC").Find(&notifications).Error; err != nil {
+		zap.S().Errorf("查询通知列表失败: %v", err)
+		return nil, 0, errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+
+	return notifications, total, nil
+}
+
+// GetUnreadCount 统计未读通知数量
+func (s *NotificationService) GetUnreadCount(userID uint) (int64, error) {
+	var count int64
+	err := s.db.Model(&model.Notification{}).Where("user_id = ? AND is_read = ?", userID, false).Count(&count).Error
+	if err != nil {
+		zap.S().Errorf("统计未读通知失败: %v", err)
+		return 0, errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+	return count, nil
+}
+
+// MarkRead 将指定通知标记为已读（ids 为空时全部标记为已读）
+func (s *NotificationService) MarkRead(userID uint, ids []uint) error {
+	db := s.db.Model(&model.Notification{}).Where("user_id = ? AND is_read = ?", userID, false)
+	if len(ids) > 0 {
+		db = db.Where("id IN ?", ids)
+	}
+	err := db.Updates(map[string]interface{}{"is_read": true, "read_at": time.Now()}).Error
+	if err != nil {
+		zap.S().Errorf("标记通知已读失败: %v", err)
+		return errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+	return nil
+}
+
+// sendEmail 异步发送通知邮件（未配置 SMTP 时跳过）
+func (s *NotificationService) sendEmail(userID uint, content string) {
+	if s.mailer == nil {
+		return
+	}
+	var user model.User
+	if err := s.db.Select("id, email").First(&user, userID).Error; err != nil {
+		zap.S().Errorf("查询用户邮箱失败: %v", err)
+		return
+	}
+	go func() {
+		if err := s.mailer.Send(user.Email, "MyNoteBook 新通知", content); err != nil {
+			zap.S().Errorf("发送通知邮件失败: %v", err)
+		}
+	}()
+}
@@ -0,0 +1,403 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/JokerYuan-lang/MyNoteBook/internal/model"
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/errcode"
+	"github.com/go-redis/redis/v8"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	relatedLockKey    = "lock:related_vectors" // 向量重算锁（多实例只有一个在计算）
+	relatedLockTTL    = 10 * time.Minute
+	relatedInterval   = 5 * time.Minute
+	relatedQueryTerms = 30  // 计算相似度时只取权重最高的词
+	relatedTagBoost   = 0.3 // 标签完全相同时的加分
+)
+
+// relatedStaleTime 待重算向量的更新时间（早于任何笔记的更新时间，下一轮重算时会被选中）
+var relatedStaleTime = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// RelatedService 相关笔记推荐：基于搜索索引中的词频计算 TF-IDF 余弦相似度，共同标签额外加分
+type RelatedService struct {
+	db  *gorm.DB
+	rdb *redis.Client
+}
+
+// NewRelatedService 创建 RelatedService 实例
+func NewRelatedService(db *gorm.DB, rdb *redis.Client) *RelatedService {
+	return &RelatedService{db: db, rdb: rdb}
+}
+
+// tfidf 词权重：(1 + ln 词频) * (ln((N+1)/(文档频率+1)) + 1)
+func tfidf(count, df int, total int64) float64 {
+	return (1 + math.Log(float64(count))) * (math.Log(float64(total+1)/float64(df+1)) + 1)
+}
+
+// GetRelatedNotes 查询与笔记最相似的 limit 条笔记（同一用户）
+func (s *RelatedService) GetRelatedNotes(userID, noteID uint, limit int) ([]model.RelatedNote, error) {
+	var note model.Note
+	err := s.db.Select("id").Where("user_id = ? AND id = ?", userID, noteID).Preload("Tags").First(&note).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New(errcode.GetMsg(errcode.NotFound))
+		}
+		zap.S().Errorf("查询笔记失败: %v", err)
+		return nil, errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+
+	var terms []struct {
+		Term  string
+		Count int
+	}
+	err = s.db.Model(&model.NoteTerm{}).Select("term, count").
+		Where("note_id = ? AND field = ?", noteID, model.TermFieldText).Scan(&terms).Error
+	if err != nil {
+		zap.S().Errorf("查询笔记索引失败: %v", err)
+		return nil, errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+	counts := make(map[string]int, len(terms))
+	for _, t := range terms {
+		counts[t.Term] = t.Count
+	}
+	return s.similarNotes(userID, noteID, counts, note.Tags, limit)
+}
+
+// similarNotes 按词频向量和标签查找相似笔记（excludeID 为要排除的笔记，草稿为 0）
+func (s *RelatedService) similarNotes(userID, excludeID uint, counts map[string]int, tags []model.Tag, limit int) ([]model.RelatedNote, error) {
+	related := []model.RelatedNote{}
+
+	// 1. 目标向量（文档频率按当前索引实时统计）
+	termWeights, err := s.termWeights(userID, counts)
+	if err != nil {
+		return nil, err
+	}
+	df, total := termWeights.df, termWeights.total
+	type weighted struct {
+		term   string
+		weight float64
+	}
+	weights := make([]weighted, 0, len(counts))
+	var targetNorm float64
+	for term, w := range termWeights.weights {
+		weights = append(weights, weighted{term: term, weight: w})
+		targetNorm += w * w
+	}
+	targetNorm = math.Sqrt(targetNorm)
+	sort.Slice(weights, func(i, j int) bool { return weights[i].weight > weights[j].weight })
+	if len(weights) > relatedQueryTerms {
+		weights = weights[:relatedQueryTerms]
+	}
+
+	// 2. 含有这些词的笔记，累加点积
+	dot := make(map[uint]float64)
+	partial := make(map[uint]float64) // 向量的模尚未计算时，用命中词的权重近似
+	if len(weights) > 0 {
+		queryTerms := make([]string, 0, len(weights))
+		termWeight := make(map[string]float64, len(weights))
+		for _, w := range weights {
+			queryTerms = append(queryTerms, w.term)
+			termWeight[w.term] = w.weight
+		}
+		var rows []struct {
+			NoteID uint
+			Term   string
+			Count  int
+		}
+		err := s.db.Model(&model.NoteTerm{}).Select("note_id, term, count").
+			Where("user_id = ? AND field = ? AND term IN ? AND note_id <> ?", userID, model.TermFieldText, queryTerms, excludeID).
+			Scan(&rows).Error
+		if err != nil {
+			zap.S().Errorf("查询相似笔记失败: %v", err)
+			return nil, errors.New(errcode.GetMsg(errcode.ServerError))
+		}
+		for _, row := range rows {
+			w := tfidf(row.Count, df[row.Term], total)
+			dot[row.NoteID] += termWeight[row.Term] * w
+			partial[row.NoteID] += w * w
+		}
+	}
+
+	// 3. 有共同标签的笔记
+	tagNames := make(map[uint]string, len(tags))
+	tagIDs := make([]uint, 0, len(tags))
+	for _, tag := range tags {
+		tagNames[tag.ID] = tag.Name
+		tagIDs = append(tagIDs, tag.ID)
+	}
+	shared := make(map[uint][]string)
+	if len(tagIDs) > 0 {
+		var rows []model.NoteTag
+		if err := s.db.Where("tag_id IN ? AND note_id <> ?", tagIDs, excludeID).Find(&rows).Error; err != nil {
+			zap.S().Errorf("查询同标签笔记失败: %v", err)
+			return nil, errors.New(errcode.GetMsg(errcode.ServerError))
+		}
+		for _, row := range rows {
+			shared[row.NoteID] = append(shared[row.NoteID], tagNames[row.TagID])
+		}
+	}
+
+	candidates := make([]uint, 0, len(dot)+len(shared))
+	for id := range dot {
+		candidates = append(candidates, id)
+	}
+	for id := range shared {
+		if _, ok := dot[id]; !ok {
+			candidates = append(candidates, id)
+		}
+	}
+	if len(candidates) == 0 {
+		return related, nil
+	}
+
+	// 4. 计算得分：余弦相似度 + 标签 Jaccard 系数加分
+	norms := make(map[uint]float64, len(candidates))
+	var vectors []model.NoteVector
+	if err := s.db.Where("note_id IN ?", candidates).Find(&vectors).Error; err != nil {
+		zap.S().Errorf("查询笔记向量失败: %v", err)
+		return nil, errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+	for _, v := range vectors {
+		norms[v.NoteID] = v.Norm
+	}
+	tagCounts := make(map[uint]int, len(shared))
+	if len(shared) > 0 {
+		var rows []struct {
+			NoteID uint
+			Total  int
+		}
+		err := s.db.Model(&model.NoteTag{}).Select("note_id, COUNT(*) AS total").
+			Where("note_id IN ?", candidates).Group("note_id").Scan(&rows).Error
+		if err != nil {
+			zap.S().Errorf("统计笔记标签失败: %v", err)
+			return nil, errors.New(errcode.GetMsg(errcode.ServerError))
+		}
+		for _, row := range rows {
+			tagCounts[row.NoteID] = row.Total
+		}
+	}
+
+	scores := make(map[uint]float64, len(candidates))
+	for _, id := range candidates {
+		var score float64
+		if d := dot[id]; d > 0 && targetNorm > 0 {
+			norm := norms[id]
+			if norm < math.Sqrt(partial[id]) {
+				norm = math.Sqrt(partial[id]) // 新笔记还没有向量，或向量已过期
+			}
+			score = d / (targetNorm * norm)
+		}
+		if n := len(shared[id]); n > 0 {
+			score += relatedTagBoost * float64(n) / float64(len(tagIDs)+tagCounts[id]-n)
+		}
+		scores[id] = score
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if scores[candidates[i]] != scores[candidates[j]] {
+			return scores[candidates[i]] > scores[candidates[j]]
+		}
+		return candidates[i] > candidates[j]
+	})
+	if len(candidates) > limit*2 {
+		candidates = candidates[:limit*2] // 多取一些，排除已删除的笔记
+	}
+
+	// 5. 查询笔记信息（过滤已删除的笔记）
+	var notes []model.Note
+	if err := s.db.Select("id, title, category").Where("user_id = ? AND id IN ?", userID, candidates).Find(&notes).Error; err != nil {
+		zap.S().Errorf("查询相似笔记失败: %v", err)
+		return nil, errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+	byID := make(map[uint]model.Note, len(notes))
+	for _, n := range notes {
+		byID[n.ID] = n
+	}
+	for _, id := range candidates {
+		n, ok := byID[id]
+		if !ok || scores[id] <= 0 {
+			continue
+		}
+		sharedTags := shared[id]
+		if sharedTags == nil {
+			sharedTags = []string{}
+		}
+		related = append(related, model.RelatedNote{
+			NoteID:     id,
+			Title:      n.Title,
+			Category:   n.Category,
+			Score:      math.Round(scores[id]*1000) / 1000,
+			SharedTags: sharedTags,
+		})
+		if len(related) == limit {
+			break
+		}
+	}
+	return related, nil
+}
+
+// tfidfWeights 一组词的 TF-IDF 权重及计算所用的文档频率、笔记总数
+type tfidfWeights struct {
+	weights map[string]float64
+	df      map[string]int
+	total   int64
+}
+
+// termWeights 按用户当前的笔记计算词频向量的 TF-IDF 权重
+func (s *RelatedService) termWeights(userID uint, counts map[string]int) (*tfidfWeights, error) {
+	result := &tfidfWeights{weights: make(map[string]float64, len(counts)), df: make(map[string]int, len(counts))}
+	if err := s.db.Model(&model.Note{}).Where("user_id = ?", userID).Count(&result.total).Error; err != nil {
+		zap.S().Errorf("统计笔记数量失败: %v", err)
+		return nil, errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+	if len(counts) == 0 {
+		return result, nil
+	}
+
+	// 文档频率：每个词出现在用户多少条笔记中
+	terms := make([]string, 0, len(counts))
+	for term := range counts {
+		terms = append(terms, term)
+	}
+	var rows []struct {
+		Term string
+		DF   int `gorm:"column:df"`
+	}
+	err := s.db.Model(&model.NoteTerm{}).Select("term, COUNT(*) AS df").
+		Where("user_id = ? AND field = ? AND term IN ?", userID, model.TermFieldText, terms).
+		Group("term").Scan(&rows).Error
+	if err != nil {
+		zap.S().Errorf("统计文档频率失败: %v", err)
+		return nil, errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+	for _, row := range rows {
+		result.df[row.Term] = row.DF
+	}
+	for term, count := range counts {
+		result.weights[term] = tfidf(count, result.df[term], result.total)
+	}
+	return result, nil
+}
+
+// MarkStale 标记用户的笔记向量待重算（删除笔记后调用；新增、修改笔记由笔记更新时间触发）
+func (s *RelatedService) MarkStale(userID uint) error {
+	err := s.db.Model(&model.NoteVector{}).Where("user_id = ?", userID).UpdateColumn("updated_at", relatedStaleTime).Error
+	if err != nil {
+		zap.S().Errorf("标记笔记向量待重算失败: %v", err)
+		return errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+	return nil
+}
+
+// Run 定期为笔记有变化的用户重算词向量的模（阻塞，应在 goroutine 中调用）
+func (s *RelatedService) Run(ctx context.Context) {
+	ticker := time.NewTicker(relatedInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.runOnce(ctx)
+		}
+	}
+}
+
+// runOnce 抢锁后重算一轮
+func (s *RelatedService) runOnce(ctx context.Context) {
+	owner := fmt.Sprintf("%d", time.Now().UnixNano())
+	ok, err := s.rdb.SetNX(ctx, relatedLockKey, owner, relatedLockTTL).Result()
+	if err != nil {
+		zap.S().Errorf("获取向量重算锁失败: %v", err)
+		return
+	}
+	if !ok {
+		return // 其他实例正在处理
+	}
+	defer releaseLockScript.Run(ctx, s.rdb, []string{relatedLockKey}, owner)
+
+	// 有笔记新增、修改（向量缺失或早于笔记更新时间）的用户
+	var userIDs []uint
+	err = s.db.Model(&model.Note{}).Distinct("notes.user_id").
+		Joins("LEFT JOIN note_vectors ON note_vectors.note_id = notes.id").
+		Where("note_vectors.note_id IS NULL OR note_vectors.updated_at < notes.updated_at").
+		Pluck("notes.user_id", &userIDs).Error
+	if err != nil {
+		zap.S().Errorf("查询待重算向量的用户失败: %v", err)
+		return
+	}
+	for _, userID := range userIDs {
+		if err := s.refreshUser(userID); err != nil {
+			zap.S().Errorf("重算用户 %d 的笔记向量失败: %v", userID, err)
+		}
+	}
+}
+
+// refreshUser 按当前文档频率重算用户全部笔记向量的模（文档频率随笔记增删变化，因此整体重算）
+func (s *RelatedService) refreshUser(userID uint) error {
+	var noteIDs []uint
+	if err := s.db.Model(&model.Note{}).Where("user_id = ?", userID).Pluck("id", &noteIDs).Error; err != nil {
+		return err
+	}
+	total := int64(len(noteIDs))
+
+	var dfRows []struct {
+		Term string
+		DF   int `gorm:"column:df"`
+	}
+	err := s.db.Model(&model.NoteTerm{}).Select("term, COUNT(*) AS df").
+		Where("user_id = ? AND field = ?", userID, model.TermFieldText).Group("term").Scan(&dfRows).Error
+	if err != nil {
+		return err
+	}
+	df := make(map[string]int, len(dfRows))
+	for _, row := range dfRows {
+		df[row.Term] = row.DF
+	}
+
+	// 逐行读取，避免一次加载全部索引
+	rows, err := s.db.Model(&model.NoteTerm{}).Select("note_id, term, count").
+		Where("user_id = ? AND field = ?", userID, model.TermFieldText).Rows()
+	if err != nil {
+		return err
+	}
+	sums := make(map[uint]float64, len(noteIDs))
+	for rows.Next() {
+		var (
+			noteID uint
+			term   string
+			count  int
+		)
+		if err := rows.Scan(&noteID, &term, &count); err != nil {
+			rows.Close()
+			return err
+		}
+		w := tfidf(count, df[term], total)
+		sums[noteID] += w * w
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	vectors := make([]model.NoteVector, 0, len(noteIDs))
+	for _, id := range noteIDs {
+		vectors = append(vectors, model.NoteVector{NoteID: id, UserID: userID, Norm: math.Sqrt(sums[id])})
+	}
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&model.NoteVector{}).Error; err != nil {
+			return err
+		}
+		if len(vectors) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(vectors, 500).Error
+	})
+}
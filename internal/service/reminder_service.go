@@ -0,0 +1,235 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/JokerYuan-lang/MyNoteBook/internal/model"
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/errcode"
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/ical"
+	"github.com/go-redis/redis/v8"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	reminderLockKey   = "lock:reminder_scheduler" // 调度锁（多实例只有一个在扫描）
+	reminderLockTTL   = 50 * time.Second
+	reminderInterval  = 30 * time.Second
+	reminderBatchSize = 100
+	calendarTokenSize = 24 // 日历令牌随机字节数（十六进制编码后 48 位）
+)
+
+// 仅当锁仍属于自己时才释放
+var releaseLockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+
+// ReminderService 笔记提醒、截止时间和日历订阅
+type ReminderService struct {
+	db                  *gorm.DB
+	rdb                 *redis.Client
+	notificationService *NotificationService
+}
+
+// NewReminderService 创建 ReminderService 实例
+func NewReminderService(db *gorm.DB, rdb *redis.Client, notificationService *NotificationService) *ReminderService {
+	return &ReminderService{db: db, rdb: rdb, notificationService: notificationService}
+}
+
+// SetSchedule 设置笔记的提醒时间和截止时间（传 nil 表示清除）
+func (s *ReminderService) SetSchedule(userID, noteID uint, remindAt, dueAt *time.Time) error {
+	note, err := s.getNote(userID, noteID)
+	if err != nil {
+		return err
+	}
+	err = s.db.Model(note).Updates(map[string]interface{}{
+		"remind_at": remindAt,
+		"due_at":    dueAt,
+		"reminded":  false, // 重新设置后需要再次提醒
+	}).Error
+	if err != nil {
+		zap.S().Errorf("设置笔记提醒失败: %v", err)
+		return errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+	return nil
+}
+
+// Snooze 稍后提醒（从当前时间起推迟 minutes 分钟）
+func (s *ReminderService) Snooze(userID, noteID uint, minutes int) error {
+	note, err := s.getNote(userID, noteID)
+	if err != nil {
+		return err
+	}
+	remindAt := time.Now().Add(time.Duration(minutes) * time.Minute)
+	err = s.db.Model(note).Updates(map[string]interface{}{
+		"remind_at": remindAt,
+		"reminded":  false,
+	}).Error
+	if err != nil {
+		zap.S().Errorf("推迟笔记提醒失败: %v", err)
+		return errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+	return nil
+}
+
+// Complete 标记笔记完成/未完成（完成后不再提醒）
+func (s *ReminderService) Complete(userID, noteID uint, completed bool) error {
+	note, err := s.getNote(userID, noteID)
+	if err != nil {
+		return err
+	}
+	var completedAt *time.Time
+	if completed {
+		now := time.Now()
+		completedAt = &now
+	}
+	if err := s.db.Model(note).Update("completed_at", completedAt).Error; err != nil {
+		zap.S().Errorf("更新笔记完成状态失败: %v", err)
+		return errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+	return nil
+}
+
+// GetCalendarToken 获取日历订阅令牌（没有则生成；reset 为 true 时重新生成，旧链接失效），issued 表示本次新签发了令牌
+func (s *ReminderService) GetCalendarToken(userID uint, reset bool) (token string, issued bool, err error) {
+	var user model.User
+	if err := s.db.Select("id, calendar_token").First(&user, userID).Error; err != nil {
+		zap.S().Errorf("查询用户失败: %v", err)
+		return "", false, errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+	if user.CalendarToken != nil && *user.CalendarToken != "" && !reset {
+		return *user.CalendarToken, false, nil
+	}
+
+	buf := make([]byte, calendarTokenSize)
+	if _, err := rand.Read(buf); err != nil {
+		zap.S().Errorf("生成日历令牌失败: %v", err)
+		return "", false, errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+	token = hex.EncodeToString(buf)
+	if err := s.db.Model(&user).Update("calendar_token", token).Error; err != nil {
+		zap.S().Errorf("保存日历令牌失败: %v", err)
+		return "", false, errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+	return token, true, nil
+}
+
+// BuildCalendar 根据订阅令牌生成用户带截止时间笔记的 iCalendar 内容
+func (s *ReminderService) BuildCalendar(token string) (string, error) {
+	// 令牌长度不对时直接返回不存在（空令牌不能匹配任何用户）
+	if len(token) != hex.EncodedLen(calendarTokenSize) {
+		return "", errors.New(errcode.GetMsg(errcode.NotFound))
+	}
+
+	var user model.User
+	err := s.db.Select("id, username").Where("calendar_token = ?", token).First(&user).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", errors.New(errcode.GetMsg(errcode.NotFound))
+		}
+		zap.S().Errorf("查询日历令牌失败: %v", err)
+		return "", errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+
+	var notes []model.Note
+	err = s.db.Select("id, title, category, due_at, remind_at, completed_at, updated_at").
+		Where("user_id = ? AND due_at IS NOT NULL", user.ID).Order("due_at").Find(&notes).Error
+	if err != nil {
+		zap.S().Errorf("查询截止笔记失败: %v", err)
+		return "", errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+
+	cal := ical.Calendar{Name: user.Username + " 的笔记"}
+	for _, note := range notes {
+		event := ical.Event{
+			UID:         fmt.Sprintf("note-%d@mynotebook", note.ID),
+			Summary:     note.Title,
+			Description: "分类: " + note.Category,
+			Start:       *note.DueAt,
+			Completed:   note.CompletedAt != nil,
+			Updated:     note.UpdatedAt,
+		}
+		if note.RemindAt != nil {
+			event.Remind = *note.RemindAt
+		}
+		cal.Events = append(cal.Events, event)
+	}
+	return cal.String(), nil
+}
+
+// Run 定时扫描到期提醒（阻塞直到 ctx 结束；通过 Redis 锁保证多实例下只有一个实例在发送）
+func (s *ReminderService) Run(ctx context.Context) {
+	ticker := time.NewTicker(reminderInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.runOnce(ctx)
+		}
+	}
+}
+
+// runOnce 抢锁后发送一批到期提醒
+func (s *ReminderService) runOnce(ctx context.Context) {
+	owner := fmt.Sprintf("%d", time.Now().UnixNano())
+	ok, err := s.rdb.SetNX(ctx, reminderLockKey, owner, reminderLockTTL).Result()
+	if err != nil {
+		zap.S().Errorf("获取提醒调度锁失败: %v", err)
+		return
+	}
+	if !ok {
+		return // 其他实例正在处理
+	}
+	defer releaseLockScript.Run(ctx, s.rdb, []string{reminderLockKey}, owner)
+
+	var notes []model.Note
+	err = s.db.Select("id, user_id, title, remind_at, due_at").
+		Where("remind_at <= ? AND reminded = ? AND completed_at IS NULL", time.Now(), false).
+		Order("remind_at").Limit(reminderBatchSize).Find(&notes).Error
+	if err != nil {
+		zap.S().Errorf("查询到期提醒失败: %v", err)
+		return
+	}
+
+	for _, note := range notes {
+		// 条件更新作为二次保险：锁过期时也不会重复提醒
+		result := s.db.Model(&model.Note{}).Where("id = ? AND reminded = ?", note.ID, false).Update("reminded", true)
+		if result.Error != nil || result.RowsAffected == 0 {
+			continue
+		}
+
+		content := fmt.Sprintf("笔记《%s》提醒时间已到", note.Title)
+		if note.DueAt != nil {
+			content = fmt.Sprintf("笔记《%s》提醒：截止时间 %s", note.Title, note.DueAt.Format("2006-01-02 15:04"))
+		}
+		if err := s.notificationService.Notify(note.UserID, 0, model.NotificationTypeReminder, note.ID, content); err != nil {
+			// 通知失败时撤销已提醒标记，下一轮重试
+			if err := s.db.Model(&model.Note{}).Where("id = ?", note.ID).Update("reminded", false).Error; err != nil {
+				zap.S().Errorf("撤销提醒标记失败: %v", err)
+			}
+		}
+	}
+}
+
+// getNote 查询属于当前用户的笔记
+func (s *ReminderService) getNote(userID, noteID uint) (*model.Note, error) {
+	var note model.Note
+	err := s.db.Where("user_id = ? AND id = ?", userID, noteID).First(&note).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New(errcode.GetMsg(errcode.NotFound))
+		}
+		zap.S().Errorf("查询笔记失败: %v", err)
+		return nil, errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+	return &note, nil
+}
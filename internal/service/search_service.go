@@ -0,0 +1,236 @@
+package service
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/JokerYuan-lang/MyNoteBook/internal/model"
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/errcode"
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/searchquery"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// likeEscaper 转义 LIKE 通配符，使关键词按字面匹配
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// SearchService 结构化搜索与保存的搜索（智能文件夹）
+type SearchService struct {
+	db           *gorm.DB
+	noteService  *NoteService
+	indexService *IndexService
+}
+
+// NewSearchService 创建 SearchService 实例
+func NewSearchService(db *gorm.DB, noteService *NoteService, indexService *IndexService) *SearchService {
+	return &SearchService{db: db, noteService: noteService, indexService: indexService}
+}
+
+// Search 按搜索语句分页查询笔记（语法错误返回 errcode.QuerySyntax）
+func (s *SearchService) Search(userID uint, text string, page, pageSize int) ([]model.Note, int64, error) {
+	scope, err := s.compileQuery(text)
+	if err != nil {
+		return nil, 0, err
+	}
+	return s.noteService.GetNoteList(userID, page, pageSize, NoteListFilter{Scopes: []func(*gorm.DB) *gorm.DB{scope}})
+}
+
+// SearchFacets 统计符合搜索语句的笔记的分面数量
+func (s *SearchService) SearchFacets(userID uint, text string) (*model.NoteFacets, error) {
+	scope, err := s.compileQuery(text)
+	if err != nil {
+		return nil, err
+	}
+	return s.noteService.GetNoteFacets(userID, NoteListFilter{Scopes: []func(*gorm.DB) *gorm.DB{scope}})
+}
+
+// compileQuery 解析搜索语句并编译为 GORM 查询条件
+func (s *SearchService) compileQuery(text string) (func(*gorm.DB) *gorm.DB, error) {
+	query, err := searchquery.Parse(text)
+	if err != nil {
+		return nil, errcode.NewError(errcode.QuerySyntax, err.Error())
+	}
+
+	var (
+		conds []string
+		args  []interface{}
+	)
+	for _, clause := range query.Clauses {
+		parts := make([]string, 0, len(clause))
+		for _, term := range clause {
+			cond, termArgs := s.compileTerm(term)
+			parts = append(parts, cond)
+			args = append(args, termArgs...)
+		}
+		if len(parts) == 1 {
+			conds = append(conds, parts[0])
+		} else {
+			conds = append(conds, "("+strings.Join(parts, " OR ")+")")
+		}
+	}
+
+	return func(db *gorm.DB) *gorm.DB {
+		if len(conds) == 0 {
+			return db
+		}
+		return db.Where(strings.Join(conds, " AND "), args...)
+	}, nil
+}
+
+// compileTerm 把一个搜索条件编译为 SQL 片段（关键词按分词匹配搜索索引，短语按原文匹配）
+func (s *SearchService) compileTerm(term searchquery.Term) (string, []interface{}) {
+	var (
+		cond string
+		args []interface{}
+	)
+	switch term.Field {
+	case searchquery.FieldTag:
+		tagCond, tagArgs := tagCondition("tags.name", term.Value)
+		cond = "EXISTS (SELECT 1 FROM note_tags JOIN tags ON tags.id = note_tags.tag_id AND tags.deleted_at IS NULL WHERE note_tags.note_id = notes.id AND " + tagCond + ")"
+		args = tagArgs
+	case searchquery.FieldCategory:
+		cond, args = notebookCondition("notes.category", term.Value)
+	case searchquery.FieldTitle:
+		cond = "notes.title LIKE ?"
+		args = []interface{}{"%" + likeEscaper.Replace(term.Value) + "%"}
+	case searchquery.FieldCreated, searchquery.FieldUpdated:
+		column := "notes.created_at"
+		if term.Field == searchquery.FieldUpdated {
+			column = "notes.updated_at"
+		}
+		var parts []string
+		if term.Start != nil {
+			parts = append(parts, column+" >= ?")
+			args = append(args, *term.Start)
+		}
+		if term.End != nil {
+			parts = append(parts, column+" < ?")
+			args = append(args, *term.End)
+		}
+		cond = "(" + strings.Join(parts, " AND ") + ")"
+	case searchquery.FieldIs:
+		// 解析时已校验为 pinned/favorite/archived，与列名一致
+		cond = "notes." + term.Value + " = ?"
+		args = []interface{}{true}
+	default:
+		if term.Phrase {
+			like := "%" + likeEscaper.Replace(term.Value) + "%"
+			cond = "(notes.title LIKE ? OR notes.plain_text LIKE ?)"
+			args = []interface{}{like, like}
+		} else {
+			cond, args = s.indexService.KeywordCondition(term.Value)
+		}
+	}
+	if term.Negate {
+		if term.Field == searchquery.FieldText {
+			// 纯文本缓存为空时 LIKE 结果为 NULL，取反时视为不包含
+			cond = "COALESCE(" + cond + ", FALSE)"
+		}
+		cond = "NOT " + cond
+	}
+	return cond, args
+}
+
+// CreateSavedSearch 保存搜索（保存前检查语法，同一用户下名称不能重复）
+func (s *SearchService) CreateSavedSearch(userID uint, name, query string) (*model.SavedSearch, error) {
+	if _, err := s.compileQuery(query); err != nil {
+		return nil, err
+	}
+	if err := s.checkName(userID, name, 0); err != nil {
+		return nil, err
+	}
+
+	search := model.SavedSearch{UserID: userID, Name: name, Query: query}
+	if err := s.db.Create(&search).Error; err != nil {
+		zap.S().Errorf("保存搜索失败: %v", err)
+		return nil, errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+	return &search, nil
+}
+
+// GetSavedSearchList 查询用户保存的搜索（按名称排序）
+func (s *SearchService) GetSavedSearchList(userID uint) ([]model.SavedSearch, error) {
+	var searches []model.SavedSearch
+	if err := s.db.Where("user_id = ?", userID).Order("name ASC").Find(&searches).Error; err != nil {
+		zap.S().Errorf("查询保存的搜索失败: %v", err)
+		return nil, errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+	return searches, nil
+}
+
+// UpdateSavedSearch 修改保存的搜索
+func (s *SearchService) UpdateSavedSearch(userID, searchID uint, name, query string) error {
+	search, err := s.getSavedSearch(userID, searchID)
+	if err != nil {
+		return err
+	}
+	if _, err := s.compileQuery(query); err != nil {
+		return err
+	}
+	if err := s.checkName(userID, name, searchID); err != nil {
+		return err
+	}
+
+	search.Name = name
+	search.Query = query
+	if err := s.db.Save(search).Error; err != nil {
+		zap.S().Errorf("修改保存的搜索失败: %v", err)
+		return errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+	return nil
+}
+
+// DeleteSavedSearch 删除保存的搜索
+func (s *SearchService) DeleteSavedSearch(userID, searchID uint) error {
+	search, err := s.getSavedSearch(userID, searchID)
+	if err != nil {
+		return err
+	}
+	// 硬删除，便于重新使用同一名称
+	if err := s.db.Unscoped().Delete(search).Error; err != nil {
+		zap.S().Errorf("删除保存的搜索失败: %v", err)
+		return errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+	return nil
+}
+
+// RunSavedSearch 执行保存的搜索，分页返回匹配的笔记
+func (s *SearchService) RunSavedSearch(userID, searchID uint, page, pageSize int) (*model.SavedSearch, []model.Note, int64, error) {
+	search, err := s.getSavedSearch(userID, searchID)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	notes, total, err := s.Search(userID, search.Query, page, pageSize)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	return search, notes, total, nil
+}
+
+// getSavedSearch 查询用户的一条保存的搜索
+func (s *SearchService) getSavedSearch(userID, searchID uint) (*model.SavedSearch, error) {
+	var search model.SavedSearch
+	err := s.db.Where("user_id = ? AND id = ?", userID, searchID).First(&search).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New(errcode.GetMsg(errcode.NotFound))
+		}
+		zap.S().Errorf("查询保存的搜索失败: %v", err)
+		return nil, errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+	return &search, nil
+}
+
+// checkName 检查名称是否与用户的其他保存的搜索重复（excludeID 为正在修改的记录）
+func (s *SearchService) checkName(userID uint, name string, excludeID uint) error {
+	var count int64
+	err := s.db.Model(&model.SavedSearch{}).Where("user_id = ? AND name = ? AND id <> ?", userID, name, excludeID).Count(&count).Error
+	if err != nil {
+		zap.S().Errorf("查询保存的搜索失败: %v", err)
+		return errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+	if count > 0 {
+		return errcode.NewError(errcode.DuplicateData, "已有同名的保存搜索")
+	}
+	return nil
+}
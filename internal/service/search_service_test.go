@@ -0,0 +1,23 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/searchquery"
+)
+
+func TestCompileStateTerm(t *testing.T) {
+	query, err := searchquery.Parse("is:pinned -is:Archived is:favorite")
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	want := []string{"notes.pinned = ?", "NOT notes.archived = ?", "notes.favorite = ?"}
+	s := &SearchService{}
+	for i, clause := range query.Clauses {
+		cond, args := s.compileTerm(clause[0])
+		if cond != want[i] || !reflect.DeepEqual(args, []interface{}{true}) {
+			t.Errorf("compileTerm(%+v) = %q %v, want %q [true]", clause[0], cond, args, want[i])
+		}
+	}
+}
@@ -0,0 +1,535 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/JokerYuan-lang/MyNoteBook/internal/model"
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/analyzer"
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/errcode"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// tagMaxName 标签名称（多级标签为完整路径）最大长度（字）
+const tagMaxName = 100
+
+// 标签推荐得分的组成
+const (
+	suggestKeywordScore = 0.5 // 内容包含标签词
+	suggestTitleBonus   = 0.2 // 标题包含标签词
+	suggestSimilarScore = 0.5 // 相似笔记使用该标签（按相似度加权）
+	suggestSimilarNotes = 10  // 参考的相似笔记数量
+)
+
+// TagService 标签推荐和自动打标签规则
+type TagService struct {
+	db             *gorm.DB
+	indexService   *IndexService
+	relatedService *RelatedService
+}
+
+// NewTagService 创建 TagService 实例
+func NewTagService(db *gorm.DB, indexService *IndexService, relatedService *RelatedService) *TagService {
+	return &TagService{db: db, indexService: indexService, relatedService: relatedService}
+}
+
+// normalizeTagName 规范化标签名：多级标签去掉各级首尾空白和空的级别（如「 project / alpha/」为 project/alpha）
+func normalizeTagName(name string) string {
+	var parts []string
+	for _, part := range strings.Split(name, model.TagPathSeparator) {
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, model.TagPathSeparator)
+}
+
+// tagCondition 按标签筛选的条件（包含全部下级标签；column 为标签名列，如 tags.name）
+func tagCondition(column, name string) (string, []interface{}) {
+	name = normalizeTagName(name)
+	return "(" + column + " = ? OR " + column + " LIKE ?)", []interface{}{name, likeEscaper.Replace(name) + model.TagPathSeparator + "%"}
+}
+
+// replaceTagPrefix 把标签名（oldName 本身或其下级标签）中的 oldName 部分替换为 newName
+func replaceTagPrefix(name, oldName, newName string) string {
+	name = normalizeTagName(name)
+	if len(name) < len(oldName) {
+		return newName
+	}
+	return newName + name[len(oldName):]
+}
+
+// GetTagTree 查询用户的标签树（同级按名称排序，附笔记数；上级标签即使没有直接使用也会出现）
+func (s *TagService) GetTagTree(userID uint) ([]model.TagNode, error) {
+	var tags []model.Tag
+	if err := s.db.Select("id, name").Where("user_id = ?", userID).Find(&tags).Error; err != nil {
+		zap.S().Errorf("查询标签失败: %v", err)
+		return nil, errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+	var rows []struct {
+		NoteID uint
+		Name   string
+	}
+	err := s.db.Table("note_tags").Select("note_tags.note_id, tags.name").
+		Joins("JOIN tags ON tags.id = note_tags.tag_id AND tags.deleted_at IS NULL").
+		Joins("JOIN notes ON notes.id = note_tags.note_id AND notes.deleted_at IS NULL").
+		Where("tags.user_id = ?", userID).Scan(&rows).Error
+	if err != nil {
+		zap.S().Errorf("统计标签笔记数失败: %v", err)
+		return nil, errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+
+	// 1. 按完整路径建立节点（补齐只作为上级出现的标签）
+	nodes := make(map[string]*model.TagNode)
+	var ensure func(path string) *model.TagNode
+	ensure = func(path string) *model.TagNode {
+		if node, ok := nodes[path]; ok {
+			return node
+		}
+		node := &model.TagNode{Name: path, Path: path}
+		if i := strings.LastIndex(path, model.TagPathSeparator); i >= 0 {
+			node.Name = path[i+len(model.TagPathSeparator):]
+			ensure(path[:i])
+		}
+		nodes[path] = node
+		return node
+	}
+	for _, tag := range tags {
+		if name := normalizeTagName(tag.Name); name != "" {
+			ensure(name).TagID = tag.ID
+		}
+	}
+
+	// 2. 统计笔记数：笔记计入自身标签及其全部上级（同一笔记在一个节点下只计一次）
+	counted := make(map[string]map[uint]bool)
+	for _, row := range rows {
+		path := normalizeTagName(row.Name)
+		if path == "" {
+			continue
+		}
+		ensure(path).NoteCount++
+		for {
+			if counted[path] == nil {
+				counted[path] = make(map[uint]bool)
+			}
+			counted[path][row.NoteID] = true
+			i := strings.LastIndex(path, model.TagPathSeparator)
+			if i < 0 {
+				break
+			}
+			path = path[:i]
+		}
+	}
+
+	// 3. 组装成树
+	children := make(map[string][]string)
+	var roots []string
+	for path := range nodes {
+		nodes[path].TotalCount = len(counted[path])
+		if i := strings.LastIndex(path, model.TagPathSeparator); i >= 0 {
+			children[path[:i]] = append(children[path[:i]], path)
+		} else {
+			roots = append(roots, path)
+		}
+	}
+	var build func(paths []string) []model.TagNode
+	build = func(paths []string) []model.TagNode {
+		sort.Strings(paths)
+		result := []model.TagNode{}
+		for _, path := range paths {
+			node := *nodes[path]
+			node.Children = build(children[path])
+			result = append(result, node)
+		}
+		return result
+	}
+	return build(roots), nil
+}
+
+// RenameTag 重命名标签，下级标签一并改名（如 project 改为 work 时 project/alpha 改为 work/alpha）；
+// 新名称已存在时合并到已有标签。使用这些标签的笔记重建搜索索引，自动打标签规则同步更新
+func (s *TagService) RenameTag(userID uint, oldName, newName string) error {
+	oldName, newName = normalizeTagName(oldName), normalizeTagName(newName)
+	if oldName == "" || newName == "" {
+		return errors.New("标签名称不能为空")
+	}
+	if oldName == newName {
+		return nil
+	}
+
+	// 1. 标签及其下级标签
+	var tags []model.Tag
+	cond, args := tagCondition("name", oldName)
+	if err := s.db.Where("user_id = ?", userID).Where(cond, args...).Find(&tags).Error; err != nil {
+		zap.S().Errorf("查询标签失败: %v", err)
+		return errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+	if len(tags) == 0 {
+		return errors.New(errcode.GetMsg(errcode.NotFound))
+	}
+	renamed := make(map[uint]string, len(tags))
+	tagIDs := make([]uint, 0, len(tags))
+	for _, tag := range tags {
+		name := replaceTagPrefix(tag.Name, oldName, newName)
+		if len([]rune(name)) > tagMaxName {
+			return fmt.Errorf("标签名称不能超过%d个字: %s", tagMaxName, name)
+		}
+		renamed[tag.ID] = name
+		tagIDs = append(tagIDs, tag.ID)
+	}
+	var noteIDs []uint
+	if err := s.db.Model(&model.NoteTag{}).Distinct("note_id").Where("tag_id IN ?", tagIDs).Pluck("note_id", &noteIDs).Error; err != nil {
+		zap.S().Errorf("查询标签关联失败: %v", err)
+		return errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+
+	// 2. 改名；新名称已有标签时把笔记关联转到已有标签后删除原标签
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		for _, tag := range tags {
+			var existing model.Tag
+			err := tx.Where("user_id = ? AND name = ? AND id NOT IN ?", userID, renamed[tag.ID], tagIDs).Limit(1).Find(&existing).Error
+			if err != nil {
+				return err
+			}
+			if existing.ID == 0 {
+				if err := tx.Model(&model.Tag{}).Where("id = ?", tag.ID).Update("name", renamed[tag.ID]).Error; err != nil {
+					return err
+				}
+				continue
+			}
+
+			if err := mergeTag(tx, tag.ID, existing.ID); err != nil {
+				return err
+			}
+		}
+
+		// 自动打标签规则中的标签名同步更新
+		var rules []model.TagRule
+		cond, args := tagCondition("tag_name", oldName)
+		if err := tx.Where("user_id = ?", userID).Where(cond, args...).Find(&rules).Error; err != nil {
+			return err
+		}
+		for _, rule := range rules {
+			name := replaceTagPrefix(rule.TagName, oldName, newName)
+			if err := tx.Model(&model.TagRule{}).Where("id = ?", rule.ID).Update("tag_name", name).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		zap.S().Errorf("重命名标签失败: %v", err)
+		return errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+
+	// 3. 标签名参与搜索索引，重建相关笔记的索引
+	return s.indexService.ReindexNotes(noteIDs)
+}
+
+// mergeTag 把标签 fromID 的笔记关联转到标签 toID，然后删除标签 fromID
+func mergeTag(tx *gorm.DB, fromID, toID uint) error {
+	var links []model.NoteTag
+	if err := tx.Where("tag_id = ?", fromID).Find(&links).Error; err != nil {
+		return err
+	}
+	for i := range links {
+		links[i].TagID = toID
+	}
+	if len(links) > 0 {
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&links).Error; err != nil {
+			return err
+		}
+	}
+	if err := tx.Where("tag_id = ?", fromID).Delete(&model.NoteTag{}).Error; err != nil {
+		return err
+	}
+	return tx.Delete(&model.Tag{}, fromID).Error
+}
+
+// NormalizeTagNames 规范化升级前保存的标签名（如 "a / b" 改为 "a/b"，与已有的同名标签合并）和打标签规则中的标签名，
+// 使按上级标签筛选和重命名能匹配到它们，启动时在后台执行
+func (s *TagService) NormalizeTagNames() {
+	// 1. 找出不规范的标签名（规范化后为空的保持不变）
+	var (
+		tags  []model.Tag
+		fixes []model.Tag
+	)
+	result := s.db.Select("id, user_id, name").FindInBatches(&tags, 500, func(tx *gorm.DB, batch int) error {
+		for _, tag := range tags {
+			if name := normalizeTagName(tag.Name); name != "" && name != tag.Name {
+				tag.Name = name
+				fixes = append(fixes, tag)
+			}
+		}
+		return nil
+	})
+	if result.Error != nil {
+		zap.S().Errorf("规范化标签名失败: %v", result.Error)
+		return
+	}
+
+	// 2. 逐个改名或合并，记录受影响的笔记
+	var noteIDs []uint
+	for _, tag := range fixes {
+		var ids []uint
+		err := s.db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Model(&model.NoteTag{}).Where("tag_id = ?", tag.ID).Pluck("note_id", &ids).Error; err != nil {
+				return err
+			}
+			var existing model.Tag
+			err := tx.Where("user_id = ? AND name = ? AND id <> ?", tag.UserID, tag.Name, tag.ID).Order("id ASC").Limit(1).Find(&existing).Error
+			if err != nil {
+				return err
+			}
+			if existing.ID == 0 {
+				return tx.Model(&model.Tag{}).Where("id = ?", tag.ID).Update("name", tag.Name).Error
+			}
+			return mergeTag(tx, tag.ID, existing.ID)
+		})
+		if err != nil {
+			zap.S().Errorf("规范化标签 %d 失败: %v", tag.ID, err)
+			continue
+		}
+		noteIDs = append(noteIDs, ids...)
+	}
+
+	// 3. 打标签规则中的标签名
+	var rules []model.TagRule
+	if err := s.db.Select("id, tag_name").Find(&rules).Error; err != nil {
+		zap.S().Errorf("规范化打标签规则失败: %v", err)
+	}
+	for _, rule := range rules {
+		if name := normalizeTagName(rule.TagName); name != "" && name != rule.TagName {
+			if err := s.db.Model(&model.TagRule{}).Where("id = ?", rule.ID).Update("tag_name", name).Error; err != nil {
+				zap.S().Errorf("规范化打标签规则 %d 失败: %v", rule.ID, err)
+			}
+		}
+	}
+
+	// 4. 标签名参与搜索索引，重建受影响笔记的索引
+	if err := s.indexService.ReindexNotes(noteIDs); err != nil {
+		zap.S().Errorf("规范化标签后重建索引失败: %v", err)
+	}
+}
+
+// SuggestTags 根据草稿标题和内容推荐用户已有的标签
+// 得分由两部分组成：标签词出现在草稿中（按 TF-IDF 关键词权重，标题中出现额外加分），以及内容相似的笔记使用了该标签
+func (s *TagService) SuggestTags(userID uint, title, content, format string, limit int) ([]model.TagSuggestion, error) {
+	suggestions := []model.TagSuggestion{}
+
+	// 1. 用户已有的标签
+	var tags []model.Tag
+	if err := s.db.Select("id, name").Where("user_id = ?", userID).Find(&tags).Error; err != nil {
+		zap.S().Errorf("查询标签失败: %v", err)
+		return nil, errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+	if len(tags) == 0 {
+		return suggestions, nil
+	}
+
+	// 2. 草稿转纯文本后分词，计算关键词权重
+	draft := model.Note{Title: title, Content: content, ContentFormat: format}
+	if err := prepareContent(&draft); err != nil {
+		return nil, err
+	}
+	counts := s.indexService.TermCounts(title, *draft.PlainText)
+	weights, err := s.relatedService.termWeights(userID, counts)
+	if err != nil {
+		return nil, err
+	}
+	var maxWeight float64
+	for _, w := range weights.weights {
+		maxWeight = math.Max(maxWeight, w)
+	}
+
+	scores := make(map[uint]float64, len(tags))
+	reasons := make(map[uint]string, len(tags))
+	normTitle := analyzer.Normalize(title)
+	normText := analyzer.Normalize(title + "\n" + *draft.PlainText)
+	for _, tag := range tags {
+		// 多级标签按最后一级匹配（如 project/alpha/design 按 design）
+		name := normalizeTagName(tag.Name)
+		name = analyzer.Normalize(name[strings.LastIndex(name, model.TagPathSeparator)+1:])
+		if name == "" || !strings.Contains(normText, name) {
+			continue
+		}
+		score := suggestKeywordScore
+		if w, ok := weights.weights[name]; ok && maxWeight > 0 {
+			score += suggestKeywordScore * w / maxWeight // 标签本身是草稿的关键词
+		}
+		if strings.Contains(normTitle, name) {
+			score += suggestTitleBonus
+		}
+		scores[tag.ID] = score
+		reasons[tag.ID] = "内容包含标签词"
+	}
+
+	// 3. 相似笔记使用的标签（按相似度占比加分）
+	similar, err := s.relatedService.similarNotes(userID, 0, counts, nil, suggestSimilarNotes)
+	if err != nil {
+		return nil, err
+	}
+	if len(similar) > 0 {
+		noteIDs := make([]uint, 0, len(similar))
+		similarity := make(map[uint]float64, len(similar))
+		var totalSimilarity float64
+		for _, note := range similar {
+			noteIDs = append(noteIDs, note.NoteID)
+			similarity[note.NoteID] = note.Score
+			totalSimilarity += note.Score
+		}
+		var rows []model.NoteTag
+		if err := s.db.Where("note_id IN ?", noteIDs).Find(&rows).Error; err != nil {
+			zap.S().Errorf("查询相似笔记标签失败: %v", err)
+			return nil, errors.New(errcode.GetMsg(errcode.ServerError))
+		}
+		for _, row := range rows {
+			scores[row.TagID] += suggestSimilarScore * similarity[row.NoteID] / totalSimilarity
+			if reasons[row.TagID] == "" {
+				reasons[row.TagID] = "相似笔记使用"
+			}
+		}
+	}
+
+	// 4. 排序取前 limit 个
+	for _, tag := range tags {
+		if score := scores[tag.ID]; score > 0 {
+			suggestions = append(suggestions, model.TagSuggestion{
+				Name:   tag.Name,
+				Score:  math.Round(score*1000) / 1000,
+				Reason: reasons[tag.ID],
+			})
+		}
+	}
+	sort.SliceStable(suggestions, func(i, j int) bool { return suggestions[i].Score > suggestions[j].Score })
+	if len(suggestions) > limit {
+		suggestions = suggestions[:limit]
+	}
+	return suggestions, nil
+}
+
+// validateTagRule 检查规则的匹配方式和表达式
+func validateTagRule(matchType, pattern string) error {
+	switch matchType {
+	case model.TagRuleKeyword:
+		if strings.TrimSpace(pattern) == "" {
+			return errors.New("关键词不能为空")
+		}
+	case model.TagRuleRegex:
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("正则表达式有误: %v", err)
+		}
+	default:
+		return fmt.Errorf("不支持的匹配方式: %s", matchType)
+	}
+	return nil
+}
+
+// CreateTagRule 创建自动打标签规则
+func (s *TagService) CreateTagRule(userID uint, tagName, matchType, pattern string) (*model.TagRule, error) {
+	if err := validateTagRule(matchType, pattern); err != nil {
+		return nil, err
+	}
+	rule := model.TagRule{UserID: userID, TagName: normalizeTagName(tagName), MatchType: matchType, Pattern: pattern, Enabled: true}
+	if err := s.db.Create(&rule).Error; err != nil {
+		zap.S().Errorf("创建打标签规则失败: %v", err)
+		return nil, errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+	return &rule, nil
+}
+
+// GetTagRuleList 查询用户的自动打标签规则
+func (s *TagService) GetTagRuleList(userID uint) ([]model.TagRule, error) {
+	var rules []model.TagRule
+	if err := s.db.Where("user_id = ?", userID).Order("id ASC").Find(&rules).Error; err != nil {
+		zap.S().Errorf("查询打标签规则失败: %v", err)
+		return nil, errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+	return rules, nil
+}
+
+// UpdateTagRule 修改自动打标签规则（enabled 为 nil 时保持原启用状态）
+func (s *TagService) UpdateTagRule(userID, ruleID uint, tagName, matchType, pattern string, enabled *bool) error {
+	rule, err := s.getTagRule(userID, ruleID)
+	if err != nil {
+		return err
+	}
+	if err := validateTagRule(matchType, pattern); err != nil {
+		return err
+	}
+
+	rule.TagName = normalizeTagName(tagName)
+	rule.MatchType = matchType
+	rule.Pattern = pattern
+	if enabled != nil {
+		rule.Enabled = *enabled
+	}
+	if err := s.db.Save(rule).Error; err != nil {
+		zap.S().Errorf("修改打标签规则失败: %v", err)
+		return errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+	return nil
+}
+
+// DeleteTagRule 删除自动打标签规则
+func (s *TagService) DeleteTagRule(userID, ruleID uint) error {
+	rule, err := s.getTagRule(userID, ruleID)
+	if err != nil {
+		return err
+	}
+	if err := s.db.Delete(rule).Error; err != nil {
+		zap.S().Errorf("删除打标签规则失败: %v", err)
+		return errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+	return nil
+}
+
+// getTagRule 查询用户的一条规则
+func (s *TagService) getTagRule(userID, ruleID uint) (*model.TagRule, error) {
+	var rule model.TagRule
+	err := s.db.Where("user_id = ? AND id = ?", userID, ruleID).First(&rule).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New(errcode.GetMsg(errcode.NotFound))
+		}
+		zap.S().Errorf("查询打标签规则失败: %v", err)
+		return nil, errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+	return &rule, nil
+}
+
+// MatchTagRules 返回标题或纯文本内容命中的规则对应的标签（保存笔记时调用）
+func (s *TagService) MatchTagRules(userID uint, title, content string) ([]string, error) {
+	var rules []model.TagRule
+	if err := s.db.Where("user_id = ? AND enabled = ?", userID, true).Find(&rules).Error; err != nil {
+		zap.S().Errorf("查询打标签规则失败: %v", err)
+		return nil, errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+
+	var tagNames []string
+	text := title + "\n" + content
+	lower := strings.ToLower(text)
+	for _, rule := range rules {
+		matched := false
+		switch rule.MatchType {
+		case model.TagRuleKeyword:
+			matched = strings.Contains(lower, strings.ToLower(rule.Pattern))
+		case model.TagRuleRegex:
+			re, err := regexp.Compile(rule.Pattern)
+			if err != nil {
+				continue // 保存时已校验，正常不会出现
+			}
+			matched = re.MatchString(text)
+		}
+		if matched {
+			tagNames = append(tagNames, rule.TagName)
+		}
+	}
+	return tagNames, nil
+}
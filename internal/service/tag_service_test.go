@@ -0,0 +1,65 @@
+package service
+
+import "testing"
+
+func TestNormalizeTagName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"work", "work"},
+		{"  work  ", "work"},
+		{"a / b", "a/b"},
+		{"project/alpha/design", "project/alpha/design"},
+		{"/a//b/", "a/b"},
+		{" 工作 /\t会议 ", "工作/会议"},
+		{"a b/c d", "a b/c d"},
+		{" / ", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := normalizeTagName(tt.name); got != tt.want {
+			t.Errorf("normalizeTagName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestTagCondition(t *testing.T) {
+	tests := []struct {
+		name    string
+		exact   string
+		pattern string
+	}{
+		{"project", "project", "project/%"},
+		{" a / b ", "a/b", "a/b/%"},
+		{"100%_done", "100%_done", `100\%\_done/%`},
+	}
+	for _, tt := range tests {
+		cond, args := tagCondition("tags.name", tt.name)
+		if cond != "(tags.name = ? OR tags.name LIKE ?)" {
+			t.Errorf("tagCondition(%q) cond = %q", tt.name, cond)
+		}
+		if len(args) != 2 || args[0] != tt.exact || args[1] != tt.pattern {
+			t.Errorf("tagCondition(%q) args = %q, want [%q %q]", tt.name, args, tt.exact, tt.pattern)
+		}
+	}
+}
+
+func TestReplaceTagPrefix(t *testing.T) {
+	tests := []struct {
+		name, oldName, newName string
+		want                   string
+	}{
+		{"project", "project", "work", "work"},
+		{"project/alpha", "project", "work", "work/alpha"},
+		{"project/alpha/design", "project/alpha", "archive/alpha", "archive/alpha/design"},
+		{"project / alpha", "project", "work", "work/alpha"},
+		{"a/b", "a", "x/y", "x/y/b"},
+		{"a/b", "a", "中文", "中文/b"},
+	}
+	for _, tt := range tests {
+		if got := replaceTagPrefix(tt.name, tt.oldName, tt.newName); got != tt.want {
+			t.Errorf("replaceTagPrefix(%q, %q, %q) = %q, want %q", tt.name, tt.oldName, tt.newName, got, tt.want)
+		}
+	}
+}
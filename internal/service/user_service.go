@@ -25,10 +25,10 @@ func NewUserService(db *gorm.DB, jwtConf config.JwtConfig) *UserService {
 }
 
 // Register 用户注册
-func (s *UserService) Register(username, password, email string) error {
+func (s *UserService) Register(username, password, email string) (uint, error) {
 	// 1. 校验密码强度
 	if !validator.CheckPasswordStrength(password) {
-		return fmt.Errorf("密码强度不足（需8位以上，包含字母和数字）")
+		return 0, fmt.Errorf("密码强度不足（需8位以上，包含字母和数字）")
 	}
 
 	// 2. 检查用户名/邮箱是否已存在
@@ -36,19 +36,19 @@ func (s *UserService) Register(username, password, email string) error {
 	err := s.db.Where("username = ?", username).First(&existUser).Error
 	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
 		zap.S().Errorf("查询用户名失败: %v", err)
-		return fmt.Errorf(errcode.GetMsg(errcode.ServerError))
+		return 0, errors.New(errcode.GetMsg(errcode.ServerError))
 	}
 	if existUser.ID > 0 {
-		return fmt.Errorf(errcode.GetMsg(errcode.DuplicateData))
+		return 0, errors.New(errcode.GetMsg(errcode.DuplicateData))
 	}
 
 	err = s.db.Where("email = ?", email).First(&existUser).Error
 	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
 		zap.S().Errorf("查询邮箱失败: %v", err)
-		return fmt.Errorf(errcode.GetMsg(errcode.ServerError))
+		return 0, errors.New(errcode.GetMsg(errcode.ServerError))
 	}
 	if existUser.ID > 0 {
-		return fmt.Errorf(errcode.GetMsg(errcode.DuplicateData))
+		return 0, errors.New(errcode.GetMsg(errcode.DuplicateData))
 	}
 
 	// 3. 创建用户（密码会在 BeforeSave 钩子中自动加密）
@@ -59,36 +59,36 @@ func (s *UserService) Register(username, password, email string) error {
 	}
 	if err := s.db.Create(&user).Error; err != nil {
 		zap.S().Errorf("创建用户失败: %v", err)
-		return fmt.Errorf(errcode.GetMsg(errcode.ServerError))
+		return 0, errors.New(errcode.GetMsg(errcode.ServerError))
 	}
 
-	return nil
+	return user.ID, nil
 }
 
-// Login 用户登录（返回 Token）
-func (s *UserService) Login(username, password string) (string, error) {
+// Login 用户登录（返回 Token 和用户ID；用户存在但密码错误时也返回用户ID，便于审计）
+func (s *UserService) Login(username, password string) (string, uint, error) {
 	// 1. 查询用户
 	var user model.User
 	err := s.db.Where("username = ?", username).First(&user).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return "", fmt.Errorf(errcode.GetMsg(errcode.PasswordError))
+			return "", 0, errors.New(errcode.GetMsg(errcode.PasswordError))
 		}
 		zap.S().Errorf("查询用户失败: %v", err)
-		return "", fmt.Errorf(errcode.GetMsg(errcode.ServerError))
+		return "", 0, errors.New(errcode.GetMsg(errcode.ServerError))
 	}
 
 	// 2. 验证密码
 	if !user.CheckPassword(password) {
-		return "", fmt.Errorf(errcode.GetMsg(errcode.PasswordError))
+		return "", user.ID, errors.New(errcode.GetMsg(errcode.PasswordError))
 	}
 
 	// 3. 生成 JWT Token
 	token, err := jwt.GenerateToken(user.ID, user.Username, s.jwtConf)
 	if err != nil {
 		zap.S().Errorf("生成 Token 失败: %v", err)
-		return "", fmt.Errorf(errcode.GetMsg(errcode.ServerError))
+		return "", user.ID, errors.New(errcode.GetMsg(errcode.ServerError))
 	}
 
-	return token, nil
+	return token, user.ID, nil
 }
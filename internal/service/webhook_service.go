@@ -0,0 +1,483 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/JokerYuan-lang/MyNoteBook/internal/config"
+	"github.com/JokerYuan-lang/MyNoteBook/internal/model"
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/errcode"
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/webhook"
+	"github.com/go-redis/redis/v8"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	webhookQueueKey    = "webhook:queue" // 待投递队列（List，元素为投递ID）
+	webhookRetryKey    = "webhook:retry" // 延迟重试集合（ZSet，分值为重试时间戳）
+	webhookMaxAttempts = 6               // 最大尝试次数
+	webhookBaseBackoff = 10 * time.Second
+	webhookWorkers     = 4                // 投递协程数
+	webhookStaleAfter  = 10 * time.Minute // 待投递、投递中的记录超过该时间未更新视为丢失，重新入队
+)
+
+// 可订阅的事件
+var webhookEvents = map[string]bool{
+	model.WebhookEventNoteCreated: true,
+	model.WebhookEventNoteUpdated: true,
+	model.WebhookEventNoteDeleted: true,
+}
+
+// WebhookPayload 推送内容
+type WebhookPayload struct {
+	Event     string           `json:"event"`
+	Timestamp int64            `json:"timestamp"`
+	Data      *WebhookNoteData `json:"data,omitempty"`
+}
+
+// WebhookNoteData 推送中的笔记信息
+type WebhookNoteData struct {
+	NoteID    uint      `json:"note_id"`
+	UserID    uint      `json:"user_id"`
+	Title     string    `json:"title"`
+	Category  string    `json:"category"`
+	Tags      []string  `json:"tags"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// WebhookService Webhook 订阅与投递
+type WebhookService struct {
+	db     *gorm.DB
+	rdb    *redis.Client
+	allow  *hostAllowList // 允许推送的内网主机
+	client *http.Client
+}
+
+// NewWebhookService 创建 WebhookService 实例
+func NewWebhookService(db *gorm.DB, rdb *redis.Client, conf config.WebhookConfig) *WebhookService {
+	allow := newHostAllowList(conf.AllowPrivateHosts)
+	return &WebhookService{
+		db:     db,
+		rdb:    rdb,
+		allow:  allow,
+		client: newWebhookHTTPClient(allow),
+	}
+}
+
+// newWebhookHTTPClient 创建投递用 HTTP 客户端：只能访问公网地址和白名单中的内网主机，且不跟随重定向（3xx 视为失败）
+func newWebhookHTTPClient(allow *hostAllowList) *http.Client {
+	client := newPublicHTTPClient(10*time.Second, allow)
+	client.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	return client
+}
+
+// validateWebhookURL 检查推送地址：只允许 http/https，主机名须解析到公网地址，或在内网白名单中
+func validateWebhookURL(rawURL string, allow *hostAllowList) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return errors.New("推送地址只支持 http/https")
+	}
+	return allow.checkHost(u.Hostname())
+}
+
+// CreateWebhook 创建 Webhook 订阅（自动生成签名密钥）
+func (s *WebhookService) CreateWebhook(userID uint, url string, events, tagFilter []string) (*model.Webhook, error) {
+	// 投递时连接前还会再检查一次地址（防止 DNS 重绑定）
+	if err := validateWebhookURL(url, s.allow); err != nil {
+		return nil, err
+	}
+	for _, event := range events {
+		if !webhookEvents[event] {
+			return nil, fmt.Errorf("不支持的事件类型: %s", event)
+		}
+	}
+
+	secret := make([]byte, 20)
+	if _, err := rand.Read(secret); err != nil {
+		zap.S().Errorf("生成 Webhook 密钥失败: %v", err)
+		return nil, errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+
+	hook := model.Webhook{
+		UserID:    userID,
+		URL:       url,
+		Secret:    hex.EncodeToString(secret),
+		Events:    strings.Join(events, ","),
+		TagFilter: strings.Join(tagFilter, ","),
+		Enabled:   true,
+	}
+	if err := s.db.Create(&hook).Error; err != nil {
+		zap.S().Errorf("创建 Webhook 失败: %v", err)
+		return nil, errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+	return &hook, nil
+}
+
+// GetWebhookList 查询用户的 Webhook 列表
+func (s *WebhookService) GetWebhookList(userID uint) ([]model.Webhook, error) {
+	var hooks []model.Webhook
+	if err := s.db.Where("user_id = ?", userID).Order("id DESC").Find(&hooks).Error; err != nil {
+		zap.S().Errorf("查询 Webhook 列表失败: %v", err)
+		return nil, errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+	return hooks, nil
+}
+
+// SetWebhookEnabled 启用/停用 Webhook
+func (s *WebhookService) SetWebhookEnabled(userID, webhookID uint, enabled bool) error {
+	hook, err := s.getWebhook(userID, webhookID)
+	if err != nil {
+		return err
+	}
+	if err := s.db.Model(hook).Update("enabled", enabled).Error; err != nil {
+		zap.S().Errorf("更新 Webhook 失败: %v", err)
+		return errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+	return nil
+}
+
+// DeleteWebhook 删除 Webhook
+func (s *WebhookService) DeleteWebhook(userID, webhookID uint) error {
+	hook, err := s.getWebhook(userID, webhookID)
+	if err != nil {
+		return err
+	}
+	if err := s.db.Delete(hook).Error; err != nil {
+		zap.S().Errorf("删除 Webhook 失败: %v", err)
+		return errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+	return nil
+}
+
+// PingWebhook 向 Webhook 发送一条测试推送（同样走队列和重试）
+func (s *WebhookService) PingWebhook(userID, webhookID uint) error {
+	hook, err := s.getWebhook(userID, webhookID)
+	if err != nil {
+		return err
+	}
+	return s.enqueue(hook, &WebhookPayload{Event: model.WebhookEventPing, Timestamp: time.Now().Unix()})
+}
+
+// GetDeliveryList 分页查询投递记录
+func (s *WebhookService) GetDeliveryList(userID, webhookID uint, page, pageSize int) ([]model.WebhookDelivery, int64, error) {
+	var (
+		deliveries []model.WebhookDelivery
+		total      int64
+	)
+	db := s.db.Model(&model.WebhookDelivery{}).Where("user_id = ?", userID)
+	if webhookID > 0 {
+		db = db.Where("webhook_id = ?", webhookID)
+	}
+
+	if err := db.Count(&total).Error; err != nil {
+		zap.S().Errorf("统计投递记录失败: %v", err)
+		return nil, 0, errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+
+	offset := (page - 1) * pageSize
+	if err := db.Offset(offset).Limit(pageSize).Order("id DESC").Find(&deliveries).Error; err != nil {
+		zap.S().Errorf("查询投递记录失败: %v", err)
+		return nil, 0, errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+
+	return deliveries, total, nil
+}
+
+// Dispatch 笔记事件发生时，投递给所有匹配事件类型和标签过滤的 Webhook
+func (s *WebhookService) Dispatch(event string, note *model.Note, tagNames []string) {
+	var hooks []model.Webhook
+	if err := s.db.Where("user_id = ? AND enabled = ?", note.UserID, true).Find(&hooks).Error; err != nil {
+		zap.S().Errorf("查询 Webhook 失败: %v", err)
+		return
+	}
+
+	payload := &WebhookPayload{
+		Event:     event,
+		Timestamp: time.Now().Unix(),
+		Data: &WebhookNoteData{
+			NoteID:    note.ID,
+			UserID:    note.UserID,
+			Title:     note.Title,
+			Category:  note.Category,
+			Tags:      tagNames,
+			UpdatedAt: note.UpdatedAt,
+		},
+	}
+	for i := range hooks {
+		if !containsItem(hooks[i].Events, event) || !matchTagFilter(hooks[i].TagFilter, tagNames) {
+			continue
+		}
+		// 单个 Webhook 入队失败不影响其他订阅
+		_ = s.enqueue(&hooks[i], payload)
+	}
+}
+
+// Run 启动投递协程和重试调度（阻塞直到 ctx 结束，多实例部署安全）
+func (s *WebhookService) Run(ctx context.Context) {
+	for i := 0; i < webhookWorkers; i++ {
+		go s.work(ctx)
+	}
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	staleTicker := time.NewTicker(time.Minute)
+	defer staleTicker.Stop()
+	s.requeueStale(ctx) // 启动时先找回上次退出时丢失的任务
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.promoteRetries(ctx)
+		case <-staleTicker.C:
+			s.requeueStale(ctx)
+		}
+	}
+}
+
+// enqueue 生成投递记录并放入 Redis 队列
+func (s *WebhookService) enqueue(hook *model.Webhook, payload *WebhookPayload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		zap.S().Errorf("序列化 Webhook 内容失败: %v", err)
+		return errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+
+	delivery := model.WebhookDelivery{
+		WebhookID: hook.ID,
+		UserID:    hook.UserID,
+		Event:     payload.Event,
+		Payload:   string(body),
+		Status:    model.DeliveryStatusPending,
+	}
+	if err := s.db.Create(&delivery).Error; err != nil {
+		zap.S().Errorf("创建投递记录失败: %v", err)
+		return errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+	if err := s.rdb.LPush(context.Background(), webhookQueueKey, delivery.ID).Err(); err != nil {
+		zap.S().Errorf("Webhook 入队失败: %v", err)
+		return errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+	return nil
+}
+
+// work 从队列中取出投递任务并执行
+func (s *WebhookService) work(ctx context.Context) {
+	for ctx.Err() == nil {
+		result, err := s.rdb.BRPop(ctx, 5*time.Second, webhookQueueKey).Result()
+		if err != nil {
+			if !errors.Is(err, redis.Nil) && ctx.Err() == nil {
+				zap.S().Errorf("读取 Webhook 队列失败: %v", err)
+				time.Sleep(time.Second)
+			}
+			continue
+		}
+		id, err := strconv.ParseUint(result[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		s.deliver(ctx, uint(id))
+	}
+}
+
+// promoteRetries 把到期的重试任务移回待投递队列（ZRem 成功者获得任务，避免多实例重复投递）
+func (s *WebhookService) promoteRetries(ctx context.Context) {
+	ids, err := s.rdb.ZRangeByScore(ctx, webhookRetryKey, &redis.ZRangeBy{
+		Min: "-inf",
+		Max: strconv.FormatInt(time.Now().Unix(), 10),
+	}).Result()
+	if err != nil {
+		zap.S().Errorf("读取 Webhook 重试队列失败: %v", err)
+		return
+	}
+	for _, id := range ids {
+		removed, err := s.rdb.ZRem(ctx, webhookRetryKey, id).Result()
+		if err != nil || removed == 0 {
+			continue
+		}
+		s.rdb.LPush(ctx, webhookQueueKey, id)
+	}
+}
+
+// requeueStale 把长时间未处理的投递记录改回 pending 并重新入队
+// 任务出队后进程崩溃、入队或安排重试失败时，记录会一直停在 pending；推送过程中进程退出时会停在 delivering
+// （推送结果未知，按至少一次投递重新推送）。重复入队的任务在 deliver 领取时跳过；条件更新保证多实例下只有一个实例重新入队
+func (s *WebhookService) requeueStale(ctx context.Context) {
+	deadline := time.Now().Add(-webhookStaleAfter)
+	var deliveries []model.WebhookDelivery
+	err := s.db.Select("id, status, updated_at").
+		Where("updated_at < ? AND (status = ? OR (status = ? AND (next_retry_at IS NULL OR next_retry_at < ?)))",
+			deadline, model.DeliveryStatusDelivering, model.DeliveryStatusPending, deadline).
+		Order("id").Limit(100).Find(&deliveries).Error
+	if err != nil {
+		zap.S().Errorf("查询滞留的投递记录失败: %v", err)
+		return
+	}
+	for _, delivery := range deliveries {
+		result := s.db.Model(&model.WebhookDelivery{}).
+			Where("id = ? AND status = ? AND updated_at = ?", delivery.ID, delivery.Status, delivery.UpdatedAt).
+			Updates(map[string]interface{}{"status": model.DeliveryStatusPending, "updated_at": time.Now()})
+		if result.Error != nil || result.RowsAffected == 0 {
+			continue
+		}
+		if err := s.rdb.LPush(ctx, webhookQueueKey, delivery.ID).Err(); err != nil {
+			zap.S().Errorf("Webhook 重新入队失败: %v", err)
+			return
+		}
+	}
+}
+
+// deliver 领取并执行一次投递，失败时按指数退避安排重试
+func (s *WebhookService) deliver(ctx context.Context, deliveryID uint) {
+	// 1. 领取任务：只有把 pending 改为 delivering 的协程才推送（任务重复入队或多实例时不会重复推送）
+	result := s.db.Model(&model.WebhookDelivery{}).
+		Where("id = ? AND status = ?", deliveryID, model.DeliveryStatusPending).
+		Updates(map[string]interface{}{"status": model.DeliveryStatusDelivering, "updated_at": time.Now()})
+	if result.Error != nil {
+		zap.S().Errorf("领取投递任务失败: %v", result.Error)
+		return
+	}
+	if result.RowsAffected == 0 {
+		return
+	}
+
+	var (
+		delivery model.WebhookDelivery
+		hook     model.Webhook
+	)
+	if err := s.db.First(&delivery, deliveryID).Error; err != nil {
+		zap.S().Errorf("查询投递记录失败: %v", err)
+		return
+	}
+	// Webhook 已删除则直接放弃
+	if err := s.db.First(&hook, delivery.WebhookID).Error; err != nil {
+		s.db.Model(&delivery).Updates(map[string]interface{}{"status": model.DeliveryStatusFailed, "error": "Webhook 不存在"})
+		return
+	}
+
+	// 2. 推送并记录结果
+	code, err := s.post(ctx, &hook, &delivery)
+	recordAttempt(&delivery, code, err, time.Now())
+	if err := s.db.Save(&delivery).Error; err != nil {
+		zap.S().Errorf("更新投递记录失败: %v", err)
+	}
+
+	if delivery.NextRetryAt != nil {
+		s.rdb.ZAdd(ctx, webhookRetryKey, &redis.Z{
+			Score:  float64(delivery.NextRetryAt.Unix()),
+			Member: delivery.ID,
+		})
+	}
+}
+
+// recordAttempt 记录一次推送结果：成功；失败且已达最大次数时放弃；否则回到 pending，按指数退避（10s、20s、40s、80s...）安排重试
+func recordAttempt(delivery *model.WebhookDelivery, code int, err error, now time.Time) {
+	delivery.Attempts++
+	delivery.ResponseCode = code
+	delivery.NextRetryAt = nil
+	switch {
+	case err == nil:
+		delivery.Status = model.DeliveryStatusSuccess
+		delivery.Error = ""
+	case delivery.Attempts >= webhookMaxAttempts:
+		delivery.Status = model.DeliveryStatusFailed
+		delivery.Error = truncateError(err)
+	default:
+		delivery.Status = model.DeliveryStatusPending
+		delivery.Error = truncateError(err)
+		next := now.Add(webhookBaseBackoff << (delivery.Attempts - 1))
+		delivery.NextRetryAt = &next
+	}
+}
+
+// post 发送带签名的 HTTP 请求，2xx 视为成功
+func (s *WebhookService) post(ctx context.Context, hook *model.Webhook, delivery *model.WebhookDelivery) (int, error) {
+	body := []byte(delivery.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "MyNoteBook-Webhook")
+	req.Header.Set(webhook.HeaderEvent, delivery.Event)
+	req.Header.Set(webhook.HeaderDelivery, strconv.FormatUint(uint64(delivery.ID), 10))
+	req.Header.Set(webhook.HeaderSignature, webhook.Sign(hook.Secret, body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("响应状态码 %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// getWebhook 查询属于当前用户的 Webhook
+func (s *WebhookService) getWebhook(userID, webhookID uint) (*model.Webhook, error) {
+	var hook model.Webhook
+	err := s.db.Where("user_id = ? AND id = ?", userID, webhookID).First(&hook).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New(errcode.GetMsg(errcode.NotFound))
+		}
+		zap.S().Errorf("查询 Webhook 失败: %v", err)
+		return nil, errors.New(errcode.GetMsg(errcode.ServerError))
+	}
+	return &hook, nil
+}
+
+// containsItem 判断逗号分隔的列表中是否包含 item
+func containsItem(list, item string) bool {
+	for _, v := range strings.Split(list, ",") {
+		if strings.TrimSpace(v) == item {
+			return true
+		}
+	}
+	return false
+}
+
+// matchTagFilter 标签过滤为空时全部匹配，否则笔记至少包含其中一个标签或它的下级标签（与按标签筛选笔记的规则一致）
+func matchTagFilter(filter string, tagNames []string) bool {
+	if strings.TrimSpace(filter) == "" {
+		return true
+	}
+	for _, item := range strings.Split(filter, ",") {
+		if item = normalizeTagName(item); item == "" {
+			continue
+		}
+		for _, name := range tagNames {
+			name = normalizeTagName(name)
+			if name == item || strings.HasPrefix(name, item+model.TagPathSeparator) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// truncateError 截断错误信息（避免超出字段长度）
+func truncateError(err error) string {
+	msg := []rune(err.Error())
+	if len(msg) > 255 {
+		msg = msg[:255]
+	}
+	return string(msg)
+}
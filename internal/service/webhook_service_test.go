@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/JokerYuan-lang/MyNoteBook/internal/model"
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/webhook"
+)
+
+// webhookReceiver 记录收到的推送，前 failures 次返回 500
+type webhookReceiver struct {
+	mu         sync.Mutex
+	failures   int
+	requests   int
+	signatures []string
+	deliveries []string
+	bodies     [][]byte
+}
+
+func (r *webhookReceiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests++
+	r.signatures = append(r.signatures, req.Header.Get(webhook.HeaderSignature))
+	r.deliveries = append(r.deliveries, req.Header.Get(webhook.HeaderDelivery))
+	r.bodies = append(r.bodies, body)
+	if r.requests <= r.failures {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func newTestWebhook(t *testing.T, receiver *webhookReceiver) (*WebhookService, *model.Webhook) {
+	server := httptest.NewServer(receiver)
+	t.Cleanup(server.Close)
+	allow := newHostAllowList([]string{"127.0.0.1"})
+	if err := validateWebhookURL(server.URL, allow); err != nil {
+		t.Fatalf("validateWebhookURL(%q) with allow-list: %v", server.URL, err)
+	}
+	return &WebhookService{allow: allow, client: newWebhookHTTPClient(allow)},
+		&model.Webhook{URL: server.URL, Secret: "test-secret"}
+}
+
+func TestWebhookRetryBackoff(t *testing.T) {
+	receiver := &webhookReceiver{failures: 2}
+	s, hook := newTestWebhook(t, receiver)
+	delivery := &model.WebhookDelivery{Event: model.WebhookEventPing, Payload: `{"event":"ping"}`, Status: model.DeliveryStatusPending}
+	delivery.ID = 42
+
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	wantRetry := []time.Duration{10 * time.Second, 20 * time.Second}
+	for attempt := 1; attempt <= 3; attempt++ {
+		code, err := s.post(context.Background(), hook, delivery)
+		recordAttempt(delivery, code, err, now)
+		if delivery.Attempts != attempt {
+			t.Fatalf("attempt %d: Attempts = %d", attempt, delivery.Attempts)
+		}
+		if attempt <= len(wantRetry) {
+			if delivery.Status != model.DeliveryStatusPending || delivery.ResponseCode != http.StatusInternalServerError || delivery.Error == "" {
+				t.Fatalf("attempt %d: delivery = %+v, want pending with 500", attempt, delivery)
+			}
+			if delivery.NextRetryAt == nil || !delivery.NextRetryAt.Equal(now.Add(wantRetry[attempt-1])) {
+				t.Fatalf("attempt %d: NextRetryAt = %v, want %v", attempt, delivery.NextRetryAt, now.Add(wantRetry[attempt-1]))
+			}
+			continue
+		}
+		if delivery.Status != model.DeliveryStatusSuccess || delivery.ResponseCode != http.StatusNoContent || delivery.Error != "" || delivery.NextRetryAt != nil {
+			t.Fatalf("attempt %d: delivery = %+v, want success", attempt, delivery)
+		}
+	}
+
+	// 每次推送都带同一个投递ID和有效签名
+	if receiver.requests != 3 {
+		t.Fatalf("receiver got %d requests, want 3", receiver.requests)
+	}
+	for i := range receiver.bodies {
+		if receiver.deliveries[i] != "42" {
+			t.Errorf("request %d: delivery header = %q, want 42", i, receiver.deliveries[i])
+		}
+		if string(receiver.bodies[i]) != delivery.Payload {
+			t.Errorf("request %d: body = %q", i, receiver.bodies[i])
+		}
+		if !webhook.Verify(hook.Secret, receiver.bodies[i], receiver.signatures[i]) {
+			t.Errorf("request %d: invalid signature %q", i, receiver.signatures[i])
+		}
+		if webhook.Verify("other-secret", receiver.bodies[i], receiver.signatures[i]) {
+			t.Errorf("request %d: signature verified with the wrong secret", i)
+		}
+	}
+}
+
+func TestWebhookGiveUp(t *testing.T) {
+	receiver := &webhookReceiver{failures: webhookMaxAttempts}
+	s, hook := newTestWebhook(t, receiver)
+	delivery := &model.WebhookDelivery{Event: model.WebhookEventPing, Payload: "{}", Status: model.DeliveryStatusPending}
+
+	now := time.Now()
+	for i := 0; i < webhookMaxAttempts; i++ {
+		code, err := s.post(context.Background(), hook, delivery)
+		recordAttempt(delivery, code, err, now)
+	}
+	if delivery.Status != model.DeliveryStatusFailed || delivery.NextRetryAt != nil || delivery.Attempts != webhookMaxAttempts {
+		t.Errorf("delivery = %+v, want failed after %d attempts", delivery, webhookMaxAttempts)
+	}
+}
+
+func TestWebhookPrivateAddress(t *testing.T) {
+	receiver := &webhookReceiver{}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	// 不在白名单中的内网地址：创建时和连接时都会被拒绝
+	if err := validateWebhookURL(server.URL, nil); err == nil {
+		t.Errorf("validateWebhookURL(%q) should reject loopback", server.URL)
+	}
+	s := &WebhookService{client: newWebhookHTTPClient(nil)}
+	if _, err := s.post(context.Background(), &model.Webhook{URL: server.URL}, &model.WebhookDelivery{}); err == nil {
+		t.Error("post to loopback should fail without allow-list")
+	}
+	if receiver.requests != 0 {
+		t.Errorf("receiver got %d requests, want 0", receiver.requests)
+	}
+}
+
+func TestHostAllowList(t *testing.T) {
+	allow := newHostAllowList([]string{" Receiver.Local ", "10.1.0.0/16", "::1", "bad/cidr", ""})
+	tests := []struct {
+		host string
+		want bool
+	}{
+		{"receiver.local", true},
+		{"RECEIVER.LOCAL", true},
+		{"other.local", false},
+		{"10.1.2.3", true},
+		{"10.2.0.1", false},
+		{"::1", true},
+		{"0:0:0:0:0:0:0:1", true},
+		{"127.0.0.1", false},
+	}
+	for _, tt := range tests {
+		if got := allow.allowHost(tt.host); got != tt.want {
+			t.Errorf("allowHost(%q) = %v, want %v", tt.host, got, tt.want)
+		}
+	}
+	if (*hostAllowList)(nil).allowHost("127.0.0.1") {
+		t.Error("nil allow-list should allow nothing")
+	}
+}
+
+func TestMatchTagFilter(t *testing.T) {
+	tests := []struct {
+		filter string
+		tags   []string
+		want   bool
+	}{
+		{"", nil, true},
+		{" ", []string{"a"}, true},
+		{"work", []string{"work"}, true},
+		{"project", []string{"project/alpha"}, true},
+		{"project/alpha", []string{"project/alpha/design"}, true},
+		{"project", []string{"projects"}, false},
+		{"project/alpha", []string{"project"}, false},
+		{"draft, project / alpha", []string{"project/alpha"}, true},
+		{"work", []string{"home"}, false},
+		{"work", nil, false},
+	}
+	for _, tt := range tests {
+		if got := matchTagFilter(tt.filter, tt.tags); got != tt.want {
+			t.Errorf("matchTagFilter(%q, %q) = %v, want %v", tt.filter, tt.tags, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+// Package analyzer 搜索分词：把标题、正文、搜索关键词切分为索引词
+// 分词器可插拔，内置 mixed（中英文混合，中文按词典分词）和 whitespace（按空白和标点切分）
+package analyzer
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"unicode"
+
+	"golang.org/x/text/width"
+)
+
+// Tokenizer 分词器（返回的词可以重复，用于统计词频）
+type Tokenizer interface {
+	Tokenize(text string) []string
+}
+
+// Config 分词器配置
+type Config struct {
+	Name     string // 分词器名称，为空时使用 mixed
+	DictPath string // 自定义词典（每行「词语 词频」，词频可省略），追加到内置词典
+}
+
+// Factory 根据配置创建分词器
+type Factory func(conf Config) (Tokenizer, error)
+
+var (
+	factoriesMu sync.RWMutex
+	factories   = map[string]Factory{
+		"mixed": func(conf Config) (Tokenizer, error) {
+			zh, err := NewChinese(conf.DictPath)
+			if err != nil {
+				return nil, err
+			}
+			return NewMixed(zh), nil
+		},
+		"whitespace": func(conf Config) (Tokenizer, error) {
+			return Whitespace{}, nil
+		},
+	}
+)
+
+// Register 注册分词器（同名覆盖）
+func Register(name string, factory Factory) {
+	factoriesMu.Lock()
+	defer factoriesMu.Unlock()
+	factories[name] = factory
+}
+
+// New 按名称创建分词器
+func New(conf Config) (Tokenizer, error) {
+	name := conf.Name
+	if name == "" {
+		name = "mixed"
+	}
+	factoriesMu.RLock()
+	factory, ok := factories[name]
+	factoriesMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("未知的分词器: %s", name)
+	}
+	return factory(conf)
+}
+
+// Normalize 统一字符形式：全角转半角、转小写
+func Normalize(text string) string {
+	return strings.ToLower(width.Fold.String(text))
+}
+
+// 停用词（不进入索引）
+var stopWords = map[string]bool{
+	"的": true, "了": true, "和": true, "是": true, "在": true, "就": true, "都": true, "而": true,
+	"及": true, "与": true, "着": true, "或": true, "也": true, "把": true, "被": true, "让": true,
+	"这": true, "那": true, "之": true, "一个": true, "没有": true, "我们": true, "你们": true, "他们": true,
+	"a": true, "an": true, "the": true, "and": true, "or": true, "of": true, "to": true, "in": true,
+	"on": true, "at": true, "for": true, "is": true, "are": true, "was": true, "be": true, "it": true,
+}
+
+// IsStopWord 是否为停用词
+func IsStopWord(term string) bool {
+	return stopWords[term]
+}
+
+// Whitespace 按空白和标点切分的分词器（不处理中文词语，连续的中文作为一个词）
+type Whitespace struct{}
+
+// Tokenize 切分文本
+func (Whitespace) Tokenize(text string) []string {
+	return strings.FieldsFunc(Normalize(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// Mixed 中英文混合分词器：中文按词典分词，英文和数字按单词切分，去掉标点和停用词
+type Mixed struct {
+	zh *Chinese
+}
+
+// NewMixed 创建中英文混合分词器
+func NewMixed(zh *Chinese) *Mixed {
+	return &Mixed{zh: zh}
+}
+
+// Tokenize 切分文本
+func (m *Mixed) Tokenize(text string) []string {
+	var (
+		terms []string
+		run   []rune
+		han   bool // 当前片段是否为中文
+	)
+	flush := func() {
+		if len(run) == 0 {
+			return
+		}
+		if han {
+			for _, term := range m.zh.Cut(string(run)) {
+				if !IsStopWord(term) {
+					terms = append(terms, term)
+				}
+			}
+		} else if term := string(run); !IsStopWord(term) {
+			terms = append(terms, term)
+		}
+		run = run[:0]
+	}
+
+	for _, r := range Normalize(text) {
+		switch {
+		case unicode.Is(unicode.Han, r):
+			if !han {
+				flush()
+			}
+			han = true
+			run = append(run, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if han {
+				flush()
+			}
+			han = false
+			run = append(run, r)
+		default:
+			flush()
+		}
+	}
+	flush()
+	return terms
+}
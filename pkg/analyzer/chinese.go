@@ -0,0 +1,161 @@
+package analyzer
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	_ "embed"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// 内置词典：取自 jieba 词典（MIT 协议，经 gse 整理），保留词频最高的 5 万个多字词和全部单字
+//
+//go:embed zh_dict.txt.gz
+var builtinDict []byte
+
+// maxWordLen 词典中词语的最大长度（字）
+const maxWordLen = 6
+
+// Chinese 基于词典的中文分词器：在所有可能的切分中选择词频概率最大的一种（与 jieba 的精确模式相同，不含 HMM 新词发现）
+type Chinese struct {
+	freq    map[string]float64 // 词语 -> 对数概率
+	minFreq float64            // 未登录单字的对数概率
+}
+
+// NewChinese 加载内置词典（dictPath 不为空时追加自定义词典）
+func NewChinese(dictPath string) (*Chinese, error) {
+	counts := make(map[string]int)
+	zr, err := gzip.NewReader(bytes.NewReader(builtinDict))
+	if err != nil {
+		return nil, fmt.Errorf("读取内置词典失败: %w", err)
+	}
+	if err := readDict(zr, counts); err != nil {
+		return nil, fmt.Errorf("读取内置词典失败: %w", err)
+	}
+	if dictPath != "" {
+		f, err := os.Open(dictPath)
+		if err != nil {
+			return nil, fmt.Errorf("读取自定义词典失败: %w", err)
+		}
+		defer f.Close()
+		if err := readDict(f, counts); err != nil {
+			return nil, fmt.Errorf("读取自定义词典失败: %w", err)
+		}
+	}
+
+	var total float64
+	for _, count := range counts {
+		total += float64(count)
+	}
+	zh := &Chinese{freq: make(map[string]float64, len(counts)), minFreq: math.Log(1 / total)}
+	for word, count := range counts {
+		zh.freq[word] = math.Log(float64(count) / total)
+	}
+	return zh, nil
+}
+
+// readDict 读取「词语 词频」格式的词典（词频省略时取 3，超长的词忽略）
+func readDict(r io.Reader, counts map[string]int) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		word := Normalize(fields[0])
+		if len([]rune(word)) > maxWordLen {
+			continue
+		}
+		count := 3
+		if len(fields) > 1 {
+			if c, err := strconv.Atoi(fields[1]); err == nil && c > 0 {
+				count = c
+			}
+		}
+		counts[word] = count
+	}
+	return scanner.Err()
+}
+
+// Cut 切分一段中文（搜索模式）：长词额外输出词典中的二字、三字子词；
+// 连续的单字（多为人名、新词等未登录词，停用词除外）改为输出相邻两字组成的词，提高搜索召回
+func (zh *Chinese) Cut(text string) []string {
+	runes := []rune(text)
+	words := zh.cutExact(runes)
+
+	var terms []string
+	for i := 0; i < len(words); i++ {
+		word := words[i]
+		if len([]rune(word)) > 1 {
+			terms = append(terms, zh.subWords(word)...)
+			terms = append(terms, word)
+			continue
+		}
+
+		// 连续单字
+		j := i
+		for j < len(words) && len([]rune(words[j])) == 1 && !IsStopWord(words[j]) {
+			j++
+		}
+		if j-i <= 1 {
+			terms = append(terms, word)
+			continue
+		}
+		for k := i; k < j-1; k++ {
+			terms = append(terms, words[k]+words[k+1])
+		}
+		i = j - 1
+	}
+	return terms
+}
+
+// cutExact 动态规划求概率最大的切分
+func (zh *Chinese) cutExact(runes []rune) []string {
+	n := len(runes)
+	best := make([]float64, n+1) // best[i]：从 i 开始到结尾的最大对数概率
+	next := make([]int, n+1)     // next[i]：从 i 开始的词的结束位置
+	for i := n - 1; i >= 0; i-- {
+		best[i] = math.Inf(-1)
+		for j := i + 1; j <= n && j-i <= maxWordLen; j++ {
+			p, ok := zh.freq[string(runes[i:j])]
+			if !ok {
+				if j-i > 1 {
+					continue
+				}
+				p = zh.minFreq // 单字总可以成词
+			}
+			if score := p + best[j]; score > best[i] {
+				best[i], next[i] = score, j
+			}
+		}
+	}
+
+	var words []string
+	for i := 0; i < n; i = next[i] {
+		words = append(words, string(runes[i:next[i]]))
+	}
+	return words
+}
+
+// subWords 长词中包含的词典词（二字、三字）
+func (zh *Chinese) subWords(word string) []string {
+	runes := []rune(word)
+	if len(runes) <= 2 {
+		return nil
+	}
+	var words []string
+	for size := 2; size <= 3 && size < len(runes); size++ {
+		for i := 0; i+size <= len(runes); i++ {
+			sub := string(runes[i : i+size])
+			if _, ok := zh.freq[sub]; ok {
+				words = append(words, sub)
+			}
+		}
+	}
+	return words
+}
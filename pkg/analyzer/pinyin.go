@@ -0,0 +1,36 @@
+package analyzer
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/mozillazg/go-pinyin"
+)
+
+// Pinyin 把文本中的汉字转为拼音索引词：全拼和首字母（如「会议纪要」-> huiyijiyao、hyjy），不含汉字时返回 nil
+// 多音字取常用读音，非汉字字符忽略
+func Pinyin(text string) []string {
+	var han []rune
+	for _, r := range text {
+		if unicode.Is(unicode.Han, r) {
+			han = append(han, r)
+		}
+	}
+	if len(han) == 0 {
+		return nil
+	}
+
+	syllables := pinyin.LazyConvert(string(han), nil)
+	if len(syllables) == 0 {
+		return nil
+	}
+	var initials strings.Builder
+	for _, syllable := range syllables {
+		initials.WriteByte(syllable[0])
+	}
+	full := strings.Join(syllables, "")
+	if len(syllables) == 1 {
+		return []string{full}
+	}
+	return []string{full, initials.String()}
+}
@@ -55,6 +55,22 @@ func InitMySQL(conf config.MysqlConfig, debug bool) (*gorm.DB, error) {
 		&model.Note{},
 		&model.Tag{},
 		&model.NoteTag{},
+		&model.Notification{},
+		&model.AuditLog{},
+		&model.Webhook{},
+		&model.WebhookDelivery{},
+		&model.ChecklistItem{},
+		&model.ImportJob{},
+		&model.Attachment{},
+		&model.NoteLink{},
+		&model.SavedSearch{},
+		&model.NoteTerm{},
+		&model.NoteVector{},
+		&model.TagRule{},
+		&model.NoteFingerprint{},
+		&model.NoteEmbedding{},
+		&model.Notebook{},
+		&model.MergedNote{},
 	)
 	if err != nil {
 		zap.S().Errorf("MySQL 数据表迁移失败: %v", err)
@@ -0,0 +1,113 @@
+// Package embedding 文本向量化：把笔记和搜索语句转成向量，按余弦相似度做语义搜索
+// 向量提供方可插拔，内置 local（本地特征哈希，无需模型，结果确定，便于开发和测试）和 http（调用 OpenAI 兼容的 embeddings 接口）
+package embedding
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// Provider 向量提供方
+type Provider interface {
+	// Name 提供方标识（含模型名），标识变化时已保存的向量作废重算
+	Name() string
+	// Embed 批量计算文本向量，返回的向量与 texts 一一对应且已归一化（模为 1）
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// Config 向量提供方配置
+type Config struct {
+	Provider  string        // 提供方名称，为空时使用 local
+	URL       string        // http：接口地址
+	APIKey    string        // http：接口密钥（可选）
+	Model     string        // http：模型名
+	Dimension int           // local：向量维度（默认 256）
+	Timeout   time.Duration // http：请求超时（默认 30 秒）
+}
+
+// Factory 根据配置创建提供方
+type Factory func(conf Config) (Provider, error)
+
+var (
+	factoriesMu sync.RWMutex
+	factories   = map[string]Factory{
+		"local": func(conf Config) (Provider, error) {
+			return NewLocal(conf.Dimension), nil
+		},
+		"http": func(conf Config) (Provider, error) {
+			return NewHTTP(conf)
+		},
+	}
+)
+
+// Register 注册提供方（同名覆盖）
+func Register(name string, factory Factory) {
+	factoriesMu.Lock()
+	defer factoriesMu.Unlock()
+	factories[name] = factory
+}
+
+// New 按名称创建提供方
+func New(conf Config) (Provider, error) {
+	name := conf.Provider
+	if name == "" {
+		name = "local"
+	}
+	factoriesMu.RLock()
+	factory, ok := factories[name]
+	factoriesMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("未知的向量提供方: %s", name)
+	}
+	return factory(conf)
+}
+
+// Normalize 向量归一化（零向量原样返回）
+func Normalize(vector []float32) []float32 {
+	var sum float64
+	for _, v := range vector {
+		sum += float64(v) * float64(v)
+	}
+	if sum == 0 {
+		return vector
+	}
+	norm := float32(math.Sqrt(sum))
+	for i := range vector {
+		vector[i] /= norm
+	}
+	return vector
+}
+
+// Dot 点积（两个归一化向量的点积即余弦相似度，维度不同时返回 0）
+func Dot(a, b []float32) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var sum float32
+	for i := range a {
+		sum += a[i] * b[i]
+	}
+	return float64(sum)
+}
+
+// Encode 向量编码为字节（小端 float32），用于落库
+func Encode(vector []float32) []byte {
+	data := make([]byte, 4*len(vector))
+	for i, v := range vector {
+		binary.LittleEndian.PutUint32(data[4*i:], math.Float32bits(v))
+	}
+	return data
+}
+
+// Decode 从字节解码向量
+func Decode(data []byte) []float32 {
+	vector := make([]float32, len(data)/4)
+	for i := range vector {
+		vector[i] = math.Float32frombits(binary.LittleEndian.Uint32(data[4*i:]))
+	}
+	return vector
+}
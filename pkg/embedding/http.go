@@ -0,0 +1,105 @@
+package embedding
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// HTTP 调用 OpenAI 兼容的 embeddings 接口（POST {"model", "input": [...]}，返回 data[].embedding）
+type HTTP struct {
+	url    string
+	apiKey string
+	model  string
+	client *http.Client
+}
+
+// NewHTTP 创建 HTTP 提供方
+func NewHTTP(conf Config) (*HTTP, error) {
+	if conf.URL == "" {
+		return nil, errors.New("未配置向量接口地址")
+	}
+	if conf.Model == "" {
+		return nil, errors.New("未配置向量模型")
+	}
+	timeout := conf.Timeout
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	return &HTTP{url: conf.URL, apiKey: conf.APIKey, model: conf.Model, client: &http.Client{Timeout: timeout}}, nil
+}
+
+// Name 提供方标识
+func (h *HTTP) Name() string {
+	return "http:" + h.model
+}
+
+type httpRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+type httpResponse struct {
+	Data []struct {
+		Index     int       `json:"index"`
+		Embedding []float32 `json:"embedding"`
+	} `json:"data"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// Embed 批量计算文本向量
+func (h *HTTP) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	body, err := json.Marshal(httpRequest{Model: h.model, Input: texts})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if h.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+h.apiKey)
+	}
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 64<<20))
+	if err != nil {
+		return nil, err
+	}
+
+	var result httpResponse
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("向量接口返回 %d，响应无法解析: %v", resp.StatusCode, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		if result.Error != nil {
+			return nil, fmt.Errorf("向量接口返回 %d: %s", resp.StatusCode, result.Error.Message)
+		}
+		return nil, fmt.Errorf("向量接口返回 %d", resp.StatusCode)
+	}
+	if len(result.Data) != len(texts) {
+		return nil, fmt.Errorf("向量接口返回 %d 条结果，请求 %d 条", len(result.Data), len(texts))
+	}
+
+	vectors := make([][]float32, len(texts))
+	for i, item := range result.Data {
+		index := item.Index
+		if index < 0 || index >= len(texts) {
+			index = i
+		}
+		vectors[index] = Normalize(item.Embedding)
+	}
+	return vectors, nil
+}
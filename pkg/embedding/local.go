@@ -0,0 +1,96 @@
+package embedding
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"unicode"
+
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/analyzer"
+)
+
+// defaultLocalDimension local 向量默认维度
+const defaultLocalDimension = 256
+
+// Local 本地特征哈希向量：英文单词、数字和中文字的一元、二元组合哈希到固定维度（带符号），结果确定且不依赖模型。
+// 只能识别字面相近的文本（如词序不同、部分改写），不能理解同义词，生产环境应使用 http 提供方
+type Local struct {
+	dimension int
+}
+
+// NewLocal 创建本地提供方（dimension 不大于 0 时使用默认维度）
+func NewLocal(dimension int) *Local {
+	if dimension <= 0 {
+		dimension = defaultLocalDimension
+	}
+	return &Local{dimension: dimension}
+}
+
+// Name 提供方标识
+func (l *Local) Name() string {
+	return fmt.Sprintf("local:%d", l.dimension)
+}
+
+// Embed 计算文本向量
+func (l *Local) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vectors[i] = l.embed(text)
+	}
+	return vectors, nil
+}
+
+// embed 计算单条文本的向量
+func (l *Local) embed(text string) []float32 {
+	vector := make([]float32, l.dimension)
+	add := func(feature string, weight float32) {
+		h := fnv.New64a()
+		h.Write([]byte(feature))
+		sum := h.Sum64()
+		index := int(sum % uint64(l.dimension))
+		if sum>>63 == 1 { // 最高位决定符号，减少哈希冲突带来的偏差
+			weight = -weight
+		}
+		vector[index] += weight
+	}
+
+	units := localUnits(analyzer.Normalize(text))
+	for i, unit := range units {
+		if analyzer.IsStopWord(unit) {
+			continue
+		}
+		add(unit, 1)
+		if i+1 < len(units) {
+			add(unit+" "+units[i+1], 0.5)
+		}
+	}
+	return Normalize(vector)
+}
+
+// localUnits 把文本切成基本单元：连续的字母数字为一个单元，中文等其他文字每个字一个单元
+func localUnits(text string) []string {
+	var (
+		units []string
+		word  strings.Builder
+	)
+	flush := func() {
+		if word.Len() > 0 {
+			units = append(units, word.String())
+			word.Reset()
+		}
+	}
+	for _, r := range text {
+		switch {
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			word.WriteRune(r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			flush()
+			units = append(units, string(r))
+		default:
+			flush()
+		}
+	}
+	flush()
+	return units
+}
@@ -10,6 +10,7 @@ const (
 	ServerError   = 500 // 服务器内部错误
 	DuplicateData = 601 // 数据重复（如账号已注册）
 	PasswordError = 602 // 密码错误
+	QuerySyntax   = 603 // 搜索语句语法错误
 )
 
 // 错误码对应提示信息
@@ -32,6 +33,8 @@ func GetMsg(code int) string {
 		return "数据已存在"
 	case PasswordError:
 		return "密码错误"
+	case QuerySyntax:
+		return "搜索语句有误"
 	default:
 		return "未知错误"
 	}
@@ -0,0 +1,20 @@
+package errcode
+
+// Error 携带错误码和详细说明的业务错误（用于需要返回具体原因的场景，如搜索语句语法错误）
+type Error struct {
+	Code   int
+	Detail string
+}
+
+// NewError 创建业务错误
+func NewError(code int, detail string) *Error {
+	return &Error{Code: code, Detail: detail}
+}
+
+// Error 错误提示：默认提示加详细说明
+func (e *Error) Error() string {
+	if e.Detail == "" {
+		return GetMsg(e.Code)
+	}
+	return GetMsg(e.Code) + "：" + e.Detail
+}
@@ -0,0 +1,86 @@
+package frontmatter
+
+import (
+	"bytes"
+	"strings"
+	"time"
+
+	"go.yaml.in/yaml/v3"
+)
+
+const delimiter = "---"
+
+// Meta Markdown 文件头部的 YAML 元数据
+type Meta struct {
+	Title    string    `yaml:"title"`
+	Category string    `yaml:"category,omitempty"`
+	Tags     List      `yaml:"tags,omitempty"`
+	Created  time.Time `yaml:"created,omitempty"`
+	Updated  time.Time `yaml:"updated,omitempty"`
+}
+
+// List 字符串列表，兼容 YAML 序列和逗号分隔的字符串（如 tags: a, b）
+type List []string
+
+// UnmarshalYAML 实现 yaml.Unmarshaler
+func (l *List) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		*l = nil
+		for _, item := range strings.Split(value.Value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				*l = append(*l, item)
+			}
+		}
+		return nil
+	}
+	var items []string
+	if err := value.Decode(&items); err != nil {
+		return err
+	}
+	*l = items
+	return nil
+}
+
+// Marshal 生成带 YAML front matter 的 Markdown 内容
+func Marshal(meta Meta, body string) ([]byte, error) {
+	head, err := yaml.Marshal(meta)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(delimiter + "\n")
+	buf.Write(head)
+	buf.WriteString(delimiter + "\n\n")
+	buf.WriteString(body)
+	if len(body) > 0 && body[len(body)-1] != '\n' {
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes(), nil
+}
+
+// Parse 解析 Markdown 内容开头的 YAML front matter，返回元数据和正文（没有 front matter 时元数据为空）
+func Parse(data []byte) (Meta, string, error) {
+	var meta Meta
+	text := strings.TrimPrefix(string(data), "\ufeff") // 去掉 UTF-8 BOM
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+
+	if !strings.HasPrefix(text, delimiter+"\n") {
+		return meta, text, nil
+	}
+	rest := text[len(delimiter)+1:]
+	end := strings.Index(rest, "\n"+delimiter)
+	if end < 0 {
+		return meta, text, nil
+	}
+	// 结束分隔符必须独占一行
+	after := rest[end+1+len(delimiter):]
+	if after != "" && after[0] != '\n' {
+		return meta, text, nil
+	}
+
+	if err := yaml.Unmarshal([]byte(rest[:end]), &meta); err != nil {
+		return meta, text, err
+	}
+	return meta, strings.TrimLeft(after, "\n"), nil
+}
@@ -0,0 +1,76 @@
+package frontmatter
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRoundTrip(t *testing.T) {
+	created := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	updated := created.Add(48 * time.Hour)
+	tests := []struct {
+		name string
+		meta Meta
+		body string
+		want string // 解析出的正文（Marshal 会为没有结尾换行的正文补一个换行）
+	}{
+		{"完整元数据", Meta{Title: "周报", Category: "工作/周会", Tags: List{"a/b", "c"}, Created: created, Updated: updated}, "# 周报\n\n内容\n", "# 周报\n\n内容\n"},
+		{"补结尾换行", Meta{Title: "t"}, "no newline", "no newline\n"},
+		{"多个结尾换行保留", Meta{Title: "t"}, "body\n\n", "body\n\n"},
+		{"空正文", Meta{Title: "t"}, "", ""},
+		{"正文以分隔符开头", Meta{Title: "t"}, "---\nnot meta\n", "---\nnot meta\n"},
+		{"标题需要转义", Meta{Title: "a: b # c"}, "x\n", "x\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := Marshal(tt.meta, tt.body)
+			if err != nil {
+				t.Fatalf("Marshal error: %v", err)
+			}
+			meta, body, err := Parse(data)
+			if err != nil {
+				t.Fatalf("Parse error: %v\n%s", err, data)
+			}
+			if !reflect.DeepEqual(meta, tt.meta) {
+				t.Errorf("meta = %+v, want %+v", meta, tt.meta)
+			}
+			if body != tt.want {
+				t.Errorf("body = %q, want %q", body, tt.want)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		meta  Meta
+		body  string
+	}{
+		{"没有 front matter", "# title\n", Meta{}, "# title\n"},
+		{"BOM 和 CRLF", "\ufeff---\r\ntitle: t\r\n---\r\n\r\nbody\r\n", Meta{Title: "t"}, "body\n"},
+		{"逗号分隔的标签", "---\ntags: a, b ,, c\n---\nbody", Meta{Tags: List{"a", "b", "c"}}, "body"},
+		{"标签序列", "---\ntags:\n  - a\n  - b\n---\n", Meta{Tags: List{"a", "b"}}, ""},
+		{"未闭合按正文处理", "---\ntitle: t\nbody", Meta{}, "---\ntitle: t\nbody"},
+		{"结束分隔符不独占一行", "---\ntitle: t\n----\nbody", Meta{}, "---\ntitle: t\n----\nbody"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			meta, body, err := Parse([]byte(tt.input))
+			if err != nil {
+				t.Fatalf("Parse error: %v", err)
+			}
+			if !reflect.DeepEqual(meta, tt.meta) || body != tt.body {
+				t.Errorf("Parse = %+v, %q; want %+v, %q", meta, body, tt.meta, tt.body)
+			}
+		})
+	}
+}
+
+func TestParseInvalidYAML(t *testing.T) {
+	if _, _, err := Parse([]byte("---\ntitle: [unclosed\n---\nbody")); err == nil {
+		t.Error("Parse should fail on invalid YAML")
+	}
+}
@@ -0,0 +1,85 @@
+package ical
+
+import (
+	"strings"
+	"time"
+)
+
+// Event 日历事件（对应 VEVENT）
+type Event struct {
+	UID         string    // 全局唯一ID
+	Summary     string    // 标题
+	Description string    // 描述
+	Start       time.Time // 开始时间
+	Remind      time.Time // 提醒时间（零值表示不提醒）
+	Completed   bool      // 是否已完成
+	Updated     time.Time // 最后修改时间
+}
+
+// Calendar iCalendar（RFC 5545）日历
+type Calendar struct {
+	Name   string
+	Events []Event
+}
+
+// String 输出 .ics 文本（CRLF 换行，长行按 75 字节折行）
+func (c *Calendar) String() string {
+	var b strings.Builder
+	writeLine(&b, "BEGIN:VCALENDAR")
+	writeLine(&b, "VERSION:2.0")
+	writeLine(&b, "PRODID:-//MyNoteBook//Notes//ZH")
+	writeLine(&b, "CALSCALE:GREGORIAN")
+	writeLine(&b, "X-WR-CALNAME:"+escape(c.Name))
+	for _, e := range c.Events {
+		writeLine(&b, "BEGIN:VEVENT")
+		writeLine(&b, "UID:"+e.UID)
+		writeLine(&b, "DTSTAMP:"+formatTime(e.Updated))
+		writeLine(&b, "DTSTART:"+formatTime(e.Start))
+		writeLine(&b, "DTEND:"+formatTime(e.Start.Add(30*time.Minute)))
+		writeLine(&b, "SUMMARY:"+escape(e.Summary))
+		if e.Description != "" {
+			writeLine(&b, "DESCRIPTION:"+escape(e.Description))
+		}
+		if !e.Completed {
+			// 已完成的笔记不输出 STATUS（VEVENT 没有「已完成」状态，CANCELLED 会被日历显示为已取消）
+			writeLine(&b, "STATUS:CONFIRMED")
+		}
+		if !e.Remind.IsZero() && !e.Completed {
+			writeLine(&b, "BEGIN:VALARM")
+			writeLine(&b, "ACTION:DISPLAY")
+			writeLine(&b, "DESCRIPTION:"+escape(e.Summary))
+			writeLine(&b, "TRIGGER;VALUE=DATE-TIME:"+formatTime(e.Remind))
+			writeLine(&b, "END:VALARM")
+		}
+		writeLine(&b, "END:VEVENT")
+	}
+	writeLine(&b, "END:VCALENDAR")
+	return b.String()
+}
+
+// formatTime 统一输出 UTC 时间
+func formatTime(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// escape 转义文本中的特殊字符
+func escape(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+	return r.Replace(s)
+}
+
+// writeLine 写入一行，超过 75 字节时折行（不拆开多字节字符）
+func writeLine(b *strings.Builder, line string) {
+	const limit = 75
+	width := 0
+	for _, r := range line {
+		size := len(string(r))
+		if width+size > limit {
+			b.WriteString("\r\n ")
+			width = 1
+		}
+		b.WriteRune(r)
+		width += size
+	}
+	b.WriteString("\r\n")
+}
@@ -0,0 +1,118 @@
+package ical
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestCalendarString(t *testing.T) {
+	start := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		event   Event
+		want    []string
+		notWant []string
+	}{
+		{
+			name:  "未完成带提醒",
+			event: Event{UID: "note-1", Summary: "周会", Start: start, Remind: start.Add(-time.Hour), Updated: start},
+			want: []string{
+				"UID:note-1", "DTSTART:20260301T090000Z", "DTEND:20260301T093000Z", "SUMMARY:周会",
+				"STATUS:CONFIRMED", "BEGIN:VALARM", "TRIGGER;VALUE=DATE-TIME:20260301T080000Z",
+			},
+		},
+		{
+			name:    "已完成不输出状态和提醒",
+			event:   Event{UID: "note-2", Summary: "done", Start: start, Remind: start, Completed: true, Updated: start},
+			want:    []string{"UID:note-2"},
+			notWant: []string{"STATUS:", "BEGIN:VALARM"},
+		},
+		{
+			name:  "转义",
+			event: Event{UID: "note-3", Summary: `a,b;c\d`, Description: "第一行\n第二行", Start: start, Updated: start},
+			want:  []string{`SUMMARY:a\,b\;c\\d`, `DESCRIPTION:第一行\n第二行`},
+		},
+		{
+			name:    "没有描述",
+			event:   Event{UID: "note-4", Summary: "x", Start: start, Updated: start},
+			notWant: []string{"DESCRIPTION:", "BEGIN:VALARM"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := (&Calendar{Name: "笔记", Events: []Event{tt.event}}).String()
+			lines := strings.Split(out, "\r\n")
+			has := func(line string) bool {
+				for _, l := range lines {
+					if strings.HasPrefix(l, line) {
+						return true
+					}
+				}
+				return false
+			}
+			for _, line := range tt.want {
+				if !has(line) {
+					t.Errorf("missing %q in:\n%s", line, out)
+				}
+			}
+			for _, line := range tt.notWant {
+				if has(line) {
+					t.Errorf("unexpected %q in:\n%s", line, out)
+				}
+			}
+		})
+	}
+}
+
+func TestCalendarFrame(t *testing.T) {
+	out := (&Calendar{Name: "笔记"}).String()
+	if !strings.HasPrefix(out, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n") || !strings.HasSuffix(out, "END:VCALENDAR\r\n") {
+		t.Errorf("unexpected calendar frame:\n%s", out)
+	}
+	if strings.Contains(strings.ReplaceAll(out, "\r\n", ""), "\n") {
+		t.Error("lines must end with CRLF")
+	}
+}
+
+func TestWriteLine(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+	}{
+		{"短行", "SUMMARY:short"},
+		{"刚好 75 字节", "SUMMARY:" + strings.Repeat("a", 67)},
+		{"长 ASCII 行", "DESCRIPTION:" + strings.Repeat("abcdefghij", 20)},
+		{"长中文行", "SUMMARY:" + strings.Repeat("中文标题", 30)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b strings.Builder
+			writeLine(&b, tt.line)
+			out := b.String()
+			if !strings.HasSuffix(out, "\r\n") {
+				t.Fatalf("line must end with CRLF: %q", out)
+			}
+			parts := strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n")
+			for i, part := range parts {
+				if len(part) > 75 {
+					t.Errorf("part %d is %d bytes, want <= 75", i, len(part))
+				}
+				if i > 0 && !strings.HasPrefix(part, " ") {
+					t.Errorf("continuation %d must start with a space: %q", i, part)
+				}
+				if !utf8.ValidString(part) {
+					t.Errorf("part %d splits a multi-byte character", i)
+				}
+			}
+			// 展开折行后应还原原文
+			if unfolded := strings.ReplaceAll(strings.TrimSuffix(out, "\r\n"), "\r\n ", ""); unfolded != tt.line {
+				t.Errorf("unfolded = %q, want %q", unfolded, tt.line)
+			}
+			if want := len(tt.line) <= 75; (len(parts) == 1) != want {
+				t.Errorf("folded into %d parts for %d bytes", len(parts), len(tt.line))
+			}
+		})
+	}
+}
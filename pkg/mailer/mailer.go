@@ -0,0 +1,40 @@
+package mailer
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/smtp"
+
+	"github.com/JokerYuan-lang/MyNoteBook/internal/config"
+	"github.com/jordan-wright/email"
+)
+
+// Mailer SMTP 邮件发送器
+type Mailer struct {
+	conf config.SmtpConfig
+}
+
+// NewMailer 创建 Mailer 实例（未开启时返回 nil，调用方据此跳过发信）
+func NewMailer(conf config.SmtpConfig) *Mailer {
+	if !conf.Enable {
+		return nil
+	}
+	return &Mailer{conf: conf}
+}
+
+// Send 发送纯文本邮件
+func (m *Mailer) Send(to, subject, body string) error {
+	e := email.NewEmail()
+	e.From = m.conf.From
+	e.To = []string{to}
+	e.Subject = subject
+	e.Text = []byte(body)
+
+	addr := fmt.Sprintf("%s:%d", m.conf.Host, m.conf.Port)
+	auth := smtp.PlainAuth("", m.conf.UserName, m.conf.Password, m.conf.Host)
+	// 465 端口为 SMTPS，需要直接建立 TLS 连接
+	if m.conf.Port == 465 {
+		return e.SendWithTLS(addr, auth, &tls.Config{ServerName: m.conf.Host})
+	}
+	return e.Send(addr, auth)
+}
@@ -0,0 +1,51 @@
+package mention
+
+import (
+	"regexp"
+	"unicode/utf8"
+)
+
+// 用户名长度（与注册规则一致）
+const (
+	minNameLen = 3
+	maxNameLen = 20
+)
+
+// 匹配 @用户名（允许中文、字母、数字、下划线和短横线；贪婪匹配到名称结束，长度在 Parse 中检查）
+var mentionRegexp = regexp.MustCompile(`(^|[^\w@])@([\p{Han}\w\-]+)`)
+
+// Parse 提取文本中 @ 到的用户名（去重，保持出现顺序）
+func Parse(text string) []string {
+	var (
+		names []string
+		seen  = make(map[string]bool)
+	)
+	for _, m := range mentionRegexp.FindAllStringSubmatch(text, -1) {
+		name := m[2]
+		// 超长的名称不截取前缀匹配，避免误通知其他用户
+		if n := utf8.RuneCountInString(name); n < minNameLen || n > maxNameLen {
+			continue
+		}
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	return names
+}
+
+// Added 返回 newText 中新增的 @ 用户名（oldText 中已存在的不再重复通知）
+func Added(oldText, newText string) []string {
+	old := make(map[string]bool)
+	for _, name := range Parse(oldText) {
+		old[name] = true
+	}
+	var added []string
+	for _, name := range Parse(newText) {
+		if !old[name] {
+			added = append(added, name)
+		}
+	}
+	return added
+}
@@ -0,0 +1,50 @@
+package mention
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{"单个", "请 @alice 看一下", []string{"alice"}},
+		{"开头", "@alice 你好", []string{"alice"}},
+		{"中文和符号", "@张三丰，@bob_1-x。", []string{"张三丰", "bob_1-x"}},
+		{"去重保持顺序", "@bob @alice @bob", []string{"bob", "alice"}},
+		{"邮箱不算", "发到 alice@example.com", nil},
+		{"连续 @ 不算", "@@alice", nil},
+		{"过短", "@ab 和 @abc", []string{"abc"}},
+		{"最长", "@" + strings.Repeat("a", maxNameLen), []string{strings.Repeat("a", maxNameLen)}},
+		{"超长不截取", "@" + strings.Repeat("a", maxNameLen+1), nil},
+		{"中文按字计长度", "@" + strings.Repeat("字", maxNameLen), []string{strings.Repeat("字", maxNameLen)}},
+		{"没有提到", "没有人", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Parse(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAdded(t *testing.T) {
+	tests := []struct {
+		old, new string
+		want     []string
+	}{
+		{"", "@alice @bob", []string{"alice", "bob"}},
+		{"@alice", "@alice @bob", []string{"bob"}},
+		{"@alice @bob", "@bob", nil},
+	}
+	for _, tt := range tests {
+		if got := Added(tt.old, tt.new); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Added(%q, %q) = %q, want %q", tt.old, tt.new, got, tt.want)
+		}
+	}
+}
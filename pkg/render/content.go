@@ -0,0 +1,110 @@
+package render
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	md "github.com/JohannesKaufmann/html-to-markdown"
+	"github.com/JohannesKaufmann/html-to-markdown/plugin"
+	nethtml "golang.org/x/net/html"
+)
+
+// 笔记内容格式
+const (
+	FormatPlain    = "plain"    // 纯文本
+	FormatMarkdown = "markdown" // Markdown（默认）
+	FormatHTML     = "html"     // HTML（保存前按白名单过滤）
+)
+
+// blockElements 提取纯文本时需要换行的块级元素
+var blockElements = map[string]bool{
+	"p": true, "div": true, "br": true, "hr": true, "li": true, "ul": true, "ol": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"pre": true, "blockquote": true, "table": true, "tr": true, "td": true, "th": true,
+}
+
+// ValidFormat 判断内容格式是否受支持（空值视为 Markdown）
+func ValidFormat(format string) bool {
+	switch format {
+	case "", FormatPlain, FormatMarkdown, FormatHTML:
+		return true
+	}
+	return false
+}
+
+// Content 按内容格式渲染为经过白名单过滤的 HTML
+func Content(format, content string) (string, error) {
+	switch format {
+	case FormatPlain:
+		return plainHTML(content), nil
+	case FormatHTML:
+		return Sanitize(content), nil
+	case "", FormatMarkdown:
+		return HTML(content)
+	}
+	return "", fmt.Errorf("不支持的内容格式: %s", format)
+}
+
+// ToMarkdown 把任意格式的内容转换为 Markdown（用于只支持 Markdown 的输出，如 PDF）
+func ToMarkdown(format, content string) (string, error) {
+	if format == "" || format == FormatMarkdown {
+		return content, nil
+	}
+	fragment, err := Content(format, content)
+	if err != nil {
+		return "", err
+	}
+	converter := md.NewConverter("", true, nil)
+	converter.Use(plugin.GitHubFlavored())
+	return converter.ConvertString(fragment)
+}
+
+// PlainText 提取 HTML 中的纯文本（块级元素之间换行，连续空白合并）
+func PlainText(fragment string) string {
+	doc, err := nethtml.Parse(strings.NewReader(fragment))
+	if err != nil {
+		return ""
+	}
+	var sb strings.Builder
+	var walk func(n *nethtml.Node)
+	walk = func(n *nethtml.Node) {
+		if n.Type == nethtml.TextNode {
+			sb.WriteString(n.Data)
+		}
+		block := n.Type == nethtml.ElementNode && blockElements[n.Data]
+		if block {
+			sb.WriteByte('\n')
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+		if block {
+			sb.WriteByte('\n')
+		}
+	}
+	walk(doc)
+
+	var lines []string
+	for _, line := range strings.Split(sb.String(), "\n") {
+		if line = strings.Join(strings.Fields(line), " "); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// plainHTML 把纯文本转换为 HTML：空行分段，段内换行保留
+func plainHTML(content string) string {
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	var sb strings.Builder
+	for _, para := range strings.Split(content, "\n\n") {
+		if para = strings.Trim(para, "\n"); para == "" {
+			continue
+		}
+		sb.WriteString("<p>")
+		sb.WriteString(strings.ReplaceAll(html.EscapeString(para), "\n", "<br>\n"))
+		sb.WriteString("</p>\n")
+	}
+	return sb.String()
+}
@@ -0,0 +1,54 @@
+// Package render 把笔记内容渲染为 HTML（经过白名单过滤）和 PDF
+package render
+
+import (
+	"bytes"
+	"regexp"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	highlighting "github.com/yuin/goldmark-highlighting/v2"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+)
+
+// markdown Markdown 解析器：GFM（表格、任务列表、删除线、自动链接）+ 脚注 + 代码高亮（内联样式）
+var markdown = goldmark.New(
+	goldmark.WithExtensions(
+		extension.GFM,
+		extension.Footnote,
+		highlighting.NewHighlighting(highlighting.WithStyle("github")),
+	),
+	goldmark.WithParserOptions(parser.WithAutoHeadingID()),
+)
+
+// policy HTML 白名单：在 UGC 策略基础上放开代码高亮样式、任务列表复选框、脚注锚点和站内链接
+var policy = newPolicy()
+
+func newPolicy() *bluemonday.Policy {
+	p := bluemonday.UGCPolicy()
+	p.AllowURLSchemes("http", "https", "mailto", "note", "attachment")
+	p.AllowAttrs("id").Matching(regexp.MustCompile(`^[\p{L}\p{N}_:-]+$`)).Globally()
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^[a-zA-Z0-9_ -]+$`)).Globally()
+	p.AllowAttrs("style").OnElements("pre", "span", "th", "td")
+	p.AllowStyles("color", "background-color", "font-weight", "font-style", "text-decoration").OnElements("pre", "span")
+	p.AllowStyles("text-align").OnElements("th", "td")
+	p.AllowAttrs("type").Matching(regexp.MustCompile(`^checkbox$`)).OnElements("input")
+	p.AllowAttrs("checked", "disabled").OnElements("input")
+	p.AllowAttrs("role").Matching(regexp.MustCompile(`^doc-[a-z]+$`)).OnElements("a", "div", "section")
+	return p
+}
+
+// HTML 把 Markdown 渲染为经过白名单过滤的 HTML 片段
+func HTML(content string) (string, error) {
+	var buf bytes.Buffer
+	if err := markdown.Convert([]byte(content), &buf); err != nil {
+		return "", err
+	}
+	return policy.Sanitize(buf.String()), nil
+}
+
+// Sanitize 按白名单过滤 HTML（去掉脚本、事件属性、危险链接等）
+func Sanitize(html string) string {
+	return policy.Sanitize(html)
+}
@@ -0,0 +1,499 @@
+package render
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"  // 注册 GIF 解码
+	_ "image/jpeg" // 注册 JPEG 解码
+	_ "image/png"  // 注册 PNG 解码
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/go-pdf/fpdf"
+	"github.com/yuin/goldmark/ast"
+	east "github.com/yuin/goldmark/extension/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+// PDF 排版参数（单位 mm / pt）
+const (
+	pdfBodySize   = 11.0 // 正文字号
+	pdfCodeSize   = 9.0  // 代码字号
+	pdfLineHeight = 6.0  // 正文行高
+	pdfCodeHeight = 4.5  // 代码行高
+	pdfIndent     = 6.0  // 列表/引用缩进
+)
+
+var pdfHeadingSizes = [...]float64{20, 17, 15, 13, 12, 11}
+
+// ErrFontRequired 文档包含内置字体无法显示的字符（如中文），但没有提供字体
+var ErrFontRequired = errors.New("render: font required for non-latin text")
+
+// PDFDocument 一篇待输出的文档（每篇从新的一页开始）
+type PDFDocument struct {
+	Title    string
+	Subtitle string // 标题下方的说明（如分类、更新时间）
+	Format   string // 正文格式（plain/markdown/html，非 Markdown 会先转换为 Markdown）
+	Content  string
+}
+
+// ImageLoader 按链接地址读取图片内容（ok 为 false 时只输出替代文字）
+type ImageLoader func(src string) (data []byte, ok bool)
+
+// PDFOptions PDF 输出选项
+type PDFOptions struct {
+	Font  []byte      // TrueType 字体内容（输出中文必须提供；为空时使用内置字体，只支持西文，遇到其他字符返回 ErrFontRequired）
+	Image ImageLoader // 图片读取（为空时只输出替代文字）
+}
+
+// WritePDF 把多篇文档排版为一个 PDF 写入 w（纯 Go 实现，不依赖外部程序）
+func WritePDF(w io.Writer, docs []PDFDocument, opts PDFOptions) error {
+	if len(opts.Font) == 0 {
+		// 内置字体只能显示 Latin-1，其余字符会变成乱码，直接报错
+		for _, doc := range docs {
+			if !isLatin1(doc.Title) || !isLatin1(doc.Subtitle) || !isLatin1(doc.Content) {
+				return ErrFontRequired
+			}
+		}
+	}
+
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pw := &pdfWriter{pdf: pdf, opts: opts, images: make(map[string]bool)}
+	if len(opts.Font) > 0 {
+		pw.family, pw.codeFamily = "note", "note"
+		for _, style := range []string{"", "B", "I", "BI"} {
+			pdf.AddUTF8FontFromBytes(pw.family, style, opts.Font)
+		}
+		pw.tr = func(s string) string { return s }
+	} else {
+		pw.family, pw.codeFamily = "Helvetica", "Courier"
+		pw.tr = pdf.UnicodeTranslatorFromDescriptor("")
+	}
+
+	pdf.SetMargins(20, 20, 20)
+	pdf.SetAutoPageBreak(true, 20)
+	pdf.SetCreator("MyNoteBook", true)
+	if len(docs) > 0 {
+		pdf.SetTitle(docs[0].Title, true)
+	}
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-15)
+		pdf.SetFont(pw.family, "", 8)
+		pdf.SetTextColor(128, 128, 128)
+		pdf.CellFormat(0, 10, fmt.Sprintf("%d", pdf.PageNo()), "", 0, "C", false, 0, "")
+	})
+
+	for _, doc := range docs {
+		pw.writeDocument(doc)
+		if pdf.Err() {
+			return pdf.Error()
+		}
+	}
+	return pdf.Output(w)
+}
+
+// pdfWriter 遍历 Markdown 语法树输出 PDF，记录当前的字体状态
+type pdfWriter struct {
+	pdf        *fpdf.Fpdf
+	opts       PDFOptions
+	tr         func(string) string // 文本编码转换（内置字体需要转为 cp1252）
+	family     string
+	codeFamily string
+	images     map[string]bool // 已注册的图片
+
+	source     []byte
+	size       float64
+	lineHeight float64
+	bold       bool
+	italic     bool
+	code       bool
+	link       string
+}
+
+// writeDocument 输出一篇文档：标题、说明、分隔线和正文
+func (w *pdfWriter) writeDocument(doc PDFDocument) {
+	pdf := w.pdf
+	pdf.AddPage()
+
+	w.setFont(pdfHeadingSizes[0], true, false, false)
+	pdf.SetTextColor(0, 0, 0)
+	pdf.MultiCell(0, 9, w.tr(doc.Title), "", "L", false)
+	if doc.Subtitle != "" {
+		w.setFont(9, false, false, false)
+		pdf.SetTextColor(128, 128, 128)
+		pdf.MultiCell(0, 5, w.tr(doc.Subtitle), "", "L", false)
+	}
+	w.rule()
+
+	content, err := ToMarkdown(doc.Format, doc.Content)
+	if err != nil {
+		content = doc.Content
+	}
+	w.source = []byte(content)
+	root := markdown.Parser().Parse(text.NewReader(w.source))
+	w.setFont(pdfBodySize, false, false, false)
+	w.lineHeight = pdfLineHeight
+	w.writeBlocks(root)
+}
+
+// writeBlocks 依次输出子块
+func (w *pdfWriter) writeBlocks(parent ast.Node) {
+	for n := parent.FirstChild(); n != nil; n = n.NextSibling() {
+		w.writeBlock(n)
+	}
+}
+
+// writeBlock 输出一个块级元素
+func (w *pdfWriter) writeBlock(n ast.Node) {
+	pdf := w.pdf
+	pdf.SetTextColor(0, 0, 0)
+	switch n := n.(type) {
+	case *ast.Heading:
+		level := n.Level
+		if level > len(pdfHeadingSizes) {
+			level = len(pdfHeadingSizes)
+		}
+		pdf.Ln(2)
+		w.setFont(pdfHeadingSizes[level-1], true, false, false)
+		w.lineHeight = pdfHeadingSizes[level-1] * 0.5
+		w.writeInlines(n)
+		pdf.Ln(w.lineHeight + 2)
+		w.setFont(pdfBodySize, false, false, false)
+		w.lineHeight = pdfLineHeight
+
+	case *ast.Paragraph:
+		w.writeInlines(n)
+		pdf.Ln(w.lineHeight + 2)
+
+	case *ast.TextBlock:
+		w.writeInlines(n)
+		pdf.Ln(w.lineHeight)
+
+	case *ast.List:
+		w.writeList(n)
+		pdf.Ln(1)
+
+	case *ast.Blockquote:
+		left, _, _, _ := pdf.GetMargins()
+		pdf.SetLeftMargin(left + pdfIndent)
+		pdf.SetX(left + pdfIndent)
+		w.writeBlocks(n)
+		pdf.SetLeftMargin(left)
+		pdf.SetX(left)
+
+	case *ast.FencedCodeBlock, *ast.CodeBlock:
+		var buf bytes.Buffer
+		lines := n.Lines()
+		for i := 0; i < lines.Len(); i++ {
+			line := lines.At(i)
+			buf.Write(line.Value(w.source))
+		}
+		w.setFont(pdfCodeSize, false, false, true)
+		pdf.SetFillColor(245, 245, 245)
+		pdf.MultiCell(0, pdfCodeHeight, w.tr(strings.TrimRight(buf.String(), "\n")), "", "L", true)
+		pdf.Ln(2)
+		w.setFont(pdfBodySize, false, false, false)
+
+	case *ast.ThematicBreak:
+		w.rule()
+
+	case *ast.HTMLBlock:
+		// 原始 HTML 不输出
+
+	case *east.Table:
+		w.writeTable(n)
+
+	case *east.FootnoteList:
+		w.rule()
+		w.setFont(pdfCodeSize, false, false, false)
+		for item := n.FirstChild(); item != nil; item = item.NextSibling() {
+			if fn, ok := item.(*east.Footnote); ok {
+				pdf.Write(pdfCodeHeight, fmt.Sprintf("[%d] ", fn.Index))
+			}
+			w.lineHeight = pdfCodeHeight
+			w.writeBlocks(item)
+		}
+		w.setFont(pdfBodySize, false, false, false)
+		w.lineHeight = pdfLineHeight
+
+	default:
+		w.writeBlocks(n)
+	}
+}
+
+// writeList 输出列表：项目符号或序号，换行后与文字对齐
+func (w *pdfWriter) writeList(list *ast.List) {
+	pdf := w.pdf
+	left, _, _, _ := pdf.GetMargins()
+	index := list.Start
+	for item := list.FirstChild(); item != nil; item = item.NextSibling() {
+		marker := "- "
+		if list.IsOrdered() {
+			marker = fmt.Sprintf("%d. ", index)
+			index++
+		}
+		pdf.SetLeftMargin(left)
+		pdf.SetX(left)
+		pdf.Write(w.lineHeight, marker)
+		pdf.SetLeftMargin(left + pdf.GetStringWidth(marker))
+		w.writeBlocks(item)
+	}
+	pdf.SetLeftMargin(left)
+	pdf.SetX(left)
+}
+
+// writeTable 输出表格：等宽列，行高取本行最多的折行数
+func (w *pdfWriter) writeTable(table *east.Table) {
+	pdf := w.pdf
+	left, _, right, bottom := pdf.GetMargins()
+	pageWidth, pageHeight := pdf.GetPageSize()
+
+	var rows [][]*east.TableCell
+	for row := table.FirstChild(); row != nil; row = row.NextSibling() {
+		var cells []*east.TableCell
+		for cell := row.FirstChild(); cell != nil; cell = cell.NextSibling() {
+			if c, ok := cell.(*east.TableCell); ok {
+				cells = append(cells, c)
+			}
+		}
+		rows = append(rows, cells)
+	}
+	if len(rows) == 0 || len(rows[0]) == 0 {
+		return
+	}
+	colWidth := (pageWidth - left - right) / float64(len(rows[0]))
+
+	pdf.SetFillColor(240, 240, 240)
+	for i, cells := range rows {
+		header := i == 0
+		w.setFont(pdfCodeSize+1, header, false, false)
+
+		texts := make([]string, len(cells))
+		lines := 1
+		for j, cell := range cells {
+			texts[j] = w.tr(w.plainText(cell))
+			if n := len(pdf.SplitText(texts[j], colWidth-2)); n > lines {
+				lines = n
+			}
+		}
+		height := float64(lines) * pdfCodeHeight
+		if pdf.GetY()+height > pageHeight-bottom {
+			pdf.AddPage()
+		}
+
+		y := pdf.GetY()
+		for j, cell := range cells {
+			x := left + float64(j)*colWidth
+			style := "D"
+			if header {
+				style = "FD"
+			}
+			pdf.Rect(x, y, colWidth, height, style)
+			pdf.SetXY(x, y)
+			pdf.MultiCell(colWidth, pdfCodeHeight, texts[j], "", cellAlign(cell.Alignment), false)
+		}
+		pdf.SetXY(left, y+height)
+	}
+	pdf.Ln(3)
+	w.setFont(pdfBodySize, false, false, false)
+}
+
+// writeInlines 输出行内元素
+func (w *pdfWriter) writeInlines(parent ast.Node) {
+	for n := parent.FirstChild(); n != nil; n = n.NextSibling() {
+		w.writeInline(n)
+	}
+}
+
+// writeInline 输出一个行内元素（粗体/斜体/代码/链接通过切换字体状态实现）
+func (w *pdfWriter) writeInline(n ast.Node) {
+	switch n := n.(type) {
+	case *ast.Text:
+		w.write(string(n.Segment.Value(w.source)))
+		if n.HardLineBreak() {
+			w.pdf.Ln(w.lineHeight)
+		} else if n.SoftLineBreak() {
+			w.write(" ")
+		}
+
+	case *ast.String:
+		w.write(string(n.Value))
+
+	case *ast.CodeSpan:
+		code := w.code
+		w.setFont(w.size, w.bold, w.italic, true)
+		w.writeInlines(n)
+		w.setFont(w.size, w.bold, w.italic, code)
+
+	case *ast.Emphasis:
+		bold, italic := w.bold, w.italic
+		if n.Level >= 2 {
+			w.setFont(w.size, true, italic, w.code)
+		} else {
+			w.setFont(w.size, bold, true, w.code)
+		}
+		w.writeInlines(n)
+		w.setFont(w.size, bold, italic, w.code)
+
+	case *ast.Link:
+		w.withLink(string(n.Destination), func() { w.writeInlines(n) })
+
+	case *ast.AutoLink:
+		url := string(n.URL(w.source))
+		w.withLink(url, func() { w.write(string(n.Label(w.source))) })
+
+	case *ast.Image:
+		w.writeImage(n)
+
+	case *east.TaskCheckBox:
+		if n.IsChecked {
+			w.write("[x] ")
+		} else {
+			w.write("[ ] ")
+		}
+
+	case *east.FootnoteLink:
+		w.write(fmt.Sprintf("[%d]", n.Index))
+
+	case *east.FootnoteBacklink, *ast.RawHTML:
+		// 脚注回链和原始 HTML 不输出
+
+	default:
+		w.writeInlines(n)
+	}
+}
+
+// writeImage 输出图片（按页面宽度缩放；读取失败或格式不支持时输出替代文字）
+func (w *pdfWriter) writeImage(n *ast.Image) {
+	src := string(n.Destination)
+	alt := w.plainText(n)
+	if w.opts.Image == nil {
+		w.write(alt)
+		return
+	}
+
+	pdf := w.pdf
+	if !w.images[src] {
+		data, ok := w.opts.Image(src)
+		if !ok {
+			w.write(alt)
+			return
+		}
+		// 先校验图片，避免损坏的图片让整个 PDF 出错
+		_, format, err := image.DecodeConfig(bytes.NewReader(data))
+		if err != nil {
+			w.write(alt)
+			return
+		}
+		pdf.RegisterImageOptionsReader(src, fpdf.ImageOptions{ImageType: format}, bytes.NewReader(data))
+		if pdf.Err() {
+			return
+		}
+		w.images[src] = true
+	}
+
+	info := pdf.GetImageInfo(src)
+	left, _, right, _ := pdf.GetMargins()
+	pageWidth, _ := pdf.GetPageSize()
+	width, height := info.Extent()
+	if maxWidth := pageWidth - left - right; width > maxWidth {
+		width, height = maxWidth, height*maxWidth/width
+	}
+	pdf.Ln(w.lineHeight)
+	pdf.ImageOptions(src, left, -1, width, height, true, fpdf.ImageOptions{}, 0, "")
+}
+
+// withLink 以链接样式输出
+func (w *pdfWriter) withLink(url string, fn func()) {
+	w.link = url
+	w.pdf.SetTextColor(30, 90, 200)
+	fn()
+	w.pdf.SetTextColor(0, 0, 0)
+	w.link = ""
+}
+
+// write 按当前字体状态输出文字
+func (w *pdfWriter) write(s string) {
+	if s == "" {
+		return
+	}
+	if w.link != "" && strings.Contains(w.link, "://") {
+		w.pdf.WriteLinkString(w.lineHeight, w.tr(s), w.link)
+		return
+	}
+	w.pdf.Write(w.lineHeight, w.tr(s))
+}
+
+// setFont 切换字号/粗体/斜体/代码字体
+func (w *pdfWriter) setFont(size float64, bold, italic, code bool) {
+	w.size, w.bold, w.italic, w.code = size, bold, italic, code
+	style := ""
+	if bold {
+		style += "B"
+	}
+	if italic {
+		style += "I"
+	}
+	family := w.family
+	if code {
+		family = w.codeFamily
+	}
+	w.pdf.SetFont(family, style, size)
+}
+
+// rule 输出一条水平分隔线
+func (w *pdfWriter) rule() {
+	pdf := w.pdf
+	left, _, right, _ := pdf.GetMargins()
+	pageWidth, _ := pdf.GetPageSize()
+	pdf.Ln(2)
+	pdf.SetDrawColor(200, 200, 200)
+	pdf.Line(left, pdf.GetY(), pageWidth-right, pdf.GetY())
+	pdf.SetDrawColor(0, 0, 0)
+	pdf.Ln(4)
+}
+
+// plainText 提取节点下的纯文本
+func (w *pdfWriter) plainText(n ast.Node) string {
+	var sb strings.Builder
+	_ = ast.Walk(n, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch node := node.(type) {
+		case *ast.Text:
+			sb.Write(node.Segment.Value(w.source))
+			if node.SoftLineBreak() || node.HardLineBreak() {
+				sb.WriteByte(' ')
+			}
+		case *ast.String:
+			sb.Write(node.Value)
+		}
+		return ast.WalkContinue, nil
+	})
+	return sb.String()
+}
+
+// isLatin1 s 是否只包含内置字体能显示的字符
+func isLatin1(s string) bool {
+	for _, r := range s {
+		if r > 0xFF || r == utf8.RuneError {
+			return false
+		}
+	}
+	return true
+}
+
+// cellAlign 表格单元格对齐方式
+func cellAlign(align east.Alignment) string {
+	switch align {
+	case east.AlignCenter:
+		return "C"
+	case east.AlignRight:
+		return "R"
+	}
+	return "L"
+}
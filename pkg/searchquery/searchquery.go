@@ -0,0 +1,307 @@
+// Package searchquery 解析结构化搜索语句，例如：
+//
+//	tag:work category:会议 created:>2026-01-01 -tag:draft "exact phrase"
+//
+// 语句由空格分隔的条件组成，条件之间为「且」关系，用 OR 连接的相邻条件为「或」关系；
+// 条件前加 - 表示取反。支持的字段：tag、category、title、created、updated、is（pinned/favorite/archived），
+// 其余文字匹配标题和正文
+package searchquery
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// 支持的字段
+const (
+	FieldText     = ""         // 关键词（匹配标题和正文）
+	FieldTag      = "tag"      // 标签名
+	FieldCategory = "category" // 分类
+	FieldTitle    = "title"    // 标题包含
+	FieldCreated  = "created"  // 创建日期
+	FieldUpdated  = "updated"  // 更新日期
+	FieldIs       = "is"       // 笔记状态（见 States）
+)
+
+// States is: 支持的笔记状态
+var States = map[string]bool{
+	"pinned":   true, // 置顶
+	"favorite": true, // 收藏
+	"archived": true, // 归档
+}
+
+var fields = map[string]bool{
+	FieldTag:      true,
+	FieldCategory: true,
+	FieldTitle:    true,
+	FieldCreated:  true,
+	FieldUpdated:  true,
+	FieldIs:       true,
+}
+
+// Term 一个搜索条件
+type Term struct {
+	Field  string
+	Value  string
+	Negate bool
+	Phrase bool // 双引号短语（按原文连续匹配，不分词）
+	// 日期字段解析为左闭右开区间 [Start, End)，为空表示不限
+	Start *time.Time
+	End   *time.Time
+}
+
+// Clause 用 OR 连接的一组条件（满足任意一个即可）
+type Clause []Term
+
+// Query 搜索语句（各组条件同时满足）
+type Query struct {
+	Clauses []Clause
+}
+
+// Error 语法错误（Pos 为出错位置，从 1 开始按字符计）
+type Error struct {
+	Pos int
+	Msg string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("第 %d 个字符附近：%s", e.Pos, e.Msg)
+}
+
+// Parse 解析搜索语句（空语句返回不含条件的 Query）
+func Parse(text string) (*Query, error) {
+	p := &parser{input: []rune(text)}
+	return p.parse()
+}
+
+// parser 按字符扫描的解析器
+type parser struct {
+	input []rune
+	pos   int
+}
+
+func (p *parser) parse() (*Query, error) {
+	query := &Query{}
+	var (
+		clause  Clause
+		pending bool // 上一个词是 OR，等待下一个条件
+		orPos   int
+	)
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.input) {
+			break
+		}
+
+		start := p.pos
+		if p.word() == "OR" {
+			if len(clause) == 0 || pending {
+				return nil, p.errorAt(start, "OR 前缺少搜索条件")
+			}
+			pending, orPos = true, start
+			continue
+		}
+		p.pos = start
+
+		term, err := p.term()
+		if err != nil {
+			return nil, err
+		}
+		if !pending && len(clause) > 0 {
+			query.Clauses = append(query.Clauses, clause)
+			clause = nil
+		}
+		clause = append(clause, term)
+		pending = false
+	}
+	if pending {
+		return nil, p.errorAt(orPos, "OR 后缺少搜索条件")
+	}
+	if len(clause) > 0 {
+		query.Clauses = append(query.Clauses, clause)
+	}
+	return query, nil
+}
+
+// term 解析一个条件：[-] ( "短语" | 字段:值 | 关键词 )
+func (p *parser) term() (Term, error) {
+	var term Term
+	if p.peek() == '-' && p.pos+1 < len(p.input) && !unicode.IsSpace(p.input[p.pos+1]) {
+		term.Negate = true
+		p.pos++
+	}
+
+	start := p.pos
+	if p.peek() == '"' {
+		value, err := p.quoted()
+		if err != nil {
+			return term, err
+		}
+		if value == "" {
+			return term, p.errorAt(start, "短语不能为空")
+		}
+		term.Value = value
+		term.Phrase = true
+		return term, nil
+	}
+
+	// 字段名:值（未知字段按普通关键词处理，如网址）
+	name := p.fieldName()
+	if name != "" && p.peek() == ':' && fields[strings.ToLower(name)] {
+		p.pos++
+		term.Field = strings.ToLower(name)
+		valueStart := p.pos
+		value, err := p.value()
+		if err != nil {
+			return term, err
+		}
+		if value == "" {
+			return term, p.errorAt(valueStart, fmt.Sprintf("%s: 后缺少搜索值", term.Field))
+		}
+		term.Value = value
+		if term.Field == FieldCreated || term.Field == FieldUpdated {
+			if term.Start, term.End, err = parseDateRange(value); err != nil {
+				return term, p.errorAt(valueStart, err.Error())
+			}
+		}
+		if term.Field == FieldIs {
+			term.Value = strings.ToLower(value)
+			if !States[term.Value] {
+				return term, p.errorAt(valueStart, fmt.Sprintf("不支持的状态 %q（可选 pinned、favorite、archived）", value))
+			}
+		}
+		return term, nil
+	}
+
+	p.pos = start
+	term.Value = p.word()
+	return term, nil
+}
+
+// value 读取字段值（可以用双引号包含空格）
+func (p *parser) value() (string, error) {
+	if p.peek() == '"' {
+		return p.quoted()
+	}
+	return p.word(), nil
+}
+
+// quoted 读取双引号中的内容
+func (p *parser) quoted() (string, error) {
+	start := p.pos
+	p.pos++ // 跳过开头的引号
+	var sb strings.Builder
+	for p.pos < len(p.input) {
+		r := p.input[p.pos]
+		p.pos++
+		if r == '"' {
+			return sb.String(), nil
+		}
+		sb.WriteRune(r)
+	}
+	return "", p.errorAt(start, "引号未闭合")
+}
+
+// word 读取到下一个空白字符为止
+func (p *parser) word() string {
+	start := p.pos
+	for p.pos < len(p.input) && !unicode.IsSpace(p.input[p.pos]) {
+		p.pos++
+	}
+	return string(p.input[start:p.pos])
+}
+
+// fieldName 读取字段名（字母）
+func (p *parser) fieldName() string {
+	start := p.pos
+	for p.pos < len(p.input) && p.input[p.pos] < unicode.MaxASCII && unicode.IsLetter(p.input[p.pos]) {
+		p.pos++
+	}
+	return string(p.input[start:p.pos])
+}
+
+func (p *parser) peek() rune {
+	if p.pos >= len(p.input) {
+		return 0
+	}
+	return p.input[p.pos]
+}
+
+func (p *parser) skipSpace() {
+	for p.pos < len(p.input) && unicode.IsSpace(p.input[p.pos]) {
+		p.pos++
+	}
+}
+
+func (p *parser) errorAt(pos int, msg string) *Error {
+	return &Error{Pos: pos + 1, Msg: msg}
+}
+
+// parseDateRange 把日期条件解析为 [start, end) 区间
+// 支持 2026、2026-01、2026-01-02 三种精度，前缀 > >= < <= =，以及 2026-01-01..2026-01-31 范围
+func parseDateRange(value string) (start, end *time.Time, err error) {
+	if from, to, ok := strings.Cut(value, ".."); ok {
+		if from != "" {
+			if start, _, err = parseDate(from); err != nil {
+				return nil, nil, err
+			}
+		}
+		if to != "" {
+			if _, end, err = parseDate(to); err != nil {
+				return nil, nil, err
+			}
+		}
+		if start == nil && end == nil {
+			return nil, nil, fmt.Errorf("日期范围不能为空")
+		}
+		return start, end, nil
+	}
+
+	for _, op := range []string{">=", "<=", ">", "<", "="} {
+		if !strings.HasPrefix(value, op) {
+			continue
+		}
+		first, next, err := parseDate(strings.TrimPrefix(value, op))
+		if err != nil {
+			return nil, nil, err
+		}
+		switch op {
+		case ">=":
+			return first, nil, nil
+		case ">":
+			return next, nil, nil
+		case "<=":
+			return nil, next, nil
+		case "<":
+			return nil, first, nil
+		default:
+			return first, next, nil
+		}
+	}
+	return parseDate(value)
+}
+
+// parseDate 解析日期（本地时区），返回该日期（年/月/日）的起始时间和下一段的起始时间
+func parseDate(value string) (first, next *time.Time, err error) {
+	layouts := []struct {
+		layout string
+		years  int
+		months int
+		days   int
+	}{
+		{"2006-01-02", 0, 0, 1},
+		{"2006-01", 0, 1, 0},
+		{"2006", 1, 0, 0},
+	}
+	for _, l := range layouts {
+		t, err := time.ParseInLocation(l.layout, value, time.Local)
+		if err != nil {
+			continue
+		}
+		n := t.AddDate(l.years, l.months, l.days)
+		return &t, &n, nil
+	}
+	return nil, nil, fmt.Errorf("日期格式错误：%q（应为 2026、2026-01 或 2026-01-02）", value)
+}
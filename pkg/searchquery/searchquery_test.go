@@ -0,0 +1,138 @@
+package searchquery
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []Clause
+	}{
+		{"空语句", "  ", nil},
+		{"关键词", "golang 笔记", []Clause{{{Value: "golang"}}, {{Value: "笔记"}}}},
+		{"字段", "tag:work category:会议/周会 title:周报", []Clause{
+			{{Field: FieldTag, Value: "work"}},
+			{{Field: FieldCategory, Value: "会议/周会"}},
+			{{Field: FieldTitle, Value: "周报"}},
+		}},
+		{"字段名不区分大小写", "TAG:work", []Clause{{{Field: FieldTag, Value: "work"}}}},
+		{"取反", "-tag:draft -旧", []Clause{
+			{{Field: FieldTag, Value: "draft", Negate: true}},
+			{{Value: "旧", Negate: true}},
+		}},
+		{"单独的短横线是关键词", "a - b", []Clause{{{Value: "a"}}, {{Value: "-"}}, {{Value: "b"}}}},
+		{"短语", `"exact phrase" -"draft copy"`, []Clause{
+			{{Value: "exact phrase", Phrase: true}},
+			{{Value: "draft copy", Phrase: true, Negate: true}},
+		}},
+		{"带引号的字段值", `category:"工作 笔记"`, []Clause{{{Field: FieldCategory, Value: "工作 笔记"}}}},
+		{"OR", "tag:a OR tag:b c", []Clause{
+			{{Field: FieldTag, Value: "a"}, {Field: FieldTag, Value: "b"}},
+			{{Value: "c"}},
+		}},
+		{"未知字段按关键词处理", "https://example.com", []Clause{{{Value: "https://example.com"}}}},
+		{"状态", "is:pinned -is:Archived", []Clause{
+			{{Field: FieldIs, Value: "pinned"}},
+			{{Field: FieldIs, Value: "archived", Negate: true}},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Parse(%q) error: %v", tt.input, err)
+			}
+			if !reflect.DeepEqual(query.Clauses, tt.want) {
+				t.Errorf("Parse(%q) = %+v, want %+v", tt.input, query.Clauses, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		pos   int
+	}{
+		{"OR 开头", "OR tag:a", 1},
+		{"连续 OR", "a OR OR b", 6},
+		{"OR 结尾", "a OR", 3},
+		{"引号未闭合", `a "b c`, 3},
+		{"空短语", `""`, 1},
+		{"缺少字段值", "tag: a", 5},
+		{"日期格式错误", "created:2026/01/01", 9},
+		{"空日期范围", "updated:..", 9},
+		{"未知状态", "is:deleted", 4},
+		{"状态缺少值", "is:", 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.input)
+			var syntaxErr *Error
+			if !errors.As(err, &syntaxErr) {
+				t.Fatalf("Parse(%q) error = %v, want *Error", tt.input, err)
+			}
+			if syntaxErr.Pos != tt.pos {
+				t.Errorf("Parse(%q) error at %d, want %d (%v)", tt.input, syntaxErr.Pos, tt.pos, err)
+			}
+		})
+	}
+}
+
+func TestParseDateRange(t *testing.T) {
+	day := func(y int, m time.Month, d int) *time.Time {
+		t := time.Date(y, m, d, 0, 0, 0, 0, time.Local)
+		return &t
+	}
+	tests := []struct {
+		value      string
+		start, end *time.Time
+	}{
+		{"2026", day(2026, 1, 1), day(2027, 1, 1)},
+		{"2026-02", day(2026, 2, 1), day(2026, 3, 1)},
+		{"2026-02-28", day(2026, 2, 28), day(2026, 3, 1)},
+		{">2026-01-01", day(2026, 1, 2), nil},
+		{">=2026-01", day(2026, 1, 1), nil},
+		{"<2026-01-01", nil, day(2026, 1, 1)},
+		{"<=2026-01", nil, day(2026, 2, 1)},
+		{"=2026-03-01", day(2026, 3, 1), day(2026, 3, 2)},
+		{"2026-01-01..2026-01-31", day(2026, 1, 1), day(2026, 2, 1)},
+		{"2026-06..", day(2026, 6, 1), nil},
+		{"..2025", nil, day(2026, 1, 1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			start, end, err := parseDateRange(tt.value)
+			if err != nil {
+				t.Fatalf("parseDateRange(%q) error: %v", tt.value, err)
+			}
+			if !sameTime(start, tt.start) || !sameTime(end, tt.end) {
+				t.Errorf("parseDateRange(%q) = [%v, %v), want [%v, %v)", tt.value, start, end, tt.start, tt.end)
+			}
+		})
+	}
+}
+
+func TestParseDateTerm(t *testing.T) {
+	query, err := Parse("created:2026-01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	term := query.Clauses[0][0]
+	if term.Field != FieldCreated || term.Start == nil || term.End == nil {
+		t.Fatalf("term = %+v, want created range", term)
+	}
+}
+
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.Equal(*b)
+}
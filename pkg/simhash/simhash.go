@@ -0,0 +1,62 @@
+// Package simhash 计算文本特征的 64 位 SimHash 指纹，内容相近的文本指纹的汉明距离也小，用于查找近似重复的笔记
+package simhash
+
+import (
+	"hash/fnv"
+	"math/bits"
+)
+
+// Bits 指纹位数
+const Bits = 64
+
+// Fingerprint 按带权特征（如分词及词频）计算 SimHash：每个特征哈希的每一位按权重投票，多数为 1 的位置 1
+func Fingerprint(features map[string]int) uint64 {
+	var votes [Bits]int
+	for feature, weight := range features {
+		h := fnv.New64a()
+		h.Write([]byte(feature))
+		sum := h.Sum64()
+		for i := 0; i < Bits; i++ {
+			if sum&(1<<uint(i)) != 0 {
+				votes[i] += weight
+			} else {
+				votes[i] -= weight
+			}
+		}
+	}
+
+	var fingerprint uint64
+	for i, vote := range votes {
+		if vote > 0 {
+			fingerprint |= 1 << uint(i)
+		}
+	}
+	return fingerprint
+}
+
+// Distance 两个指纹的汉明距离（不同的位数）
+func Distance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// Similarity 两个指纹的相似度（0~1，1 表示指纹相同）
+func Similarity(a, b uint64) float64 {
+	return 1 - float64(Distance(a, b))/Bits
+}
+
+// Bands 把指纹切成 n 段，返回每段的值。汉明距离不超过 n-1 的两个指纹至少有一段完全相同，
+// 可按段分桶快速找出候选，避免两两比较
+func Bands(fingerprint uint64, n int) []uint64 {
+	bands := make([]uint64, n)
+	start := 0
+	for i := 0; i < n; i++ {
+		width := Bits / n
+		if i < Bits%n {
+			width++
+		}
+		mask := uint64(1)<<uint(width) - 1
+		bands[i] = fingerprint >> uint(start) & mask
+		start += width
+	}
+	return bands
+}
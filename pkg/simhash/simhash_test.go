@@ -0,0 +1,75 @@
+package simhash
+
+import (
+	"math/bits"
+	"testing"
+)
+
+func TestFingerprint(t *testing.T) {
+	a := Fingerprint(map[string]int{"会议": 3, "纪要": 2, "项目": 1, "进度": 1})
+	if a != Fingerprint(map[string]int{"进度": 1, "项目": 1, "纪要": 2, "会议": 3}) {
+		t.Error("Fingerprint depends on map order")
+	}
+	if Fingerprint(nil) != 0 {
+		t.Errorf("Fingerprint(nil) = %x, want 0", Fingerprint(nil))
+	}
+	// 单个特征的指纹就是它的哈希
+	if got := Fingerprint(map[string]int{"x": 1}); got != Fingerprint(map[string]int{"x": 5}) {
+		t.Errorf("single feature fingerprint changed with weight: %x", got)
+	}
+}
+
+func TestDistanceSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b       uint64
+		distance   int
+		similarity float64
+	}{
+		{0, 0, 0, 1},
+		{0b1011, 0b0001, 2, 1 - 2.0/64},
+		{0, ^uint64(0), 64, 0},
+	}
+	for _, tt := range tests {
+		if got := Distance(tt.a, tt.b); got != tt.distance {
+			t.Errorf("Distance(%x, %x) = %d, want %d", tt.a, tt.b, got, tt.distance)
+		}
+		if got := Similarity(tt.a, tt.b); got != tt.similarity {
+			t.Errorf("Similarity(%x, %x) = %v, want %v", tt.a, tt.b, got, tt.similarity)
+		}
+	}
+}
+
+func TestBands(t *testing.T) {
+	// 7 段：前 1 段 10 位，其余 9 位，拼回去等于原指纹
+	fingerprint := uint64(0xF0E1D2C3B4A59687)
+	bands := Bands(fingerprint, 7)
+	if len(bands) != 7 {
+		t.Fatalf("len(Bands) = %d, want 7", len(bands))
+	}
+	var joined uint64
+	start := 0
+	for i, band := range bands {
+		width := 9
+		if i == 0 {
+			width = 10
+		}
+		if bits.Len64(band) > width {
+			t.Errorf("band %d = %x wider than %d bits", i, band, width)
+		}
+		joined |= band << uint(start)
+		start += width
+	}
+	if joined != fingerprint {
+		t.Errorf("joined bands = %x, want %x", joined, fingerprint)
+	}
+
+	// 距离不超过 n-1 时至少有一段相同
+	other := fingerprint ^ (1 | 1<<20 | 1<<40 | 1<<63 | 1<<30 | 1<<50)
+	same := false
+	for i, band := range Bands(other, 7) {
+		same = same || band == bands[i]
+	}
+	if !same {
+		t.Error("fingerprints within distance 6 share no band")
+	}
+}
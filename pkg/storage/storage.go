@@ -0,0 +1,78 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// LocalStorage 本地磁盘文件存储（按内容 SHA-256 寻址，相同文件只存一份）
+type LocalStorage struct {
+	dir string
+}
+
+// NewLocalStorage 创建 LocalStorage 实例（目录不存在时自动创建）
+func NewLocalStorage(dir string) (*LocalStorage, error) {
+	if dir == "" {
+		dir = "./data/attachments"
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &LocalStorage{dir: dir}, nil
+}
+
+// Hash 计算内容的 SHA-256（即存储 key）
+func Hash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// Save 保存文件内容，返回存储 key
+func (s *LocalStorage) Save(data []byte) (string, error) {
+	key := Hash(data)
+	path := s.path(key)
+	if _, err := os.Stat(path); err == nil {
+		return key, nil // 已存在相同内容
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", err
+	}
+	// 先写临时文件再重命名，避免并发读到半个文件
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return "", err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return "", err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return "", err
+	}
+	return key, nil
+}
+
+// Open 打开存储的文件
+func (s *LocalStorage) Open(key string) (*os.File, error) {
+	if len(key) != sha256.Size*2 {
+		return nil, errors.New("无效的文件 key")
+	}
+	if _, err := hex.DecodeString(key); err != nil {
+		return nil, errors.New("无效的文件 key")
+	}
+	return os.Open(s.path(key))
+}
+
+// path 文件存储路径：dir/前两位/完整 key
+func (s *LocalStorage) path(key string) string {
+	return filepath.Join(s.dir, key[:2], key)
+}
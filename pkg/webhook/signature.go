@@ -0,0 +1,32 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// 推送请求头
+const (
+	HeaderEvent     = "X-MyNoteBook-Event"     // 事件类型
+	HeaderDelivery  = "X-MyNoteBook-Delivery"  // 投递ID（重试时不变）
+	HeaderSignature = "X-MyNoteBook-Signature" // 签名：sha256=<hex>
+)
+
+const signaturePrefix = "sha256="
+
+// Sign 使用 HMAC-SHA256 对请求体签名，返回请求头中的签名值
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify 校验签名（供接收方使用，常量时间比较）
+func Verify(secret string, body []byte, signature string) bool {
+	if !strings.HasPrefix(signature, signaturePrefix) {
+		return false
+	}
+	return hmac.Equal([]byte(Sign(secret, body)), []byte(signature))
+}
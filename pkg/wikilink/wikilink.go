@@ -0,0 +1,242 @@
+// Package wikilink 解析笔记内容中指向其他笔记的链接：[[笔记标题]]、[[笔记标题|显示文字]]、[[#笔记ID]] 和 note://<笔记ID>
+// 代码块（``` 或 ~~~ 围起的块）和行内代码（`...`）中的文字不视为链接
+package wikilink
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	wikiRegexp = regexp.MustCompile(`\[\[([^\[\]|\n]+)(\|[^\[\]\n]*)?\]\]`)
+	idRegexp   = regexp.MustCompile(`^#(\d+)$`)
+	noteRegexp = regexp.MustCompile(`note://(\d+)`)
+)
+
+// Link 内容中的一个笔记链接（按 ID 链接时 NoteID 不为 0，否则按 Title 匹配）
+type Link struct {
+	NoteID uint
+	Title  string
+}
+
+// Parse 提取内容中的笔记链接（去重，保持出现顺序）
+func Parse(content string) []Link {
+	var (
+		links []Link
+		seen  = make(map[Link]bool)
+	)
+	add := func(link Link) {
+		if !seen[link] {
+			seen[link] = true
+			links = append(links, link)
+		}
+	}
+
+	code := codeRanges(content)
+	for _, loc := range wikiRegexp.FindAllStringSubmatchIndex(content, -1) {
+		if inCode(code, loc[0]) {
+			continue
+		}
+		target := strings.TrimSpace(content[loc[2]:loc[3]])
+		if target == "" {
+			continue
+		}
+		if id := idRegexp.FindStringSubmatch(target); id != nil {
+			if noteID, err := strconv.ParseUint(id[1], 10, 32); err == nil && noteID > 0 {
+				add(Link{NoteID: uint(noteID)})
+			}
+			continue
+		}
+		add(Link{Title: target})
+	}
+	for _, loc := range noteRegexp.FindAllStringSubmatchIndex(content, -1) {
+		if inCode(code, loc[0]) {
+			continue
+		}
+		if noteID, err := strconv.ParseUint(content[loc[2]:loc[3]], 10, 32); err == nil && noteID > 0 {
+			add(Link{NoteID: uint(noteID)})
+		}
+	}
+	return links
+}
+
+// Rename 把内容中指向 oldTitle 的 [[标题]] 链接改为 newTitle（保留显示文字，代码中的文字不改）
+func Rename(content, oldTitle, newTitle string) string {
+	code := codeRanges(content)
+	var sb strings.Builder
+	last := 0
+	for _, loc := range wikiRegexp.FindAllStringSubmatchIndex(content, -1) {
+		if inCode(code, loc[0]) || strings.TrimSpace(content[loc[2]:loc[3]]) != oldTitle {
+			continue
+		}
+		display := ""
+		if loc[4] >= 0 {
+			display = content[loc[4]:loc[5]]
+		}
+		sb.WriteString(content[last:loc[0]])
+		sb.WriteString("[[" + newTitle + display + "]]")
+		last = loc[1]
+	}
+	if last == 0 {
+		return content
+	}
+	sb.WriteString(content[last:])
+	return sb.String()
+}
+
+// Retarget 把内容中按 ID 指向 targets 键的链接（[[#ID]] 和 note://ID）改为指向对应的值（保留显示文字，代码中的文字不改）
+func Retarget(content string, targets map[uint]uint) string {
+	type edit struct {
+		start, end int
+		text       string
+	}
+	code := codeRanges(content)
+	lookup := func(s string) (uint, bool) {
+		id, err := strconv.ParseUint(s, 10, 32)
+		if err != nil {
+			return 0, false
+		}
+		to, ok := targets[uint(id)]
+		return to, ok
+	}
+
+	var edits []edit
+	for _, loc := range wikiRegexp.FindAllStringSubmatchIndex(content, -1) {
+		id := idRegexp.FindStringSubmatch(strings.TrimSpace(content[loc[2]:loc[3]]))
+		if id == nil || inCode(code, loc[0]) {
+			continue
+		}
+		if to, ok := lookup(id[1]); ok {
+			edits = append(edits, edit{loc[2], loc[3], "#" + strconv.FormatUint(uint64(to), 10)})
+		}
+	}
+	for _, loc := range noteRegexp.FindAllStringSubmatchIndex(content, -1) {
+		if inCode(code, loc[0]) {
+			continue
+		}
+		if to, ok := lookup(content[loc[2]:loc[3]]); ok {
+			edits = append(edits, edit{loc[2], loc[3], strconv.FormatUint(uint64(to), 10)})
+		}
+	}
+	if len(edits) == 0 {
+		return content
+	}
+
+	sort.Slice(edits, func(i, j int) bool { return edits[i].start < edits[j].start })
+	var sb strings.Builder
+	last := 0
+	for _, e := range edits {
+		if e.start < last {
+			continue // 两种写法重叠时只改一次
+		}
+		sb.WriteString(content[last:e.start])
+		sb.WriteString(e.text)
+		last = e.end
+	}
+	sb.WriteString(content[last:])
+	return sb.String()
+}
+
+// codeRanges 找出内容中代码块和行内代码的位置（[起点, 终点) 按顺序排列）
+func codeRanges(content string) [][2]int {
+	var (
+		ranges    [][2]int
+		fence     string // 当前代码块的围栏（为空表示不在代码块中）
+		start     int    // 当前代码块或普通文本段的起点
+		textStart int
+	)
+	for pos := 0; pos < len(content); {
+		end := strings.IndexByte(content[pos:], '\n')
+		if end < 0 {
+			end = len(content)
+		} else {
+			end += pos + 1
+		}
+		line := strings.TrimRight(content[pos:end], "\r\n")
+		marker := fenceMarker(line)
+		if fence == "" && marker != "" {
+			ranges = append(ranges, inlineCodeRanges(content, textStart, pos)...)
+			fence, start = marker, pos
+		} else if fence != "" && strings.HasPrefix(marker, fence) && strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(line), fence[:1])) == "" {
+			ranges = append(ranges, [2]int{start, end})
+			fence, textStart = "", end
+		}
+		pos = end
+	}
+	if fence != "" {
+		return append(ranges, [2]int{start, len(content)}) // 未闭合的代码块延续到结尾
+	}
+	return append(ranges, inlineCodeRanges(content, textStart, len(content))...)
+}
+
+// fenceMarker 返回代码块围栏（至少三个 ` 或 ~，最多缩进三个空格），不是围栏时返回空
+func fenceMarker(line string) string {
+	trimmed := strings.TrimLeft(line, " ")
+	if len(line)-len(trimmed) > 3 || len(trimmed) < 3 {
+		return ""
+	}
+	c := trimmed[0]
+	if c != '`' && c != '~' {
+		return ""
+	}
+	n := 0
+	for n < len(trimmed) && trimmed[n] == c {
+		n++
+	}
+	if n < 3 || (c == '`' && strings.IndexByte(trimmed[n:], '`') >= 0) {
+		return ""
+	}
+	return trimmed[:n]
+}
+
+// inlineCodeRanges 找出 content[from:to] 中的行内代码：以 N 个反引号开始，到下一处恰好 N 个反引号结束
+func inlineCodeRanges(content string, from, to int) [][2]int {
+	var ranges [][2]int
+	runLen := func(i int) int {
+		n := 0
+		for i+n < to && content[i+n] == '`' {
+			n++
+		}
+		return n
+	}
+	for i := from; i < to; {
+		if content[i] != '`' {
+			i++
+			continue
+		}
+		n := runLen(i)
+		closed := false
+		for j := i + n; j < to; {
+			if content[j] != '`' {
+				j++
+				continue
+			}
+			m := runLen(j)
+			if m == n {
+				ranges = append(ranges, [2]int{i, j + m})
+				i, closed = j+m, true
+				break
+			}
+			j += m
+		}
+		if !closed {
+			i += n // 没有配对的反引号按普通文字处理
+		}
+	}
+	return ranges
+}
+
+// inCode 判断 pos 是否位于代码中
+func inCode(ranges [][2]int, pos int) bool {
+	for _, r := range ranges {
+		if pos < r[0] {
+			return false
+		}
+		if pos < r[1] {
+			return true
+		}
+	}
+	return false
+}
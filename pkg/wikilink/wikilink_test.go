@@ -0,0 +1,66 @@
+package wikilink
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []Link
+	}{
+		{"标题链接", "见 [[周报]] 和 [[计划|下周计划]]", []Link{{Title: "周报"}, {Title: "计划"}}},
+		{"ID 链接", "[[#12]] note://34 [[#0]]", []Link{{NoteID: 12}, {NoteID: 34}}},
+		{"去重", "[[a]] [[ a ]] note://1 [[#1]]", []Link{{Title: "a"}, {NoteID: 1}}},
+		{"空标题忽略", "[[ ]]", nil},
+		{"行内代码", "`[[a]]` 和 ``x ` [[b]]`` [[c]]", []Link{{Title: "c"}}},
+		{"代码块", "```go\n[[a]] note://1\n```\n[[b]]", []Link{{Title: "b"}}},
+		{"波浪线代码块", "~~~\n[[a]]\n~~~\n[[b]]", []Link{{Title: "b"}}},
+		{"围栏长度不足不闭合", "````\n```\n[[a]]\n````\n[[b]]", []Link{{Title: "b"}}},
+		{"未闭合的代码块延续到结尾", "[[a]]\n```\n[[b]]", []Link{{Title: "a"}}},
+		{"未配对的反引号按普通文字", "a ` [[b]]", []Link{{Title: "b"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Parse(tt.content); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse(%q) = %+v, want %+v", tt.content, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRename(t *testing.T) {
+	tests := []struct {
+		content, want string
+	}{
+		{"[[旧]] [[旧|显示]] [[其他]]", "[[新]] [[新|显示]] [[其他]]"},
+		{"[[ 旧 ]]", "[[新]]"},
+		{"`[[旧]]` [[旧]]", "`[[旧]]` [[新]]"},
+		{"```\n[[旧]]\n```", "```\n[[旧]]\n```"},
+		{"没有链接", "没有链接"},
+	}
+	for _, tt := range tests {
+		if got := Rename(tt.content, "旧", "新"); got != tt.want {
+			t.Errorf("Rename(%q) = %q, want %q", tt.content, got, tt.want)
+		}
+	}
+}
+
+func TestRetarget(t *testing.T) {
+	targets := map[uint]uint{5: 9, 6: 9}
+	tests := []struct {
+		content, want string
+	}{
+		{"note://5 note://55 note://6", "note://9 note://55 note://9"},
+		{"[[#5|显示]] [[ #6 ]] [[#7]]", "[[#9|显示]] [[#9]] [[#7]]"},
+		{"`note://5` [[5]]", "`note://5` [[5]]"},
+		{"```\n[[#5]]\n```\nnote://5", "```\n[[#5]]\n```\nnote://9"},
+	}
+	for _, tt := range tests {
+		if got := Retarget(tt.content, targets); got != tt.want {
+			t.Errorf("Retarget(%q) = %q, want %q", tt.content, got, tt.want)
+		}
+	}
+}
@@ -1,12 +1,15 @@
 package router
 
 import (
+	"context"
 	"time"
 
 	"github.com/JokerYuan-lang/MyNoteBook/api"
 	"github.com/JokerYuan-lang/MyNoteBook/internal/config"
 	"github.com/JokerYuan-lang/MyNoteBook/internal/middlewares"
 	"github.com/JokerYuan-lang/MyNoteBook/internal/service"
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/mailer"
+	"github.com/JokerYuan-lang/MyNoteBook/pkg/storage"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	"github.com/go-redis/redis/v8"
@@ -17,11 +20,13 @@ import (
 func InitRouter(
 	db *gorm.DB,
 	rdb *redis.Client,
-	jwtConf config.JwtConfig,
-	debug bool,
+	fileStorage *storage.LocalStorage,
+	conf config.Config,
 ) *gin.Engine {
+	jwtConf := conf.Jwt
+
 	// 设置 Gin 模式（调试/生产）
-	if !debug {
+	if !conf.Debug {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
@@ -40,11 +45,76 @@ func InitRouter(
 	}))
 
 	// 2. 初始化服务和 API
+	auditService := service.NewAuditService(db)
+	auditAPI := api.NewAuditAPI(auditService)
+
 	userService := service.NewUserService(db, jwtConf)
-	userAPI := api.NewUserAPI(userService)
+	userAPI := api.NewUserAPI(userService, auditService)
+
+	notificationService := service.NewNotificationService(db, mailer.NewMailer(conf.Smtp))
+	notificationAPI := api.NewNotificationAPI(notificationService)
+
+	webhookService := service.NewWebhookService(db, rdb, conf.Webhook)
+	webhookAPI := api.NewWebhookAPI(webhookService)
+	go webhookService.Run(context.Background()) // 后台投递 Webhook
+
+	reminderService := service.NewReminderService(db, rdb, notificationService)
+	reminderAPI := api.NewReminderAPI(reminderService, auditService)
+	go reminderService.Run(context.Background()) // 后台扫描到期提醒
+
+	checklistService := service.NewChecklistService(db)
+	checklistAPI := api.NewChecklistAPI(checklistService)
+
+	attachmentService := service.NewAttachmentService(db, fileStorage)
+	attachmentAPI := api.NewAttachmentAPI(attachmentService)
+
+	exportService := service.NewExportService(db, fileStorage, conf.Render)
+	exportAPI := api.NewExportAPI(exportService)
+
+	indexService := service.NewIndexService(db, conf.Search)
+
+	linkService := service.NewLinkService(db, indexService)
+	linkAPI := api.NewLinkAPI(linkService)
+	go linkService.RebuildLinks() // 后台补全旧笔记的链接
+
+	graphService := service.NewGraphService(db)
+	graphAPI := api.NewGraphAPI(graphService)
+
+	relatedService := service.NewRelatedService(db, rdb)
+	relatedAPI := api.NewRelatedAPI(relatedService)
+	go relatedService.Run(context.Background()) // 后台重算笔记词向量
+
+	tagService := service.NewTagService(db, indexService, relatedService)
+	tagAPI := api.NewTagAPI(tagService)
+	go tagService.NormalizeTagNames() // 后台规范化升级前保存的标签名
 
-	noteService := service.NewNoteService(db)
-	noteAPI := api.NewNoteAPI(noteService)
+	noteService := service.NewNoteService(db, notificationService, webhookService, linkService, indexService, tagService, relatedService)
+	noteAPI := api.NewNoteAPI(noteService, auditService)
+	go func() {
+		noteService.RebuildContentCache() // 后台补全旧笔记的渲染缓存
+		indexService.RebuildIndex()       // 再为旧笔记建立搜索索引（依赖纯文本缓存）
+	}()
+
+	notebookService := service.NewNotebookService(db, noteService)
+	notebookAPI := api.NewNotebookAPI(notebookService)
+	go notebookService.RebuildNotebooks() // 后台把旧笔记的分类转换为笔记本
+
+	duplicateService := service.NewDuplicateService(db, noteService)
+	duplicateAPI := api.NewDuplicateAPI(duplicateService, auditService)
+
+	embeddingService := service.NewEmbeddingService(db, rdb, conf.Embedding, indexService, relatedService)
+	embeddingAPI := api.NewEmbeddingAPI(embeddingService)
+	go embeddingService.Run(context.Background()) // 后台计算笔记语义向量
+
+	searchService := service.NewSearchService(db, noteService, indexService)
+	searchAPI := api.NewSearchAPI(searchService)
+
+	importService := service.NewImportService(db, noteService, attachmentService)
+	importAPI := api.NewImportAPI(importService, auditService)
+	go importService.Run(context.Background()) // 后台标记中断的导入任务
+
+	clipService := service.NewClipService(noteService, attachmentService)
+	clipAPI := api.NewClipAPI(clipService, auditService)
 
 	// 3. 路由分组
 	apiGroup := r.Group("/api/v1")
@@ -54,17 +124,142 @@ func InitRouter(
 		{
 			publicGroup.POST("/register", middlewares.RateLimit(rdb, 5, time.Minute), userAPI.Register) // 注册（1分钟5次）
 			publicGroup.POST("/login", middlewares.RateLimit(rdb, 5, time.Minute), userAPI.Login)       // 登录（1分钟5次）
+			publicGroup.GET("/calendar.ics", reminderAPI.GetCalendar)                                   // 日历订阅（凭令牌访问）
 		}
 
 		// 需登录接口（AuthCheck 中间件）
 		authGroup := apiGroup.Group("/note")
 		authGroup.Use(middlewares.AuthCheck(jwtConf)) // 统一认证
 		{
-			authGroup.POST("/create", noteAPI.CreateNote)   // 创建笔记
-			authGroup.GET("/list", noteAPI.GetNoteList)     // 笔记列表（分页）
-			authGroup.GET("/detail", noteAPI.GetNoteByID)   // 笔记详情
-			authGroup.PUT("/update", noteAPI.UpdateNote)    // 更新笔记
-			authGroup.DELETE("/delete", noteAPI.DeleteNote) // 删除笔记
+			authGroup.POST("/create", noteAPI.CreateNote)                  // 创建笔记
+			authGroup.GET("/list", noteAPI.GetNoteList)                    // 笔记列表（分页）
+			authGroup.GET("/detail", noteAPI.GetNoteByID)                  // 笔记详情
+			authGroup.GET("/render", noteAPI.RenderNote)                   // 笔记渲染为 HTML
+			authGroup.GET("/links/broken", linkAPI.GetBrokenLinks)         // 断链报告
+			authGroup.GET("/graph", graphAPI.GetGraph)                     // 知识图谱
+			authGroup.GET("/related", relatedAPI.GetRelatedNotes)          // 相关笔记推荐
+			authGroup.GET("/duplicates", duplicateAPI.GetDuplicates)       // 重复笔记
+			authGroup.POST("/merge", duplicateAPI.MergeNotes)              // 合并笔记
+			authGroup.GET("/merge/trash", duplicateAPI.GetMergedNotes)     // 合并回收站
+			authGroup.PUT("/merge/restore", duplicateAPI.RestoreNote)      // 从合并回收站恢复
+			authGroup.PUT("/update", noteAPI.UpdateNote)                   // 更新笔记
+			authGroup.DELETE("/delete", noteAPI.DeleteNote)                // 删除笔记
+			authGroup.PUT("/pin", noteAPI.PinNote)                         // 置顶/取消置顶
+			authGroup.PUT("/favorite", noteAPI.FavoriteNote)               // 收藏/取消收藏
+			authGroup.PUT("/archive", noteAPI.ArchiveNote)                 // 归档/取消归档
+			authGroup.PUT("/schedule", reminderAPI.SetSchedule)            // 设置提醒/截止时间
+			authGroup.PUT("/snooze", reminderAPI.Snooze)                   // 稍后提醒
+			authGroup.PUT("/complete", reminderAPI.Complete)               // 标记完成
+			authGroup.GET("/calendar/url", reminderAPI.GetCalendarURL)     // 日历订阅地址
+			authGroup.POST("/checklist/add", checklistAPI.AddItem)         // 添加清单项
+			authGroup.PUT("/checklist/toggle", checklistAPI.ToggleItem)    // 切换清单项完成状态
+			authGroup.PUT("/checklist/reorder", checklistAPI.ReorderItems) // 清单排序
+			authGroup.DELETE("/checklist/delete", checklistAPI.DeleteItem) // 删除清单项
+			authGroup.GET("/export/markdown", exportAPI.ExportMarkdown)    // 导出 Markdown 压缩包
+			authGroup.GET("/export/pdf", exportAPI.ExportPDF)              // 导出笔记/分类为 PDF
+			authGroup.GET("/export/epub", exportAPI.ExportEPUB)            // 导出分类为 EPUB
+			authGroup.GET("/export/site", exportAPI.ExportSite)            // 导出分类为静态网站
+		}
+
+		// 笔记本接口（需登录）
+		notebookGroup := apiGroup.Group("/notebook")
+		notebookGroup.Use(middlewares.AuthCheck(jwtConf))
+		{
+			notebookGroup.GET("/tree", notebookAPI.GetNotebookTree)     // 笔记本树（含笔记数）
+			notebookGroup.POST("/create", notebookAPI.CreateNotebook)   // 创建笔记本
+			notebookGroup.PUT("/rename", notebookAPI.RenameNotebook)    // 重命名笔记本
+			notebookGroup.PUT("/move", notebookAPI.MoveNotebook)        // 移动笔记本
+			notebookGroup.DELETE("/delete", notebookAPI.DeleteNotebook) // 删除笔记本
+		}
+
+		// 附件接口（需登录）
+		attachmentGroup := apiGroup.Group("/attachment")
+		attachmentGroup.Use(middlewares.AuthCheck(jwtConf))
+		{
+			attachmentGroup.GET("/list", attachmentAPI.GetAttachmentList)      // 笔记附件列表
+			attachmentGroup.GET("/download", attachmentAPI.DownloadAttachment) // 下载附件
+		}
+
+		// 导入接口（需登录）
+		importGroup := apiGroup.Group("/import")
+		importGroup.Use(middlewares.AuthCheck(jwtConf))
+		{
+			importGroup.POST("/markdown", importAPI.ImportMarkdown) // 导入 Markdown/ZIP（异步）
+			importGroup.POST("/enex", importAPI.ImportENEX)         // 导入 Evernote ENEX（异步）
+			importGroup.POST("/joplin", importAPI.ImportJoplin)     // 导入 Joplin JEX/RAW（异步）
+			importGroup.POST("/notion", importAPI.ImportNotion)     // 导入 Notion 导出（异步）
+			importGroup.GET("/job", importAPI.GetJob)               // 导入进度
+			importGroup.GET("/list", importAPI.GetJobList)          // 最近导入任务
+		}
+
+		// 搜索接口（需登录）
+		searchGroup := apiGroup.Group("/search")
+		searchGroup.Use(middlewares.AuthCheck(jwtConf))
+		{
+			searchGroup.GET("/notes", searchAPI.Search)                      // 按搜索语句查询笔记
+			searchGroup.GET("/semantic", embeddingAPI.SemanticSearch)        // 语义搜索
+			searchGroup.POST("/saved/create", searchAPI.CreateSavedSearch)   // 保存搜索
+			searchGroup.GET("/saved/list", searchAPI.GetSavedSearchList)     // 保存的搜索列表
+			searchGroup.PUT("/saved/update", searchAPI.UpdateSavedSearch)    // 修改保存的搜索
+			searchGroup.DELETE("/saved/delete", searchAPI.DeleteSavedSearch) // 删除保存的搜索
+			searchGroup.GET("/saved/run", searchAPI.RunSavedSearch)          // 执行保存的搜索
+		}
+
+		// 标签树、标签推荐和自动打标签规则接口（需登录）
+		tagGroup := apiGroup.Group("/tag")
+		tagGroup.Use(middlewares.AuthCheck(jwtConf))
+		{
+			tagGroup.GET("/tree", tagAPI.GetTagTree)              // 标签树（含笔记数）
+			tagGroup.PUT("/rename", tagAPI.RenameTag)             // 重命名标签（含下级标签）
+			tagGroup.POST("/suggest", tagAPI.SuggestTags)         // 根据草稿内容推荐标签
+			tagGroup.POST("/rule/create", tagAPI.CreateTagRule)   // 创建自动打标签规则
+			tagGroup.GET("/rule/list", tagAPI.GetTagRuleList)     // 自动打标签规则列表
+			tagGroup.PUT("/rule/update", tagAPI.UpdateTagRule)    // 修改自动打标签规则
+			tagGroup.DELETE("/rule/delete", tagAPI.DeleteTagRule) // 删除自动打标签规则
+		}
+
+		// 网页剪藏接口（需登录）
+		clipGroup := apiGroup.Group("/clip")
+		clipGroup.Use(middlewares.AuthCheck(jwtConf))
+		{
+			clipGroup.POST("/create", clipAPI.ClipPage) // 剪藏网页为笔记
+		}
+
+		// 通知接口（需登录）
+		notificationGroup := apiGroup.Group("/notification")
+		notificationGroup.Use(middlewares.AuthCheck(jwtConf))
+		{
+			notificationGroup.GET("/list", notificationAPI.GetNotificationList)    // 通知列表（分页）
+			notificationGroup.GET("/unread_count", notificationAPI.GetUnreadCount) // 未读数量
+			notificationGroup.PUT("/read", notificationAPI.MarkRead)               // 标记已读
+			notificationGroup.PUT("/read_all", notificationAPI.MarkAllRead)        // 全部已读
+		}
+
+		// Webhook 接口（需登录）
+		webhookGroup := apiGroup.Group("/webhook")
+		webhookGroup.Use(middlewares.AuthCheck(jwtConf))
+		{
+			webhookGroup.POST("/create", webhookAPI.CreateWebhook)      // 创建订阅
+			webhookGroup.GET("/list", webhookAPI.GetWebhookList)        // 订阅列表
+			webhookGroup.PUT("/enabled", webhookAPI.SetWebhookEnabled)  // 启用/停用
+			webhookGroup.DELETE("/delete", webhookAPI.DeleteWebhook)    // 删除订阅
+			webhookGroup.POST("/ping", webhookAPI.PingWebhook)          // 测试推送
+			webhookGroup.GET("/deliveries", webhookAPI.GetDeliveryList) // 投递记录
+		}
+
+		// 审计日志接口（需登录，只能查看自己的操作记录）
+		auditGroup := apiGroup.Group("/audit")
+		auditGroup.Use(middlewares.AuthCheck(jwtConf))
+		{
+			auditGroup.GET("/list", auditAPI.GetMyAuditList) // 我的操作记录
+		}
+
+		// 管理员接口（需登录 + 管理员角色）
+		adminGroup := apiGroup.Group("/admin")
+		adminGroup.Use(middlewares.AuthCheck(jwtConf), middlewares.AdminCheck(db))
+		{
+			adminGroup.GET("/audit/list", auditAPI.GetAllAuditList)   // 全局审计日志
+			adminGroup.GET("/audit/export", auditAPI.ExportAuditLogs) // 导出审计日志（CSV）
 		}
 	}
 